@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/isdmx/watchdog/internal/audit"
+)
+
+// auditCommand runs the audit subcommands
+func auditCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "verify" {
+		fmt.Fprintln(stderr, "Usage: watchdog audit verify FILE...")
+		return 2
+	}
+	return auditVerify(args[1:], stdout, stderr)
+}
+
+// auditVerify checks the hash chain of audit logs given oldest first, so rotated
+// backups can be verified together with the current file
+func auditVerify(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("audit verify", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: watchdog audit verify FILE...")
+		fmt.Fprintln(stderr, "\nFiles are verified in order, list rotated backups oldest first (audit.jsonl.2 audit.jsonl.1 audit.jsonl).")
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	verifier := &audit.Verifier{}
+	for _, path := range flags.Args() {
+		if err := verifyFile(verifier, path); err != nil {
+			fmt.Fprintf(stderr, "FAIL %s: %v\n", path, err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(stdout, "OK %d records verified, last hash %s\n", verifier.Records(), verifier.LastHash())
+	return 0
+}
+
+func verifyFile(verifier *audit.Verifier, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return verifier.Verify(file)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/isdmx/watchdog/internal/audit"
+)
+
+func TestAuditVerify(t *testing.T) {
+	dir := t.TempDir()
+	var lines []string
+	prevHash := ""
+	for seq := uint64(1); seq <= 3; seq++ {
+		record := audit.Record{Seq: seq, CycleID: "cycle-1", Action: "terminated", Result: "success", PrevHash: prevHash}
+		hash, err := audit.ComputeHash(record)
+		require.NoError(t, err)
+		record.Hash = hash
+		prevHash = hash
+
+		line, err := json.Marshal(record)
+		require.NoError(t, err)
+		lines = append(lines, string(line)+"\n")
+	}
+
+	backup := filepath.Join(dir, "audit.jsonl.1")
+	current := filepath.Join(dir, "audit.jsonl")
+	require.NoError(t, os.WriteFile(backup, []byte(lines[0]+lines[1]), 0o600))
+	require.NoError(t, os.WriteFile(current, []byte(lines[2]), 0o600))
+
+	run := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := runCommand(args, &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	t.Run("valid chain", func(t *testing.T) {
+		code, stdout, _ := run("audit", "verify", backup, current)
+		require.Equal(t, 0, code)
+		require.Contains(t, stdout, "OK 3 records verified")
+	})
+
+	t.Run("files out of order", func(t *testing.T) {
+		code, _, stderr := run("audit", "verify", current, backup)
+		require.Equal(t, 1, code)
+		require.True(t, strings.HasPrefix(stderr, "FAIL "+backup))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		code, _, _ := run("audit", "verify", filepath.Join(dir, "missing.jsonl"))
+		require.Equal(t, 1, code)
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		code, _, _ := run("audit", "verify")
+		require.Equal(t, 2, code)
+		code, _, _ = run("audit")
+		require.Equal(t, 2, code)
+		code, _, _ = run("bogus")
+		require.Equal(t, 2, code)
+	})
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/isdmx/watchdog/internal/client"
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+const configUsage = `Usage:
+  watchdog config validate [FLAGS] [FILE]    check the configuration, for CI
+  watchdog config print [FLAGS] [FILE]       print the effective configuration with defaults
+
+Without FILE, $WATCHDOG_CONFIG is used or config.yaml is looked up in the working
+directory, ./config and /etc/watchdog. The WATCHDOG_ environment variables and the
+setting flags of watchdog, such as --watchdog.dryRun, override the file.
+
+Secret references are only checked for their syntax, --resolve reads the files,
+environment variables and Kubernetes Secrets they reference.
+`
+
+// configCommand runs the config subcommands
+func configCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, configUsage)
+		return 2
+	}
+	switch args[0] {
+	case "validate":
+		return configValidate(args[1:], stdout, stderr)
+	case "print":
+		return configPrint(args[1:], stdout, stderr)
+	default:
+		fmt.Fprint(stderr, configUsage)
+		return 2
+	}
+}
+
+// configValidate loads the configuration and lists every problem found in it
+func configValidate(args []string, stdout, stderr io.Writer) int {
+	cfg, code := loadConfig("config validate", args, stderr)
+	if cfg == nil {
+		return code
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(stderr, "FAIL %s\n", cfg.Path())
+		printProblems(err, stderr)
+		return 1
+	}
+	fmt.Fprintf(stdout, "OK %s\n", cfg.Path())
+	return 0
+}
+
+// configPrint prints the configuration the watchdog would run with, failing
+// when it is invalid
+func configPrint(args []string, stdout, stderr io.Writer) int {
+	cfg, code := loadConfig("config print", args, stderr)
+	if cfg == nil {
+		return code
+	}
+	data, err := cfg.YAML()
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to print config: %v\n", err)
+		return 1
+	}
+	_, _ = stdout.Write(data)
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(stderr, "%s is invalid:\n", cfg.Path())
+		printProblems(err, stderr)
+		return 1
+	}
+	return 0
+}
+
+// loadConfig parses the arguments and loads the configuration, returning the
+// exit code when it cannot
+func loadConfig(name string, args []string, stderr io.Writer) (*config.Config, int) {
+	src := config.Source{Secrets: client.NewSecretReader()}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	src.RegisterFlags(flags)
+	resolve := flags.Bool("resolve", false, "resolve the secret references instead of only checking their syntax")
+	flags.Usage = func() {
+		fmt.Fprint(stderr, configUsage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, 2
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return nil, 2
+	}
+
+	if flags.NArg() == 1 {
+		src.Path = flags.Arg(0)
+	}
+	src.Unresolved = !*resolve
+	cfg, err := src.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to load config: %v\n", err)
+		return nil, 1
+	}
+	return cfg, 0
+}
+
+// printProblems lists the problems of an invalid configuration one per line
+func printProblems(err error, stderr io.Writer) {
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		fmt.Fprintf(stderr, "  %v\n", err)
+		return
+	}
+	for _, problem := range validationErr.Problems {
+		fmt.Fprintf(stderr, "  %s\n", problem)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigCommand(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte("watchdog:\n  namespaces: [default]\n  scheduleInterval: 5m\n"), 0o600))
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("watchdog:\n  scheduleInterval: 0s\n  dryrun: true\n  dry_run: true\n"), 0o600))
+
+	run := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := runCommand(args, &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	t.Run("validate", func(t *testing.T) {
+		code, stdout, _ := run("config", "validate", valid)
+		require.Equal(t, 0, code)
+		require.Equal(t, "OK "+valid+"\n", stdout)
+	})
+
+	t.Run("validate lists every problem", func(t *testing.T) {
+		code, _, stderr := run("config", "validate", invalid)
+		require.Equal(t, 1, code)
+		require.Equal(t, "FAIL "+invalid+"\n"+
+			"  watchdog.dry_run: unknown setting\n"+
+			"  watchdog.namespaces: must list at least one namespace\n"+
+			"  watchdog.scheduleInterval: must be positive, got 0s\n", stderr)
+	})
+
+	t.Run("print", func(t *testing.T) {
+		code, stdout, stderr := run("config", "print", valid)
+		require.Equal(t, 0, code)
+		require.Empty(t, stderr)
+		require.Contains(t, stdout, "  scheduleInterval: 5m0s # valid.yaml\n")
+		require.Contains(t, stdout, "  maxPodLifetime: 1h0m0s\n")
+	})
+
+	t.Run("print applies the overrides", func(t *testing.T) {
+		t.Setenv("WATCHDOG_WATCHDOG_MAXPODLIFETIME", "3h")
+		code, stdout, _ := run("config", "print", "--watchdog.dryRun", "--config", valid)
+		require.Equal(t, 0, code)
+		require.Contains(t, stdout, "  dryRun: true # --watchdog.dryRun\n")
+		require.Contains(t, stdout, "  maxPodLifetime: 3h0m0s # WATCHDOG_WATCHDOG_MAXPODLIFETIME\n")
+	})
+
+	t.Run("print fails on an invalid config", func(t *testing.T) {
+		code, stdout, stderr := run("config", "print", invalid)
+		require.Equal(t, 1, code)
+		require.Contains(t, stdout, "  dryRun: true # invalid.yaml\n")
+		require.Contains(t, stderr, invalid+" is invalid:\n")
+	})
+
+	t.Run("references are checked without resolving them", func(t *testing.T) {
+		t.Setenv("KUBECONFIG", filepath.Join(dir, "missing-kubeconfig"))
+		t.Setenv("HOME", filepath.Join(dir, "nonexistent"))
+		referenced := filepath.Join(dir, "referenced.yaml")
+		require.NoError(t, os.WriteFile(referenced, []byte("watchdog:\n  namespaces: [default]\n"+
+			"notifications:\n  webhooks:\n    - name: chat\n      url: https://chat.example.com/hook\n"+
+			"      secret: k8s-secret:default/watchdog-webhooks#hmac\n"), 0o600))
+
+		code, stdout, stderr := run("config", "validate", referenced)
+		require.Equal(t, 0, code, stderr)
+		require.Equal(t, "OK "+referenced+"\n", stdout)
+
+		code, stdout, _ = run("config", "print", referenced)
+		require.Equal(t, 0, code)
+		require.Contains(t, stdout, "k8s-secret:default/watchdog-webhooks#hmac")
+
+		code, _, stderr = run("config", "validate", "--resolve", referenced)
+		require.Equal(t, 1, code)
+		require.Contains(t, stderr, "notifications.webhooks[0].secret: failed to resolve")
+
+		malformed := filepath.Join(dir, "malformed.yaml")
+		require.NoError(t, os.WriteFile(malformed, []byte("watchdog:\n  namespaces: [default]\n"+
+			"notifications:\n  webhooks:\n    - name: chat\n      url: https://chat.example.com/hook\n"+
+			"      secret: k8s-secret:default/watchdog-webhooks\n"), 0o600))
+		code, _, stderr = run("config", "validate", malformed)
+		require.Equal(t, 1, code)
+		require.Contains(t, stderr, "notifications.webhooks[0].secret: invalid reference")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		code, _, stderr := run("config", "validate", filepath.Join(dir, "missing.yaml"))
+		require.Equal(t, 1, code)
+		require.Contains(t, stderr, "failed to load config")
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		code, _, _ := run("config")
+		require.Equal(t, 2, code)
+		code, _, _ = run("config", "lint")
+		require.Equal(t, 2, code)
+		code, _, _ = run("config", "validate", valid, invalid)
+		require.Equal(t, 2, code)
+	})
+}
@@ -3,12 +3,30 @@
 // The watchdog is a Kubernetes pod lifetime management tool that monitors
 // pods in specified namespaces and terminates those that exceed a configured
 // maximum lifetime. It provides a simple command-line interface to start
-// the monitoring service.
+// the monitoring service, plus a few maintenance commands:
+//
+//	watchdog [run] [--config FILE] [--SETTING VALUE]...
+//	                                  run the watchdog, the setting flags such
+//	                                  as --watchdog.dryRun override the file
+//	watchdog once [--config FILE] [--SETTING VALUE]...
+//	                                  run a single cycle and exit, for a CronJob
+//	watchdog version                  print the build information
+//	watchdog plan --pods PATH [--config FILE] [--diff FILE]
+//	                                  print the pods the policy would expire, offline
+//	watchdog audit verify FILE...     verify the hash chain of audit logs
+//	watchdog config validate [FILE]   check the configuration, for CI
+//	watchdog config print [FILE]      print the effective configuration with defaults
+//	watchdog restore [--dry-run] [--config FILE] [--SETTING VALUE]... NAMESPACE/POD
+//	                                  recreate a pod from its latest snapshot
 //
 // The application uses the Uber fx framework for dependency injection and
 // follows a modular architecture with separate packages for configuration,
 // logging, Kubernetes client interaction, monitoring, and HTTP server functionality.
 //
+// The once command exits with 0 after a clean cycle, 1 when the cycle failed,
+// 3 on a partial failure and 4 when a pause held terminations back, and may
+// push its metrics to a Prometheus Pushgateway first.
+//
 // Features:
 //   - Configurable maximum pod lifetime enforcement
 //   - Namespace and label selector filtering
@@ -1,9 +1,95 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
 	"github.com/isdmx/watchdog/internal/app"
+	"github.com/isdmx/watchdog/internal/client"
+	"github.com/isdmx/watchdog/internal/config"
 )
 
 func main() {
-	app.NewApplication().Run()
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		os.Exit(runCommand(os.Args[1:], os.Stdout, os.Stderr))
+	}
+	os.Exit(runWatchdog(os.Args[1:], os.Stderr))
+}
+
+const usage = `Usage:
+  watchdog [run] [--config FILE] [--SETTING VALUE]...
+                                      run the watchdog
+  watchdog once [--config FILE] [--SETTING VALUE]...
+                                      run a single cycle and exit, for a CronJob
+  watchdog version                    print the build information
+  watchdog plan --pods PATH [--config FILE] [--diff FILE]
+                                      print the pods the policy would expire, offline
+  watchdog audit verify FILE...       verify the hash chain of audit logs
+  watchdog config validate [FILE]     check the configuration, for CI
+  watchdog config print [FILE]        print the effective configuration with defaults
+  watchdog restore [--dry-run] [--config FILE] [--SETTING VALUE]... NAMESPACE/POD
+                                      recreate a pod from its latest snapshot
+`
+
+// runWatchdog runs the long-running watchdog until it is stopped
+func runWatchdog(args []string, stderr io.Writer) int {
+	src, code := parseSource(args, stderr)
+	if src == nil {
+		return code
+	}
+	app.NewApplication(*src).Run()
+	return 0
+}
+
+// parseSource parses --config and the setting flags, such as
+// --watchdog.dryRun or --http.addr=:8081. Without a source, the watchdog exits
+// with the code returned instead of running.
+func parseSource(args []string, stderr io.Writer) (*config.Source, int) {
+	src := &config.Source{Secrets: client.NewSecretReader()}
+	flags := flag.NewFlagSet("watchdog", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	src.RegisterFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fmt.Fprintln(stderr, "\nFlags, each setting can also be set with its WATCHDOG_ environment variable:")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, 0
+		}
+		return nil, 2
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return nil, 2
+	}
+	return src, 0
+}
+
+// runCommand runs a subcommand and returns the process exit code
+func runCommand(args []string, stdout, stderr io.Writer) int {
+	switch args[0] {
+	case "run":
+		return runWatchdog(args[1:], stderr)
+	case "once":
+		return onceCommand(args[1:], stdout, stderr)
+	case "plan":
+		return planCommand(args[1:], stdout, stderr)
+	case "version":
+		return versionCommand(args[1:], stdout, stderr)
+	case "audit":
+		return auditCommand(args[1:], stdout, stderr)
+	case "config":
+		return configCommand(args[1:], stdout, stderr)
+	case "restore":
+		return restoreCommand(args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
 }
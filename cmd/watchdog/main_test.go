@@ -6,13 +6,14 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/isdmx/watchdog/internal/app"
+	"github.com/isdmx/watchdog/internal/config"
 )
 
 func TestMainFunction(t *testing.T) {
 	t.Run("main function runs without error", func(t *testing.T) {
 		// This is a minimal test for the main function
 		// We create an app and verify it's created properly
-		appInstance := app.NewApplication()
+		appInstance := app.NewApplication(config.Source{})
 		require.NotNil(t, appInstance)
 
 		// Since main() calls app.Run(), we're effectively testing
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"go.uber.org/zap"
+
+	"github.com/isdmx/watchdog/internal/app"
+	"github.com/isdmx/watchdog/internal/client"
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+// Exit codes of the once command, a CronJob can tell them apart in its alerts
+const (
+	// exitFailed means the cycle could not run or was interrupted
+	exitFailed = 1
+	// exitPartial means some namespaces could not be listed or some pods could not be terminated
+	exitPartial = 3
+	// exitPaused means terminations were held back by a pause
+	exitPaused = 4
+)
+
+// onceCommand runs a single monitoring cycle and exits, for a CronJob
+func onceCommand(args []string, _, stderr io.Writer) int {
+	src := &config.Source{Secrets: client.NewSecretReader()}
+	flags := flag.NewFlagSet("once", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	src.RegisterFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: watchdog once [--config FILE] [--SETTING VALUE]...")
+		fmt.Fprintln(stderr, "\nRun a single monitoring cycle, then exit with 0 on success, 1 on failure,")
+		fmt.Fprintln(stderr, "3 on a partial failure and 4 when terminations are paused.")
+		fmt.Fprintln(stderr, "The metrics are pushed to metrics.pushgateway.url when set.")
+		fmt.Fprintln(stderr, "\nFlags:")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return 2
+	}
+
+	var (
+		cfg      *config.Config
+		pm       *monitoring.PodMonitor
+		registry *prometheus.Registry
+		logger   *zap.SugaredLogger
+	)
+	application := app.NewCycle(*src, &cfg, &pm, &registry, &logger)
+	if err := application.Err(); err != nil {
+		fmt.Fprintf(stderr, "failed to set up the cycle: %v\n", err)
+		return exitFailed
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	startCtx, cancel := context.WithTimeout(ctx, application.StartTimeout())
+	defer cancel()
+	if err := application.Start(startCtx); err != nil {
+		fmt.Fprintf(stderr, "failed to start: %v\n", err)
+		return exitFailed
+	}
+
+	cycleCtx, cancelCycle := context.WithTimeout(ctx, cycleTimeout(cfg))
+	defer cancelCycle()
+	report, err := pm.Run(cycleCtx, monitoring.RunOptions{})
+	code := exitCode(report, err)
+	if err != nil {
+		logger.Errorw("Monitoring cycle failed", "error", err, "exitCode", code)
+	} else {
+		logger.Infow("Monitoring cycle finished", "cycle", report.CycleID, "examined", report.Examined,
+			"actions", report.Actions, "unlisted", report.Unlisted, "paused", report.Paused, "exitCode", code)
+	}
+
+	// Stopping flushes the notifications, the audit log and the telemetry
+	stopCtx, cancelStop := context.WithTimeout(context.Background(), application.StopTimeout())
+	defer cancelStop()
+	if err := application.Stop(stopCtx); err != nil {
+		fmt.Fprintf(stderr, "failed to stop: %v\n", err)
+	}
+
+	if err := pushMetrics(cfg.Metrics.Pushgateway, registry); err != nil {
+		fmt.Fprintf(stderr, "failed to push metrics: %v\n", err)
+		if code == 0 {
+			code = exitFailed
+		}
+	}
+	return code
+}
+
+// cycleTimeout returns the configured cycle timeout, defaulting to the schedule interval
+func cycleTimeout(cfg *config.Config) time.Duration {
+	if cfg.Watchdog.CycleTimeout > 0 {
+		return cfg.Watchdog.CycleTimeout
+	}
+	return cfg.Watchdog.ScheduleInterval
+}
+
+// exitCode tells how a cycle went: failures come first, then the partial
+// failures, then the pauses holding terminations back
+func exitCode(report *monitoring.CycleReport, err error) int {
+	switch {
+	case err != nil:
+		return exitFailed
+	case len(report.Unlisted) > 0 || report.Actions[monitoring.ActionFailed] > 0:
+		return exitPartial
+	case report.Paused != "":
+		return exitPaused
+	default:
+		return 0
+	}
+}
+
+// pushMetrics replaces the metrics of the job on the Pushgateway with those of
+// the registry, it does nothing without a URL
+func pushMetrics(cfg config.PushgatewayConfig, gatherer prometheus.Gatherer) error {
+	if cfg.URL == "" {
+		return nil
+	}
+	return push.New(cfg.URL, cfg.Job).
+		Client(&http.Client{Timeout: cfg.Timeout}).
+		Gatherer(gatherer).
+		Push()
+}
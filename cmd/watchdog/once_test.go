@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+func TestExitCode(t *testing.T) {
+	report := func(modify func(*monitoring.CycleReport)) *monitoring.CycleReport {
+		r := &monitoring.CycleReport{Actions: map[monitoring.Action]int{monitoring.ActionTerminated: 2}}
+		modify(r)
+		return r
+	}
+
+	require.Equal(t, 0, exitCode(report(func(*monitoring.CycleReport) {}), nil))
+	require.Equal(t, exitFailed, exitCode(report(func(*monitoring.CycleReport) {}), errors.New("monitoring cycle interrupted")))
+	require.Equal(t, exitPartial, exitCode(report(func(r *monitoring.CycleReport) { r.Unlisted = []string{"default"} }), nil))
+	require.Equal(t, exitPartial, exitCode(report(func(r *monitoring.CycleReport) { r.Actions[monitoring.ActionFailed] = 1 }), nil))
+	require.Equal(t, exitPaused, exitCode(report(func(r *monitoring.CycleReport) { r.Paused = "paused by alice" }), nil))
+	require.Equal(t, exitPartial, exitCode(report(func(r *monitoring.CycleReport) {
+		r.Paused = "paused by alice"
+		r.Actions[monitoring.ActionFailed] = 1
+	}), nil), "failures come before pauses")
+}
+
+func TestPushMetrics(t *testing.T) {
+	var method, path, body string
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gateway.Close()
+
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "watchdog_pods_examined_total"})
+	registry.MustRegister(counter)
+	counter.Add(3)
+
+	require.NoError(t, pushMetrics(config.PushgatewayConfig{}, registry), "nothing is pushed without a URL")
+	require.Empty(t, method)
+
+	cfg := config.PushgatewayConfig{URL: gateway.URL, Job: "watchdog-sandboxes", Timeout: time.Second}
+	require.NoError(t, pushMetrics(cfg, registry))
+	require.Equal(t, http.MethodPut, method, "a push replaces the metrics of the previous run")
+	require.Equal(t, "/metrics/job/watchdog-sandboxes", path)
+	require.NotEmpty(t, body)
+
+	gateway.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	require.Error(t, pushMetrics(cfg, registry))
+}
+
+func TestOnceCommand(t *testing.T) {
+	run := func(args ...string) (int, string) {
+		var stdout, stderr bytes.Buffer
+		code := runCommand(args, &stdout, &stderr)
+		return code, stderr.String()
+	}
+
+	t.Run("rejects arguments", func(t *testing.T) {
+		code, stderr := run("once", "default")
+		require.Equal(t, 2, code)
+		require.Contains(t, stderr, "Usage: watchdog once")
+	})
+
+	t.Run("fails on an invalid config", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("watchdog:\n  scheduleInterval: 0s\n"), 0o600))
+		code, stderr := run("once", "--config", path)
+		require.Equal(t, exitFailed, code)
+		require.Contains(t, stderr, "failed to set up the cycle: ")
+		require.Contains(t, stderr, "watchdog.scheduleInterval: must be positive, got 0s")
+	})
+}
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+// planResponse is the JSON output of the plan command, shaped as GET /api/v1/candidates
+type planResponse struct {
+	EvaluatedAt time.Time              `json:"evaluatedAt"`
+	Policy      string                 `json:"policy"`
+	DryRun      bool                   `json:"dryRun"`
+	Count       int                    `json:"count"`
+	Candidates  []monitoring.Candidate `json:"candidates"`
+}
+
+// planChange is a pod whose verdict differs between two configurations, a nil
+// side means the configuration does not match the pod
+type planChange struct {
+	Namespace string                `json:"namespace"`
+	Name      string                `json:"name"`
+	Before    *monitoring.Candidate `json:"before"`
+	After     *monitoring.Candidate `json:"after"`
+}
+
+// planDiff is the JSON output of the plan command with --diff
+type planDiff struct {
+	EvaluatedAt time.Time    `json:"evaluatedAt"`
+	Count       int          `json:"count"`
+	Changes     []planChange `json:"changes"`
+}
+
+// planCommand evaluates the pods of a manifest dump against a configuration,
+// offline, to review policy changes
+func planCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("plan", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", "", "config file to evaluate, looked up as for watchdog run when empty")
+	podsPath := flags.String("pods", "", "kubectl get pods -o json dump, or a directory of YAML or JSON manifests")
+	at := flags.String("at", "", "evaluation time, RFC 3339 or a duration from now such as 6h (default now)")
+	output := flags.String("output", "table", "output format, table or json")
+	diffPath := flags.String("diff", "", "config file to compare with, only the pods whose verdict changes are printed")
+	all := flags.Bool("all", false, "also print the pods that do not expire")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: watchdog plan [--config FILE] --pods PATH [--at TIME] [--output table|json] [--diff FILE] [--all]")
+		fmt.Fprintln(stderr, "\nPrint which pods the policy would expire and why, without a cluster.")
+		fmt.Fprintln(stderr, "\nFlags:")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if flags.NArg() > 0 || *podsPath == "" || (*output != "table" && *output != "json") {
+		flags.Usage()
+		return 2
+	}
+
+	read := time.Now().UTC()
+	now, err := parseAt(*at, read)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid --at: %v\n", err)
+		return 2
+	}
+	pods, err := readPods(*podsPath, read)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to read pods: %v\n", err)
+		return 1
+	}
+
+	cfg, candidates, err := planConfig(*configPath, pods, now)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	if *diffPath != "" {
+		_, before, err := planConfig(*diffPath, pods, now)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		changes := diffPlans(before, candidates)
+		if *output == "json" {
+			return writePlanJSON(stdout, stderr, planDiff{EvaluatedAt: now, Count: len(changes), Changes: changes})
+		}
+		writeDiffTable(stdout, changes, *diffPath, now)
+		return 0
+	}
+
+	if !*all {
+		candidates = slices.DeleteFunc(candidates, func(c monitoring.Candidate) bool {
+			return c.Verdict == monitoring.VerdictActive
+		})
+	}
+	if *output == "json" {
+		return writePlanJSON(stdout, stderr, planResponse{
+			EvaluatedAt: now,
+			Policy:      cfg.Watchdog.Policy,
+			DryRun:      cfg.Watchdog.DryRun,
+			Count:       len(candidates),
+			Candidates:  candidates,
+		})
+	}
+	writePlanTable(stdout, cfg.Watchdog.Policy, candidates, now)
+	return 0
+}
+
+// planConfig loads a configuration without resolving its secrets and evaluates the pods
+func planConfig(path string, pods []v1.Pod, now time.Time) (*config.Config, []monitoring.Candidate, error) {
+	cfg, err := config.NewConfig(config.Source{Path: path, Unresolved: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	candidates, err := monitoring.Plan(cfg, pods, now)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to evaluate %s: %w", cfg.Path(), err)
+	}
+	return cfg, candidates, nil
+}
+
+// parseAt parses the evaluation time, as a time or as a duration from now
+func parseAt(at string, now time.Time) (time.Time, error) {
+	if at == "" {
+		return now, nil
+	}
+	if d, err := time.ParseDuration(at); err == nil {
+		return now.Add(d), nil
+	}
+	t, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected an RFC 3339 time or a duration, got %q", at)
+	}
+	return t.UTC(), nil
+}
+
+// readPods reads the pods of a file, or of the YAML and JSON files of a
+// directory. Pods without a namespace are in default, and pods without a
+// creationTimestamp, such as manifests not applied yet, are created at the
+// given time. Other kinds of objects are skipped.
+func readPods(path string, created time.Time) ([]v1.Pod, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			switch filepath.Ext(file) {
+			case ".yaml", ".yml", ".json":
+				if !entry.IsDir() {
+					files = append(files, file)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var pods []v1.Pod
+	for _, file := range files {
+		if err := readManifests(file, &pods); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	for i := range pods {
+		if pods[i].Namespace == "" {
+			pods[i].Namespace = metav1.NamespaceDefault
+		}
+		if pods[i].CreationTimestamp.IsZero() {
+			pods[i].CreationTimestamp = metav1.NewTime(created)
+		}
+	}
+	return pods, nil
+}
+
+// readManifests appends the pods of the documents of a YAML or JSON file
+func readManifests(file string, pods *[]v1.Pod) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var document json.RawMessage
+		if err := decoder.Decode(&document); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := appendPods(document, pods); err != nil {
+			return err
+		}
+	}
+}
+
+// appendPods appends a Pod, or the pods of a List or a PodList
+func appendPods(document json.RawMessage, pods *[]v1.Pod) error {
+	var meta metav1.TypeMeta
+	if err := json.Unmarshal(document, &meta); err != nil {
+		// A document that is not an object is not a manifest
+		return nil
+	}
+	switch meta.Kind {
+	case "Pod":
+		var pod v1.Pod
+		if err := json.Unmarshal(document, &pod); err != nil {
+			return err
+		}
+		*pods = append(*pods, pod)
+	case "PodList":
+		var list v1.PodList
+		if err := json.Unmarshal(document, &list); err != nil {
+			return err
+		}
+		*pods = append(*pods, list.Items...)
+	case "List":
+		var list struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		if err := json.Unmarshal(document, &list); err != nil {
+			return err
+		}
+		for _, item := range list.Items {
+			if err := appendPods(item, pods); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// diffPlans returns the pods whose verdict, or reason to expire, differs between two plans
+func diffPlans(before, after []monitoring.Candidate) []planChange {
+	key := func(c monitoring.Candidate) string { return c.Namespace + "/" + c.Name }
+	changes := map[string]*planChange{}
+	var order []string
+	change := func(c monitoring.Candidate) *planChange {
+		k := key(c)
+		if changes[k] == nil {
+			changes[k] = &planChange{Namespace: c.Namespace, Name: c.Name}
+			order = append(order, k)
+		}
+		return changes[k]
+	}
+	for _, c := range after {
+		change(c).After = &c
+	}
+	for _, c := range before {
+		change(c).Before = &c
+	}
+
+	result := []planChange{}
+	for _, k := range order {
+		c := changes[k]
+		if c.Before != nil && c.After != nil && c.Before.Verdict == c.After.Verdict &&
+			(!expires(c.After) || c.Before.Reason == c.After.Reason) {
+			continue
+		}
+		result = append(result, *c)
+	}
+	return result
+}
+
+// writePlanTable prints the candidates as a table
+func writePlanTable(stdout io.Writer, policy string, candidates []monitoring.Candidate, now time.Time) {
+	expired := 0
+	for _, c := range candidates {
+		if c.Verdict == monitoring.VerdictExpired {
+			expired++
+		}
+	}
+	fmt.Fprintf(stdout, "Policy %s at %s: %d pods expire\n\n", policy, now.Format(time.RFC3339), expired)
+	if len(candidates) == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tVERDICT\tREASON\tDEADLINE\tREMAINING")
+	for _, c := range candidates {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", c.Namespace, c.Name, c.Verdict, reason(&c), deadline(&c), remaining(&c))
+	}
+	w.Flush()
+}
+
+// writeDiffTable prints the changes against the other configuration as a table
+func writeDiffTable(stdout io.Writer, changes []planChange, diffPath string, now time.Time) {
+	fmt.Fprintf(stdout, "Changes against %s at %s: %d pods\n\n", diffPath, now.Format(time.RFC3339), len(changes))
+	if len(changes) == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tNAMESPACE\tNAME\tBEFORE\tAFTER")
+	for _, c := range changes {
+		mark := "~"
+		switch {
+		case expires(c.After) && !expires(c.Before):
+			mark = "+"
+		case expires(c.Before) && !expires(c.After):
+			mark = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", mark, c.Namespace, c.Name, verdict(c.Before), verdict(c.After))
+	}
+	w.Flush()
+}
+
+// writePlanJSON prints the plan as indented JSON
+func writePlanJSON(stdout, stderr io.Writer, value any) int {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// expires tells whether the candidate expires
+func expires(c *monitoring.Candidate) bool {
+	return c != nil && c.Verdict == monitoring.VerdictExpired
+}
+
+// verdict describes a side of a change
+func verdict(c *monitoring.Candidate) string {
+	if c == nil {
+		return "not matched"
+	}
+	if c.Verdict == monitoring.VerdictActive {
+		return string(c.Verdict)
+	}
+	return fmt.Sprintf("%s (%s)", c.Verdict, reason(c))
+}
+
+// reason returns why the pod expires, or why it could not be evaluated
+func reason(c *monitoring.Candidate) string {
+	if c.Verdict == monitoring.VerdictInvalid {
+		return strings.ReplaceAll(c.Error, "\t", " ")
+	}
+	return c.Reason
+}
+
+// deadline formats the time the pod expires at
+func deadline(c *monitoring.Candidate) string {
+	if c.Deadline.IsZero() {
+		return "-"
+	}
+	return c.Deadline.Format(time.RFC3339)
+}
+
+// remaining formats the time left to the pod, negative when it is overdue
+func remaining(c *monitoring.Candidate) string {
+	if c.Deadline.IsZero() {
+		return "-"
+	}
+	return (time.Duration(c.RemainingSeconds) * time.Second).String()
+}
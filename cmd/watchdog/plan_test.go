@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+func TestPlanCommand(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	current := write("current.yaml", "watchdog:\n  policy: sandboxes\n  namespaces: [default, ci]\n  maxPodLifetime: 2h\n  ttlLabel: sandbox.kill_time\n")
+	proposed := write("proposed.yaml", "watchdog:\n  policy: sandboxes\n  namespaces: [default]\n  maxPodLifetime: 1h\n  ttlLabel: sandbox.kill_time\n"+
+		"notifications:\n  webhooks:\n    - url: k8s-secret:ops/chat#url\n")
+	// kubectl get pods -A -o json
+	dump := write("pods.json", `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "old", "namespace": "default", "creationTimestamp": "2026-01-02T09:00:00Z"}},
+    {"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "recent", "namespace": "default", "creationTimestamp": "2026-01-02T10:30:00Z"}},
+    {"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "labeled", "namespace": "default", "creationTimestamp": "2026-01-02T11:50:00Z",
+      "labels": {"sandbox.kill_time": "1767355200"}}},
+    {"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "runner", "namespace": "ci", "creationTimestamp": "2026-01-02T08:00:00Z"}},
+    {"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "web", "namespace": "prod", "creationTimestamp": "2026-01-01T00:00:00Z"}}
+  ]
+}`)
+
+	run := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := runCommand(append([]string{"plan"}, args...), &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	t.Run("table", func(t *testing.T) {
+		code, stdout, stderr := run("--config", current, "--pods", dump, "--at", "2026-01-02T12:00:00Z")
+		require.Equal(t, 0, code, stderr)
+		require.Equal(t, "Policy sandboxes at 2026-01-02T12:00:00Z: 3 pods expire\n\n"+
+			"NAMESPACE  NAME     VERDICT  REASON               DEADLINE              REMAINING\n"+
+			"ci         runner   expired  MaxLifetimeExceeded  2026-01-02T10:00:00Z  -2h0m0s\n"+
+			"default    old      expired  MaxLifetimeExceeded  2026-01-02T11:00:00Z  -1h0m0s\n"+
+			"default    labeled  expired  TTLExpired           2026-01-02T12:00:00Z  0s\n", stdout)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		code, stdout, stderr := run("--config", proposed, "--pods", dump, "--at", "2026-01-02T12:00:00Z", "--output", "json", "--all")
+		require.Equal(t, 0, code, stderr)
+		var plan planResponse
+		require.NoError(t, json.Unmarshal([]byte(stdout), &plan))
+		require.Equal(t, "sandboxes", plan.Policy)
+		require.Equal(t, 3, plan.Count, "the ci namespace is not monitored, the k8s-secret reference is not read")
+		require.Equal(t, monitoring.VerdictExpired, plan.Candidates[1].Verdict)
+		require.Equal(t, "recent", plan.Candidates[1].Name)
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		code, stdout, stderr := run("--config", proposed, "--diff", current, "--pods", dump, "--at", "2026-01-02T12:00:00Z")
+		require.Equal(t, 0, code, stderr)
+		require.Equal(t, "Changes against "+current+" at 2026-01-02T12:00:00Z: 2 pods\n\n"+
+			"   NAMESPACE  NAME    BEFORE                         AFTER\n"+
+			"+  default    recent  active                         expired (MaxLifetimeExceeded)\n"+
+			"-  ci         runner  expired (MaxLifetimeExceeded)  not matched\n", stdout)
+	})
+
+	t.Run("a directory of manifests", func(t *testing.T) {
+		write("manifests/sandbox.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: sandbox
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: sandbox-1
+  labels:
+    sandbox.kill_time: "1767355200"
+`)
+		write("manifests/nested/pod.yml", "apiVersion: v1\nkind: Pod\nmetadata:\n  name: sandbox-2\n  namespace: ci\n")
+		write("manifests/README.md", "not a manifest")
+		pods, err := readPods(filepath.Join(dir, "manifests"), time.Date(2026, 1, 2, 11, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		require.Len(t, pods, 2)
+		require.Equal(t, "sandbox-2", pods[0].Name)
+		require.Equal(t, "default", pods[1].Namespace, "pods without a namespace are in default")
+		require.Equal(t, time.Date(2026, 1, 2, 11, 0, 0, 0, time.UTC), pods[1].CreationTimestamp.UTC(), "manifests are created when read")
+
+		code, stdout, stderr := run("--config", current, "--pods", filepath.Join(dir, "manifests"), "--at", "3h")
+		require.Equal(t, 0, code, stderr)
+		require.Contains(t, stdout, ": 2 pods expire\n")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		code, _, stderr := run("--config", current)
+		require.Equal(t, 2, code)
+		require.Contains(t, stderr, "Usage: watchdog plan")
+
+		code, _, stderr = run("--config", current, "--pods", dump, "--at", "tomorrow")
+		require.Equal(t, 2, code)
+		require.Equal(t, "invalid --at: expected an RFC 3339 time or a duration, got \"tomorrow\"\n", stderr)
+
+		code, _, stderr = run("--config", current, "--pods", filepath.Join(dir, "missing.json"))
+		require.Equal(t, 1, code)
+		require.Contains(t, stderr, "failed to read pods: ")
+	})
+}
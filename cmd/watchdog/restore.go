@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/isdmx/watchdog/internal/client"
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/snapshot"
+)
+
+// restoreCommand recreates a pod from the latest snapshot in the configured store
+func restoreCommand(args []string, stdout, stderr io.Writer) int {
+	src := &config.Source{Secrets: client.NewSecretReader()}
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	src.RegisterFlags(flags)
+	dryRun := flags.Bool("dry-run", false, "print the pod manifest instead of creating it")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: watchdog restore [--dry-run] [--config FILE] [--SETTING VALUE]... NAMESPACE/POD")
+		fmt.Fprintln(stderr, "\nRecreate a pod from its latest snapshot, with the configuration of the watchdog.")
+		fmt.Fprintln(stderr, "\nFlags:")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	namespace, name, ok := strings.Cut(flags.Arg(0), "/")
+	if flags.NArg() != 1 || !ok || namespace == "" || name == "" {
+		flags.Usage()
+		return 2
+	}
+
+	cfg, err := config.NewConfig(*src)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+	clientset, err := client.NewKubernetesClient(zap.NewNop().Sugar())
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to create Kubernetes client: %v\n", err)
+		return 1
+	}
+	store, err := snapshot.NewStore(clientset, cfg.Snapshots)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	if err := restorePod(context.Background(), clientset, store, cfg.Watchdog.TtlLabel, namespace, name, *dryRun, stdout); err != nil {
+		fmt.Fprintf(stderr, "failed to restore %s/%s: %v\n", namespace, name, err)
+		return 1
+	}
+	return 0
+}
+
+// restorePod creates the pod from its latest snapshot, or prints it in dry-run mode
+func restorePod(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	store snapshot.Store,
+	ttlLabel, namespace, name string,
+	dryRun bool,
+	stdout io.Writer,
+) error {
+	latest, err := store.Latest(ctx, namespace, name)
+	if errors.Is(err, snapshot.ErrNotFound) {
+		return fmt.Errorf("no snapshot of %s/%s in the configured store", namespace, name)
+	}
+	if err != nil {
+		return err
+	}
+	pod := snapshot.PrepareRestore(latest.Pod, ttlLabel)
+
+	if dryRun {
+		data, err := json.MarshalIndent(pod, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(stdout, string(data))
+		return err
+	}
+
+	if _, err := clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "pod/%s restored in namespace %s from the snapshot taken at %s\n",
+		name, namespace, latest.Taken.UTC().Format(time.RFC3339))
+	return nil
+}
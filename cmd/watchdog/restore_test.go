@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/isdmx/watchdog/internal/snapshot"
+)
+
+func TestRestorePod(t *testing.T) {
+	ctx := context.Background()
+	store := snapshot.NewDirectoryStore(t.TempDir())
+	pod := snapshot.Sanitize(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sandbox-1",
+			Namespace: "default",
+			UID:       "uid-1",
+			Labels:    map[string]string{"app": "sandbox", "sandbox.kill_time": "1700000000"},
+		},
+		Spec: v1.PodSpec{NodeName: "node-1", Containers: []v1.Container{{Name: "main", Image: "busybox"}}},
+	})
+	taken := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Save(ctx, &snapshot.Snapshot{Namespace: "default", Name: "sandbox-1", Taken: taken, Pod: pod}))
+
+	t.Run("dry run prints the manifest", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		var stdout bytes.Buffer
+		require.NoError(t, restorePod(ctx, clientset, store, "sandbox.kill_time", "default", "sandbox-1", true, &stdout))
+
+		var printed v1.Pod
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &printed))
+		require.Equal(t, "sandbox-1", printed.Name)
+		require.Equal(t, map[string]string{"app": "sandbox"}, printed.Labels)
+
+		pods, err := clientset.CoreV1().Pods("default").List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		require.Empty(t, pods.Items)
+	})
+
+	t.Run("creates the pod", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		var stdout bytes.Buffer
+		require.NoError(t, restorePod(ctx, clientset, store, "sandbox.kill_time", "default", "sandbox-1", false, &stdout))
+		require.Contains(t, stdout.String(), "pod/sandbox-1 restored in namespace default")
+
+		restored, err := clientset.CoreV1().Pods("default").Get(ctx, "sandbox-1", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Empty(t, restored.Spec.NodeName)
+		require.Equal(t, "busybox", restored.Spec.Containers[0].Image)
+	})
+
+	t.Run("missing snapshot", func(t *testing.T) {
+		err := restorePod(ctx, fake.NewSimpleClientset(), store, "", "default", "other", false, &bytes.Buffer{})
+		require.ErrorContains(t, err, "no snapshot of default/other")
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		for _, args := range [][]string{{"restore"}, {"restore", "sandbox-1"}, {"restore", "default/"}} {
+			var stdout, stderr bytes.Buffer
+			require.Equal(t, 2, runCommand(args, &stdout, &stderr), args)
+		}
+	})
+}
+
+func TestRestoreCommandReadsConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.yaml")
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 1, restoreCommand([]string{"--config", path, "--dry-run", "default/sandbox-1"}, &stdout, &stderr))
+	require.Contains(t, stderr.String(), "failed to load config")
+	require.Contains(t, stderr.String(), path)
+
+	stderr.Reset()
+	require.Equal(t, 2, restoreCommand([]string{"--config", path}, &stdout, &stderr))
+	require.Contains(t, stderr.String(), "Usage: watchdog restore")
+}
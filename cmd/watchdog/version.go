@@ -0,0 +1,50 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+)
+
+// version is set at build time with -ldflags "-X main.version=v1.2.3", builds
+// without it report the module version
+var version string
+
+// versionCommand prints the build information
+func versionCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		fmt.Fprintln(stderr, "Usage: watchdog version")
+		return 2
+	}
+	info, _ := debug.ReadBuildInfo()
+	fmt.Fprint(stdout, buildInfo(info))
+	return 0
+}
+
+// buildInfo describes the version, the VCS revision and the toolchain of the binary
+func buildInfo(info *debug.BuildInfo) string {
+	var settings map[string]string
+	moduleVersion := ""
+	if info != nil {
+		moduleVersion = info.Main.Version
+		settings = make(map[string]string, len(info.Settings))
+		for _, setting := range info.Settings {
+			settings[setting.Key] = setting.Value
+		}
+	}
+
+	text := fmt.Sprintf("watchdog %s\n", cmp.Or(version, moduleVersion, "(devel)"))
+	if revision := settings["vcs.revision"]; revision != "" {
+		if settings["vcs.modified"] == "true" {
+			revision += " (modified)"
+		}
+		text += fmt.Sprintf("  commit: %s\n", revision)
+	}
+	if built := settings["vcs.time"]; built != "" {
+		text += fmt.Sprintf("  date:   %s\n", built)
+	}
+	text += fmt.Sprintf("  go:     %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	return text
+}
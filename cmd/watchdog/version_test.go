@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildInfo(t *testing.T) {
+	goLine := "  go:     " + runtime.Version() + " " + runtime.GOOS + "/" + runtime.GOARCH + "\n"
+
+	info := &debug.BuildInfo{
+		Main: debug.Module{Version: "v1.4.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "0123abcd"},
+			{Key: "vcs.time", Value: "2026-10-01T12:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+	require.Equal(t, "watchdog v1.4.0\n  commit: 0123abcd (modified)\n  date:   2026-10-01T12:00:00Z\n"+goLine, buildInfo(info))
+
+	version = "v1.5.0-rc.1"
+	t.Cleanup(func() { version = "" })
+	require.Equal(t, "watchdog v1.5.0-rc.1\n"+goLine, buildInfo(&debug.BuildInfo{}), "the version set at build time wins")
+	require.Equal(t, "watchdog v1.5.0-rc.1\n"+goLine, buildInfo(nil))
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, runCommand([]string{"version"}, &stdout, &stderr))
+	require.Contains(t, stdout.String(), "watchdog v1.5.0-rc.1\n")
+	require.Equal(t, 2, runCommand([]string{"version", "--json"}, &stdout, &stderr))
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/isdmx/watchdog/internal/auth"
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+	"github.com/isdmx/watchdog/internal/pause"
+	"github.com/isdmx/watchdog/internal/server"
+)
+
+// maxRequestBytes bounds the body of every request
+const maxRequestBytes = 64 * 1024
+
+var _ server.Routes = (*API)(nil)
+
+// API serves the watchdog's JSON API under /api/v1, reporting the configuration
+// the pod monitor currently runs with
+type API struct {
+	pm     *monitoring.PodMonitor
+	wd     *server.WatchdogServer
+	pause  *pause.Switch
+	config *config.Config
+	logger *zap.SugaredLogger
+}
+
+// NewAPI creates a new API
+func NewAPI(
+	pm *monitoring.PodMonitor,
+	wd *server.WatchdogServer,
+	pauseSwitch *pause.Switch,
+	cfg *config.Config,
+	logger *zap.SugaredLogger,
+) *API {
+	return &API{
+		pm:     pm,
+		wd:     wd,
+		pause:  pauseSwitch,
+		config: cfg,
+		logger: logger.Named("API"),
+	}
+}
+
+// RegisterRoutes registers the API routes
+func (a *API) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/status", a.status)
+	mux.HandleFunc("GET /api/v1/candidates", a.candidates)
+	mux.HandleFunc("GET /api/v1/runs", a.listRuns)
+	mux.HandleFunc("POST /api/v1/runs", a.triggerRun)
+	mux.HandleFunc("GET /api/v1/runs/{id}", a.getRun)
+	mux.HandleFunc("POST /api/v1/pods/{namespace}/{name}/extend", a.extendPod)
+	mux.HandleFunc("GET /api/v1/pause", a.pauseStatus)
+
+	// Anyone reaching the port could stop every termination, so pausing needs
+	// authentication unless explicitly allowed without
+	if a.config.HTTP.Auth.Enabled() || a.config.HTTP.Auth.AllowUnauthenticatedPause {
+		mux.HandleFunc("POST /api/v1/pause", a.pauseTerminations)
+		mux.HandleFunc("POST /api/v1/resume", a.resumeTerminations)
+	} else {
+		a.logger.Info("Pause endpoints disabled, enable authentication or http.auth.allowUnauthenticatedPause")
+	}
+}
+
+// authorize checks that the caller may perform the action, writing the error
+// response when not
+func (a *API) authorize(w http.ResponseWriter, r *http.Request, attrs auth.Attributes) bool {
+	allowed, err := auth.Allowed(r.Context(), attrs)
+	if err != nil {
+		a.logger.Errorw("Failed to authorize request", "action", attrs.String(), "error", err)
+		a.writeError(w, http.StatusBadGateway, "authorization failed: "+err.Error())
+		return false
+	}
+	if !allowed {
+		a.writeError(w, http.StatusForbidden, "forbidden: cannot "+attrs.String())
+		return false
+	}
+	return true
+}
+
+// visibleNamespaces returns a check of whether the caller may view the pods of a
+// namespace. Failed reviews hide the namespace.
+func (a *API) visibleNamespaces(r *http.Request) func(namespace string) bool {
+	return func(namespace string) bool {
+		allowed, err := auth.Allowed(r.Context(), auth.PodAttributes("get", namespace))
+		if err != nil {
+			a.logger.Errorw("Failed to authorize request", "namespace", namespace, "error", err)
+		}
+		return allowed
+	}
+}
+
+// errorResponse is the body of every failed request
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// decodeJSON decodes an optional JSON request body, rejecting unknown fields
+func decodeJSON(r *http.Request, v any) error {
+	decoder := json.NewDecoder(io.LimitReader(r.Body, maxRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// writeJSON writes a JSON response with the given status
+func (a *API) writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		a.logger.Errorw("Failed to write response", "error", err)
+	}
+}
+
+// writeError writes a JSON error response
+func (a *API) writeError(w http.ResponseWriter, status int, message string) {
+	a.writeJSON(w, status, errorResponse{Error: message})
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/isdmx/watchdog/internal/auth"
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+	"github.com/isdmx/watchdog/internal/pause"
+	"github.com/isdmx/watchdog/internal/server"
+)
+
+func newTestAPI(t *testing.T, pods ...*v1.Pod) (*API, *http.ServeMux) {
+	t.Helper()
+	cfg := newTestConfig()
+	cfg.HTTP.Auth.AllowUnauthenticatedPause = true
+	return newTestAPIWithConfig(t, cfg, pods...)
+}
+
+func newTestConfig() *config.Config {
+	return &config.Config{Watchdog: config.WatchdogConfig{
+		Policy:           "default",
+		Namespaces:       []string{"default"},
+		MaxPodLifetime:   time.Hour,
+		ScheduleInterval: time.Hour,
+		DryRun:           true,
+	}}
+}
+
+func newTestAPIWithConfig(t *testing.T, cfg *config.Config, pods ...*v1.Pod) (*API, *http.ServeMux) {
+	t.Helper()
+	objects := make([]runtime.Object, 0, len(pods))
+	for _, pod := range pods {
+		objects = append(objects, pod)
+	}
+	clientset := fake.NewSimpleClientset(objects...)
+	lc := fxtest.NewLifecycle(t)
+	pauseSwitch := pause.NewSwitch(lc, clientset, cfg, zap.NewNop().Sugar(), nil)
+	pm := monitoring.NewPodMonitor(clientset, cfg, nil, pauseSwitch, zap.NewNop().Sugar(), nil, nil, nil)
+	wd := server.NewWatchdogServer(lc, pm, zap.NewNop().Sugar(), cfg)
+
+	api := NewAPI(pm, wd, pauseSwitch, cfg, zap.NewNop().Sugar())
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux)
+	return api, mux
+}
+
+func newPod(name string, age time.Duration) *v1.Pod {
+	return &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:              name,
+		Namespace:         "default",
+		CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+	}}
+}
+
+func TestWriteError(t *testing.T) {
+	api, _ := newTestAPI(t)
+	recorder := httptest.NewRecorder()
+	api.writeError(recorder, http.StatusTeapot, "short and stout")
+
+	require.Equal(t, http.StatusTeapot, recorder.Code)
+	require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"error": "short and stout"}`, recorder.Body.String())
+}
+
+// rules authorizes the listed actions, failing for the others when err is set
+type rules struct {
+	allowed []auth.Attributes
+	err     error
+}
+
+func (r rules) Authorize(_ context.Context, _ auth.Identity, attrs auth.Attributes) (bool, error) {
+	for _, allowed := range r.allowed {
+		if allowed == attrs {
+			return true, nil
+		}
+	}
+	return false, r.err
+}
+
+// asUser makes the request on behalf of a Kubernetes user allowed the listed actions
+func asUser(r *http.Request, name string, allowed ...auth.Attributes) *http.Request {
+	return r.WithContext(auth.NewContext(r.Context(), auth.Identity{Name: name}, rules{allowed: allowed}))
+}
+
+func TestAuthorize(t *testing.T) {
+	api, _ := newTestAPI(t)
+	attrs := auth.PodAttributes("patch", "default")
+	request := httptest.NewRequest(http.MethodPost, "/", http.NoBody)
+
+	recorder := httptest.NewRecorder()
+	require.True(t, api.authorize(recorder, request, attrs), "authentication is disabled")
+
+	recorder = httptest.NewRecorder()
+	require.True(t, api.authorize(recorder, asUser(request, "alice", attrs), attrs))
+
+	recorder = httptest.NewRecorder()
+	require.False(t, api.authorize(recorder, asUser(request, "alice"), attrs))
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	require.JSONEq(t, `{"error": "forbidden: cannot patch pods in namespace default"}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	failing := request.WithContext(auth.NewContext(request.Context(), auth.Identity{Name: "alice"}, rules{err: errors.New("timeout")}))
+	require.False(t, api.authorize(recorder, failing, attrs))
+	require.Equal(t, http.StatusBadGateway, recorder.Code)
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+// candidatesResponse is the body of GET /api/v1/candidates
+type candidatesResponse struct {
+	EvaluatedAt time.Time              `json:"evaluatedAt"`
+	DryRun      bool                   `json:"dryRun"`
+	Count       int                    `json:"count"`
+	Candidates  []monitoring.Candidate `json:"candidates"`
+}
+
+// candidates evaluates the matching pods without acting on them. The namespace,
+// policy and verdict query parameters filter the result, pods in namespaces the
+// caller may not view are left out.
+func (a *API) candidates(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := monitoring.CandidateFilter{
+		Namespace: query.Get("namespace"),
+		Policy:    query.Get("policy"),
+		Verdict:   monitoring.CandidateVerdict(query.Get("verdict")),
+	}
+	if filter.Verdict != "" && !slices.Contains(monitoring.CandidateVerdicts, filter.Verdict) {
+		a.writeError(w, http.StatusBadRequest,
+			fmt.Sprintf("unknown verdict %q, expected one of %v", filter.Verdict, monitoring.CandidateVerdicts))
+		return
+	}
+
+	now := time.Now().UTC()
+	candidates, err := a.pm.Candidates(r.Context(), filter, now)
+	if err != nil {
+		a.logger.Errorw("Failed to evaluate candidates", "error", err)
+		a.writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	visible := a.visibleNamespaces(r)
+	candidates = slices.DeleteFunc(candidates, func(c monitoring.Candidate) bool {
+		return !visible(c.Namespace)
+	})
+
+	a.writeJSON(w, http.StatusOK, candidatesResponse{
+		EvaluatedAt: now,
+		DryRun:      a.pm.Config().Watchdog.DryRun,
+		Count:       len(candidates),
+		Candidates:  candidates,
+	})
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/isdmx/watchdog/internal/auth"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+func TestCandidates(t *testing.T) {
+	_, mux := newTestAPI(t, newPod("expired", 2*time.Hour), newPod("young", time.Minute))
+
+	get := func(url string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, http.NoBody))
+		return recorder
+	}
+
+	t.Run("lists candidates", func(t *testing.T) {
+		recorder := get("/api/v1/candidates")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+		var response candidatesResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		require.True(t, response.DryRun)
+		require.Equal(t, 2, response.Count)
+		require.Equal(t, "expired", response.Candidates[0].Name)
+		require.Equal(t, monitoring.VerdictExpired, response.Candidates[0].Verdict)
+		require.Equal(t, monitoring.ReasonMaxLifetime, response.Candidates[0].Reason)
+	})
+
+	t.Run("filters by verdict", func(t *testing.T) {
+		recorder := get("/api/v1/candidates?verdict=active&namespace=default&policy=default")
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var response candidatesResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		require.Equal(t, 1, response.Count)
+		require.Equal(t, "young", response.Candidates[0].Name)
+	})
+
+	t.Run("hides namespaces the caller may not view", func(t *testing.T) {
+		for allowed, want := range map[auth.Attributes]int{
+			auth.PodAttributes("get", "default"):   2,
+			auth.PodAttributes("patch", "default"): 0,
+		} {
+			recorder := httptest.NewRecorder()
+			mux.ServeHTTP(recorder, asUser(httptest.NewRequest(http.MethodGet, "/api/v1/candidates", http.NoBody), "alice", allowed))
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			var response candidatesResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			require.Equal(t, want, response.Count, allowed.String())
+		}
+	})
+
+	t.Run("rejects unknown verdicts", func(t *testing.T) {
+		recorder := get("/api/v1/candidates?verdict=doomed")
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+		var response errorResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		require.Contains(t, response.Error, "doomed")
+	})
+
+	t.Run("only GET", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/candidates", http.NoBody))
+		require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	})
+}
@@ -0,0 +1,35 @@
+// Package api serves the watchdog's JSON API on the HTTP server.
+//
+// Endpoints:
+//   - GET /api/v1/status: the policy, the watched namespaces, the pause state
+//     and the extension limits.
+//   - GET /api/v1/candidates: every pod matched by the policy with its age,
+//     deadline, time remaining, verdict and reason, evaluated without acting.
+//     Filter with the namespace, policy and verdict (expired, active, invalid)
+//     query parameters.
+//   - POST /api/v1/runs: queue a monitoring cycle right away, optionally scoped
+//     by {"namespaces": [...], "policy": "...", "dryRun": true}. Triggered and
+//     scheduled cycles share one loop, so they never overlap. Turning dry-run
+//     off needs an authenticated caller.
+//   - GET /api/v1/runs: the recent runs, scheduled and triggered, newest first.
+//   - GET /api/v1/runs/{id}: the status and results of one of the recent runs.
+//   - POST /api/v1/pods/{namespace}/{name}/extend: move the pod's deadline by
+//     {"duration": "1h"}, rewriting its TTL label within the configured maximum
+//     lifetime and number of extensions, and return the new deadline.
+//   - GET /api/v1/pause: the pauses in effect.
+//   - POST /api/v1/pause: pause terminations, optionally of a single policy,
+//     with {"policy": "...", "by": "...", "reason": "...", "duration": "30m"}
+//     or an "until" time for resuming on their own.
+//   - POST /api/v1/resume: lift the API pause of {"policy": "..."}.
+//
+// The pause and resume endpoints are only served with authentication enabled
+// or http.auth.allowUnauthenticatedPause set.
+//
+// With authentication enabled, handlers authorize the caller through the auth
+// package: candidates and run results are limited to the namespaces where the
+// caller may get pods, extending needs patch on pods in the namespace, and
+// pausing, resuming and triggering runs need post on the path. Pauses and
+// extensions then record the authenticated user instead of "by".
+//
+// Responses are JSON; failed requests return {"error": "..."}.
+package api
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/isdmx/watchdog/internal/auth"
+	"github.com/isdmx/watchdog/internal/client"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+// extendRequest is the body of POST /api/v1/pods/{namespace}/{name}/extend
+type extendRequest struct {
+	Duration string `json:"duration"`
+	By       string `json:"by"`
+}
+
+// extendPod moves a pod's deadline by the requested duration and returns the new deadline
+func (a *API) extendPod(w http.ResponseWriter, r *http.Request) {
+	namespace := r.PathValue("namespace")
+	if !a.authorize(w, r, auth.PodAttributes("patch", namespace)) {
+		return
+	}
+
+	var req extendRequest
+	if err := decodeJSON(r, &req); err != nil {
+		a.writeError(w, http.StatusBadRequest, "invalid extend request: "+err.Error())
+		return
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration <= 0 {
+		a.writeError(w, http.StatusBadRequest, fmt.Sprintf("duration must be a positive duration such as \"1h\", got %q", req.Duration))
+		return
+	}
+
+	extension, err := a.pm.Extend(r.Context(), namespace, r.PathValue("name"), duration, requester(req.By, r), time.Now())
+	switch {
+	case err == nil:
+		a.writeJSON(w, http.StatusOK, extension)
+	case errors.Is(err, monitoring.ErrExtensionsDisabled), errors.Is(err, monitoring.ErrNotManaged):
+		a.writeError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, monitoring.ErrExtensionRefused):
+		a.writeError(w, http.StatusConflict, err.Error())
+	case client.Classify(err) == client.ClassNotFound:
+		a.writeError(w, http.StatusNotFound, "pod not found")
+	default:
+		a.logger.Errorw("Failed to extend pod", "error", err)
+		a.writeError(w, http.StatusBadGateway, err.Error())
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/isdmx/watchdog/internal/auth"
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+func TestExtendPod(t *testing.T) {
+	sandbox := newPod("sandbox", 10*time.Minute)
+	sandbox.Labels = map[string]string{"sandbox.kill_time": strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)}
+	api, mux := newTestAPI(t, sandbox, newPod("expired", 5*time.Hour))
+	api.pm.Config().Watchdog.TtlLabel = "sandbox.kill_time"
+	api.pm.Config().Watchdog.MaxPodLifetime = 4 * time.Hour
+	api.pm.Config().Watchdog.Extensions = config.ExtensionsConfig{Enabled: true, MaxExtensions: 1}
+
+	extend := func(name, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/pods/default/"+name+"/extend", strings.NewReader(body)))
+		return recorder
+	}
+
+	recorder := extend("sandbox", `{"duration": "1h", "by": "alice"}`)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var extension monitoring.Extension
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &extension))
+	require.Equal(t, 1, extension.Extensions)
+	require.Equal(t, extension.PreviousDeadline.Add(time.Hour), extension.Deadline)
+
+	require.Equal(t, http.StatusConflict, extend("sandbox", `{"duration": "1h"}`).Code)
+	require.Equal(t, http.StatusConflict, extend("expired", `{"duration": "1h"}`).Code)
+	require.Equal(t, http.StatusNotFound, extend("missing", `{"duration": "1h"}`).Code)
+	require.Equal(t, http.StatusBadRequest, extend("sandbox", `{"duration": "-1h"}`).Code)
+	require.Equal(t, http.StatusBadRequest, extend("sandbox", `{}`).Code)
+
+	api.pm.Config().Watchdog.Extensions.Enabled = false
+	require.Equal(t, http.StatusForbidden, extend("sandbox", `{"duration": "1h"}`).Code)
+}
+
+func TestExtendPodAuthorization(t *testing.T) {
+	sandbox := newPod("sandbox", 10*time.Minute)
+	sandbox.Labels = map[string]string{"sandbox.kill_time": strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)}
+	api, mux := newTestAPI(t, sandbox)
+	api.pm.Config().Watchdog.TtlLabel = "sandbox.kill_time"
+	api.pm.Config().Watchdog.MaxPodLifetime = 4 * time.Hour
+	api.pm.Config().Watchdog.Extensions = config.ExtensionsConfig{Enabled: true, MaxExtensions: 1}
+
+	extend := func(allowed ...auth.Attributes) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/pods/default/sandbox/extend", strings.NewReader(`{"duration": "1h", "by": "mallory"}`))
+		mux.ServeHTTP(recorder, asUser(request, "alice", allowed...))
+		return recorder
+	}
+
+	require.Equal(t, http.StatusForbidden, extend(auth.PodAttributes("get", "default")).Code)
+
+	recorder := extend(auth.PodAttributes("patch", "default"))
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+}
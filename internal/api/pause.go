@@ -0,0 +1,122 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/isdmx/watchdog/internal/auth"
+	"github.com/isdmx/watchdog/internal/pause"
+)
+
+// pauseRequest is the body of POST /api/v1/pause, an empty policy pauses every policy
+type pauseRequest struct {
+	Policy   string    `json:"policy"`
+	By       string    `json:"by"`
+	Reason   string    `json:"reason"`
+	Duration string    `json:"duration"`
+	Until    time.Time `json:"until"`
+}
+
+// resumeRequest is the body of POST /api/v1/resume
+type resumeRequest struct {
+	Policy string `json:"policy"`
+	By     string `json:"by"`
+}
+
+// pauseStatus reports the pauses in effect
+func (a *API) pauseStatus(w http.ResponseWriter, _ *http.Request) {
+	a.writeJSON(w, http.StatusOK, a.pause.Status())
+}
+
+// pauseTerminations pauses terminations until resumed or until the optional auto-resume time
+func (a *API) pauseTerminations(w http.ResponseWriter, r *http.Request) {
+	if !a.authorize(w, r, auth.PathAttributes("post", r.URL.Path)) {
+		return
+	}
+	var req pauseRequest
+	if err := decodeJSON(r, &req); err != nil {
+		a.writeError(w, http.StatusBadRequest, "invalid pause request: "+err.Error())
+		return
+	}
+	if err := a.checkPolicy(req.Policy); err != nil {
+		a.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	until, err := resumeTime(req, time.Now())
+	if err != nil {
+		a.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	a.pause.Pause(pause.Pause{
+		Policy: req.Policy,
+		By:     requester(req.By, r),
+		Reason: req.Reason,
+		Until:  until,
+	})
+	a.writeJSON(w, http.StatusOK, a.pause.Status())
+}
+
+// resumeTerminations lifts an API pause. Pauses set in the ConfigMap must be lifted
+// there, so the response still lists them.
+func (a *API) resumeTerminations(w http.ResponseWriter, r *http.Request) {
+	if !a.authorize(w, r, auth.PathAttributes("post", r.URL.Path)) {
+		return
+	}
+	var req resumeRequest
+	if err := decodeJSON(r, &req); err != nil {
+		a.writeError(w, http.StatusBadRequest, "invalid resume request: "+err.Error())
+		return
+	}
+	if err := a.checkPolicy(req.Policy); err != nil {
+		a.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	a.pause.Resume(req.Policy, requester(req.By, r))
+	a.writeJSON(w, http.StatusOK, a.pause.Status())
+}
+
+// checkPolicy accepts an empty policy or "*" for every policy, or the configured policy
+func (a *API) checkPolicy(policy string) error {
+	if policy == "" || policy == pause.AllPolicies || policy == a.pm.Config().Watchdog.Policy {
+		return nil
+	}
+	return fmt.Errorf("unknown policy %q", policy)
+}
+
+// resumeTime returns the auto-resume time of a pause request, zero when there is none
+func resumeTime(req pauseRequest, now time.Time) (time.Time, error) {
+	if req.Duration != "" && !req.Until.IsZero() {
+		return time.Time{}, errors.New("set either duration or until, not both")
+	}
+	if req.Duration != "" {
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid duration: %w", err)
+		}
+		if duration <= 0 {
+			return time.Time{}, errors.New("duration must be positive")
+		}
+		return now.Add(duration), nil
+	}
+	if !req.Until.IsZero() && !req.Until.After(now) {
+		return time.Time{}, errors.New("until must be in the future")
+	}
+	return req.Until, nil
+}
+
+// requester returns who made the request: the authenticated user, else the name
+// given in the request, else the client address
+func requester(by string, r *http.Request) string {
+	if identity, ok := auth.IdentityFrom(r.Context()); ok {
+		return identity.Name
+	}
+	if by != "" {
+		return by
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/isdmx/watchdog/internal/auth"
+	"github.com/isdmx/watchdog/internal/monitoring"
+	"github.com/isdmx/watchdog/internal/pause"
+)
+
+func TestPause(t *testing.T) {
+	api, mux := newTestAPI(t, newPod("expired", 2*time.Hour))
+	api.pm.Config().Watchdog.DryRun = false
+
+	do := func(method, url, body string) (int, pause.Status) {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(method, url, strings.NewReader(body)))
+		var status pause.Status
+		if recorder.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+		}
+		return recorder.Code, status
+	}
+
+	code, status := do(http.MethodPost, "/api/v1/pause", `{"by": "alice", "reason": "INC-42", "duration": "30m"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, status.Paused)
+	require.Len(t, status.Pauses, 1)
+	require.Equal(t, pause.SourceAPI, status.Pauses[0].Source)
+	require.Equal(t, pause.AllPolicies, status.Pauses[0].Policy)
+	require.Equal(t, "alice", status.Pauses[0].By)
+	require.WithinDuration(t, time.Now().Add(30*time.Minute), status.Pauses[0].Until, time.Minute)
+
+	// Cycles still evaluate and report while paused
+	report, err := api.pm.Run(context.Background(), monitoring.RunOptions{})
+	require.NoError(t, err)
+	require.Equal(t, map[monitoring.Action]int{monitoring.ActionPaused: 1}, report.Actions)
+	require.Contains(t, report.Paused, "by alice: INC-42")
+
+	code, status = do(http.MethodGet, "/api/v1/pause", "")
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, status.Paused)
+
+	code, status = do(http.MethodPost, "/api/v1/resume", `{"by": "alice"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.False(t, status.Paused)
+	require.Empty(t, status.Pauses)
+
+	report, err = api.pm.Run(context.Background(), monitoring.RunOptions{})
+	require.NoError(t, err)
+	require.Equal(t, map[monitoring.Action]int{monitoring.ActionTerminated: 1}, report.Actions)
+}
+
+func TestPausePolicy(t *testing.T) {
+	_, mux := newTestAPI(t)
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/pause", strings.NewReader(`{"policy": "default"}`)))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var status pause.Status
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+	require.False(t, status.Paused, "a policy pause is not global")
+	require.Equal(t, "default", status.Pauses[0].Policy)
+	require.NotEmpty(t, status.Pauses[0].By, "defaults to the client address")
+}
+
+func TestPauseAuthorization(t *testing.T) {
+	_, mux := newTestAPI(t)
+	post := func(url, body string, allowed ...auth.Attributes) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, asUser(httptest.NewRequest(http.MethodPost, url, strings.NewReader(body)), "alice", allowed...))
+		return recorder
+	}
+
+	require.Equal(t, http.StatusForbidden, post("/api/v1/pause", `{}`).Code)
+	require.Equal(t, http.StatusForbidden, post("/api/v1/resume", `{}`).Code)
+
+	// The authenticated user is recorded, whatever the request says
+	recorder := post("/api/v1/pause", `{"by": "mallory"}`, auth.PathAttributes("post", "/api/v1/pause"))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var status pause.Status
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+	require.Equal(t, "alice", status.Pauses[0].By)
+
+	require.Equal(t, http.StatusOK, post("/api/v1/resume", `{}`, auth.PathAttributes("post", "/api/v1/resume")).Code)
+}
+
+func TestPauseBadRequests(t *testing.T) {
+	_, mux := newTestAPI(t)
+	for _, body := range []string{
+		`{"policy": "other"}`,
+		`{"duration": "soon"}`,
+		`{"duration": "-5m"}`,
+		`{"until": "2001-01-01T00:00:00Z"}`,
+		`{"duration": "5m", "until": "2999-01-01T00:00:00Z"}`,
+		`{"paused": true}`,
+	} {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/pause", strings.NewReader(body)))
+		require.Equal(t, http.StatusBadRequest, recorder.Code, body)
+	}
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/resume", strings.NewReader(`{"policy": "other"}`)))
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestPauseRoutes(t *testing.T) {
+	post := func(mux *http.ServeMux, url string) int {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, asUser(httptest.NewRequest(http.MethodPost, url, strings.NewReader(`{}`)), "alice",
+			auth.PathAttributes("post", url)))
+		return recorder.Code
+	}
+
+	// Not served by default, anyone reaching the port could stop the terminations
+	_, mux := newTestAPIWithConfig(t, newTestConfig())
+	require.Equal(t, http.StatusMethodNotAllowed, post(mux, "/api/v1/pause"))
+	require.Equal(t, http.StatusNotFound, post(mux, "/api/v1/resume"))
+
+	cfg := newTestConfig()
+	cfg.HTTP.Auth.Kubernetes.Enabled = true
+	_, mux = newTestAPIWithConfig(t, cfg)
+	require.Equal(t, http.StatusOK, post(mux, "/api/v1/pause"))
+	require.Equal(t, http.StatusOK, post(mux, "/api/v1/resume"))
+}
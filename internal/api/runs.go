@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/isdmx/watchdog/internal/auth"
+	"github.com/isdmx/watchdog/internal/monitoring"
+	"github.com/isdmx/watchdog/internal/server"
+)
+
+// triggerRun queues a monitoring run, the optional JSON body scopes it with
+// namespaces, policy and a dry-run override
+func (a *API) triggerRun(w http.ResponseWriter, r *http.Request) {
+	if !a.authorize(w, r, auth.PathAttributes("post", r.URL.Path)) {
+		return
+	}
+
+	var opts monitoring.RunOptions
+	if err := decodeJSON(r, &opts); err != nil {
+		a.writeError(w, http.StatusBadRequest, "invalid run request: "+err.Error())
+		return
+	}
+	// Without authentication anyone reaching the port could delete pods
+	// the configuration only reports on
+	if _, ok := auth.IdentityFrom(r.Context()); !ok && opts.DryRun != nil && !*opts.DryRun {
+		a.writeError(w, http.StatusForbidden, "forbidden: turning dry-run off needs authentication")
+		return
+	}
+
+	run, err := a.wd.Trigger(opts)
+	if errors.Is(err, server.ErrRunQueued) {
+		a.writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		a.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/runs/"+run.ID)
+	a.writeJSON(w, http.StatusAccepted, run)
+}
+
+// runsResponse is the body of GET /api/v1/runs
+type runsResponse struct {
+	Runs []server.Run `json:"runs"`
+}
+
+// listRuns reports the recent runs, newest first
+func (a *API) listRuns(w http.ResponseWriter, r *http.Request) {
+	visible := a.visibleNamespaces(r)
+	runs := a.wd.Runs()
+	for i := range runs {
+		runs[i] = filterRun(runs[i], visible)
+	}
+	a.writeJSON(w, http.StatusOK, runsResponse{Runs: runs})
+}
+
+// getRun reports the status and results of a recent run
+func (a *API) getRun(w http.ResponseWriter, r *http.Request) {
+	run, ok := a.wd.Run(r.PathValue("id"))
+	if !ok {
+		a.writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+	a.writeJSON(w, http.StatusOK, filterRun(run, a.visibleNamespaces(r)))
+}
+
+// filterRun drops the results of pods in namespaces the caller may not view, the
+// action counts stay complete
+func filterRun(run server.Run, visible func(namespace string) bool) server.Run {
+	if run.Report == nil {
+		return run
+	}
+	report := *run.Report
+	report.Results = make([]monitoring.Result, 0, len(run.Report.Results))
+	for _, result := range run.Report.Results {
+		if visible(result.Namespace) {
+			report.Results = append(report.Results, result)
+		}
+	}
+	run.Report = &report
+	return run
+}
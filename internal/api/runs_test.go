@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/isdmx/watchdog/internal/auth"
+	"github.com/isdmx/watchdog/internal/monitoring"
+	"github.com/isdmx/watchdog/internal/server"
+)
+
+func TestRuns(t *testing.T) {
+	api, mux := newTestAPI(t, newPod("expired", 2*time.Hour))
+	require.NoError(t, api.wd.Start(context.Background()))
+	t.Cleanup(func() { _ = api.wd.Shutdown(context.Background()) })
+
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(method, url, strings.NewReader(body)))
+		return recorder
+	}
+
+	t.Run("triggers a run and reports it", func(t *testing.T) {
+		recorder := do(http.MethodPost, "/api/v1/runs", `{"namespaces": ["default"], "dryRun": true}`)
+		require.Equal(t, http.StatusAccepted, recorder.Code)
+
+		var run server.Run
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &run))
+		require.NotEmpty(t, run.ID)
+		require.Equal(t, "/api/v1/runs/"+run.ID, recorder.Header().Get("Location"))
+
+		require.Eventually(t, func() bool {
+			recorder := do(http.MethodGet, "/api/v1/runs/"+run.ID, "")
+			require.Equal(t, http.StatusOK, recorder.Code)
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &run))
+			return run.Status == server.RunSucceeded
+		}, 2*time.Second, 10*time.Millisecond)
+		require.Equal(t, map[monitoring.Action]int{monitoring.ActionDryRun: 1}, run.Report.Actions)
+		require.Equal(t, "expired", run.Report.Results[0].Name)
+	})
+
+	t.Run("empty body runs the configured policy", func(t *testing.T) {
+		recorder := do(http.MethodPost, "/api/v1/runs", "")
+		require.Equal(t, http.StatusAccepted, recorder.Code)
+	})
+
+	t.Run("bad requests", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/v1/runs", `{"namespaces": ["kube-system"]}`).Code)
+		require.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/v1/runs", `{"force": true}`).Code)
+		require.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/v1/runs", `not json`).Code)
+	})
+
+	t.Run("lists runs newest first", func(t *testing.T) {
+		recorder := do(http.MethodGet, "/api/v1/runs", "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var response runsResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		require.Len(t, response.Runs, 2)
+		require.Nil(t, response.Runs[0].Options.DryRun, "the empty body run came last")
+	})
+
+	t.Run("unknown run", func(t *testing.T) {
+		require.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/runs/missing", "").Code)
+	})
+}
+
+func TestRunsConflict(t *testing.T) {
+	// The watchdog loop is not started, so the first run stays queued
+	_, mux := newTestAPI(t)
+	for _, want := range []int{http.StatusAccepted, http.StatusConflict} {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/runs", http.NoBody))
+		require.Equal(t, want, recorder.Code)
+	}
+}
+
+func TestRunsAuthorization(t *testing.T) {
+	_, mux := newTestAPI(t)
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, asUser(httptest.NewRequest(http.MethodPost, "/api/v1/runs", http.NoBody), "alice"))
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, asUser(httptest.NewRequest(http.MethodPost, "/api/v1/runs", http.NoBody), "alice", auth.PathAttributes("post", "/api/v1/runs")))
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+}
+
+func TestRunsDryRunOff(t *testing.T) {
+	api, mux := newTestAPI(t, newPod("expired", 2*time.Hour))
+	require.NoError(t, api.wd.Start(context.Background()))
+	t.Cleanup(func() { _ = api.wd.Shutdown(context.Background()) })
+
+	// The configured dry run cannot be turned off without authentication
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/runs", strings.NewReader(`{"dryRun": false}`)))
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	require.Empty(t, api.wd.Runs())
+	report, err := api.pm.Run(context.Background(), monitoring.RunOptions{})
+	require.NoError(t, err)
+	require.Equal(t, map[monitoring.Action]int{monitoring.ActionDryRun: 1}, report.Actions, "the pod is still there")
+
+	recorder = httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/runs", strings.NewReader(`{"dryRun": false}`))
+	mux.ServeHTTP(recorder, asUser(request, "alice", auth.PathAttributes("post", "/api/v1/runs")))
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+}
+
+func TestFilterRun(t *testing.T) {
+	report := &monitoring.CycleReport{
+		Actions: map[monitoring.Action]int{monitoring.ActionTerminated: 2},
+		Results: []monitoring.Result{
+			{Namespace: "default", Name: "a", Action: monitoring.ActionTerminated},
+			{Namespace: "kube-system", Name: "b", Action: monitoring.ActionTerminated},
+		},
+	}
+	run := filterRun(server.Run{ID: "1", Report: report}, func(namespace string) bool { return namespace == "default" })
+
+	require.Equal(t, []monitoring.Result{{Namespace: "default", Name: "a", Action: monitoring.ActionTerminated}}, run.Report.Results)
+	require.Equal(t, 2, run.Report.Actions[monitoring.ActionTerminated], "counts stay complete")
+	require.Len(t, report.Results, 2, "the stored run is not modified")
+	require.Nil(t, filterRun(server.Run{ID: "2"}, nil).Report)
+}
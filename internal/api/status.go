@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+	"github.com/isdmx/watchdog/internal/pause"
+)
+
+// statusResponse is the body of GET /api/v1/status
+type statusResponse struct {
+	Policy           string            `json:"policy"`
+	Namespaces       []string          `json:"namespaces"`
+	OwnedNamespaces  []string          `json:"ownedNamespaces"`
+	LabelSelectors   map[string]string `json:"labelSelectors"`
+	DryRun           bool              `json:"dryRun"`
+	ScheduleInterval string            `json:"scheduleInterval"`
+	MaxPodLifetime   string            `json:"maxPodLifetime"`
+	TTLLabel         string            `json:"ttlLabel,omitempty"`
+	Extensions       extensionsStatus  `json:"extensions"`
+	Pause            pause.Status      `json:"pause"`
+}
+
+// extensionsStatus describes the limits of pod extensions
+type extensionsStatus struct {
+	Enabled       bool   `json:"enabled"`
+	MaxLifetime   string `json:"maxLifetime"`
+	MaxExtensions int    `json:"maxExtensions"`
+}
+
+// status reports the policy, the watched namespaces and what can be changed through
+// the API, with the secrets of the configuration scrubbed
+func (a *API) status(w http.ResponseWriter, _ *http.Request) {
+	full := a.pm.Config()
+	cfg := full.Watchdog
+
+	var labelSelectors map[string]string
+	if cfg.LabelSelectors != nil {
+		labelSelectors = make(map[string]string, len(cfg.LabelSelectors))
+		for key, value := range cfg.LabelSelectors {
+			labelSelectors[key] = full.Redact(value)
+		}
+	}
+	a.writeJSON(w, http.StatusOK, statusResponse{
+		Policy:           full.Redact(cfg.Policy),
+		Namespaces:       redactAll(full, cfg.Namespaces),
+		OwnedNamespaces:  redactAll(full, a.pm.OwnedNamespaces()),
+		LabelSelectors:   labelSelectors,
+		DryRun:           cfg.DryRun,
+		ScheduleInterval: cfg.ScheduleInterval.String(),
+		MaxPodLifetime:   cfg.MaxPodLifetime.String(),
+		TTLLabel:         full.Redact(cfg.TtlLabel),
+		Extensions: extensionsStatus{
+			Enabled:       cfg.Extensions.Enabled && cfg.TtlLabel != "",
+			MaxLifetime:   monitoring.MaxExtendedLifetime(&cfg).String(),
+			MaxExtensions: cfg.Extensions.MaxExtensions,
+		},
+		Pause: a.pause.Status(),
+	})
+}
+
+// redactAll scrubs the secrets of the configuration from every text
+func redactAll(cfg *config.Config, texts []string) []string {
+	if texts == nil {
+		return nil
+	}
+	redacted := make([]string, len(texts))
+	for i, text := range texts {
+		redacted[i] = cfg.Redact(text)
+	}
+	return redacted
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/isdmx/watchdog/internal/pause"
+)
+
+func TestStatus(t *testing.T) {
+	api, mux := newTestAPI(t)
+	api.pause.Pause(pause.Pause{By: "alice"})
+	t.Cleanup(func() { api.pause.Resume("", "alice") })
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/status", http.NoBody))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var status statusResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+	require.Equal(t, "default", status.Policy)
+	require.Equal(t, []string{"default"}, status.Namespaces)
+	require.Equal(t, []string{"default"}, status.OwnedNamespaces)
+	require.True(t, status.DryRun)
+	require.Equal(t, "1h0m0s", status.ScheduleInterval)
+	require.Equal(t, extensionsStatus{MaxLifetime: "1h0m0s"}, status.Extensions)
+	require.True(t, status.Pause.Paused)
+	require.Equal(t, "alice", status.Pause.Pauses[0].By)
+}
@@ -1,51 +1,234 @@
 package app
 
 import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxevent"
 	"go.uber.org/zap"
 
+	"github.com/isdmx/watchdog/internal/api"
+	"github.com/isdmx/watchdog/internal/audit"
+	"github.com/isdmx/watchdog/internal/auth"
 	"github.com/isdmx/watchdog/internal/client"
 	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/dashboard"
+	"github.com/isdmx/watchdog/internal/events"
+	"github.com/isdmx/watchdog/internal/health"
+	"github.com/isdmx/watchdog/internal/logarchive"
 	"github.com/isdmx/watchdog/internal/logging"
+	"github.com/isdmx/watchdog/internal/metrics"
 	"github.com/isdmx/watchdog/internal/monitoring"
+	"github.com/isdmx/watchdog/internal/notify"
+	"github.com/isdmx/watchdog/internal/pause"
+	"github.com/isdmx/watchdog/internal/reload"
 	"github.com/isdmx/watchdog/internal/server"
+	"github.com/isdmx/watchdog/internal/sharding"
+	"github.com/isdmx/watchdog/internal/snapshot"
+	"github.com/isdmx/watchdog/internal/telemetry"
 )
 
-func NewApplication() *fx.App {
+func NewApplication(src config.Source) *fx.App {
 	return fx.New(
-		// Configuration module
-		fx.Provide(config.NewConfig),
+		monitor(src),
 
-		// Logging module
-		fx.Provide(logging.NewLogger),
-		fx.Provide(logging.NewSugaredLogger),
+		// Pause switch details, shown on /readyz
+		fx.Provide(fx.Annotate(
+			func(s *pause.Switch) *pause.Switch { return s },
+			fx.ResultTags(`group:"details"`),
+			fx.As(new(server.Detail)),
+		)),
 
-		// Kubernetes client module
-		fx.Provide(client.NewKubernetesClient),
+		// HTTP API
+		fx.Provide(fx.Annotate(
+			api.NewAPI,
+			fx.ResultTags(`group:"routes"`),
+			fx.As(new(server.Routes)),
+		)),
 
-		// Monitoring module
-		fx.Provide(monitoring.NewPodMonitor),
+		// Web dashboard
+		fx.Provide(fx.Annotate(
+			dashboard.NewDashboard,
+			fx.ResultTags(`group:"routes"`),
+			fx.As(new(server.Routes)),
+		)),
+
+		// Authentication of the HTTP server
+		fx.Provide(fx.Annotate(
+			auth.NewAuth,
+			fx.ResultTags(`group:"middlewares"`),
+			fx.As(new(server.Middleware)),
+		)),
+
+		// Health checks of the components, run by the probes
+		fx.Provide(fx.Annotate(
+			client.NewAPIServerCheck,
+			fx.ResultTags(`group:"checks"`),
+			fx.As(new(health.Checker)),
+		)),
+		fx.Provide(fx.Annotate(
+			func(s *pause.Switch) *pause.Switch { return s },
+			fx.ResultTags(`group:"checks"`),
+			fx.As(new(health.Checker)),
+		)),
+		fx.Provide(fx.Annotate(
+			func(m *sharding.Membership) *sharding.Membership { return m },
+			fx.ResultTags(`group:"checks"`),
+			fx.As(new(health.Checker)),
+		)),
+		fx.Provide(fx.Annotate(
+			func(wd *server.WatchdogServer) *server.WatchdogServer { return wd },
+			fx.ResultTags(`group:"checks"`),
+			fx.As(new(health.Checker)),
+		)),
+		fx.Provide(fx.Annotate(
+			health.NewRegistry,
+			fx.ParamTags(``, ``, `group:"checks"`),
+		)),
 
 		// HTTP server
 		fx.Provide(fx.Annotate(
 			server.NewHTTPServer,
+			fx.ParamTags(``, ``, ``, ``, ``, `group:"details"`, `group:"middlewares"`, `group:"routes"`),
 			fx.ResultTags(`group:"servers"`),
 			fx.As(new(server.Server)),
 		)),
 
-		// Watchdog server
+		// Watchdog server, also used by the API to trigger runs
+		fx.Provide(server.NewWatchdogServer),
 		fx.Provide(fx.Annotate(
-			server.NewWatchdogServer,
+			func(wd *server.WatchdogServer) *server.WatchdogServer { return wd },
 			fx.ResultTags(`group:"servers"`),
 			fx.As(new(server.Server)),
 		)),
 
-		// Start the application (all servers)
+		// Config reloads, swapped into the monitor, the watchdog server, the logger
+		// and the notifier
+		fx.Provide(fx.Annotate(
+			func(pm *monitoring.PodMonitor) *monitoring.PodMonitor { return pm },
+			fx.ResultTags(`group:"reloadables"`),
+			fx.As(new(reload.Reloadable)),
+		)),
+		fx.Provide(fx.Annotate(
+			func(wd *server.WatchdogServer) *server.WatchdogServer { return wd },
+			fx.ResultTags(`group:"reloadables"`),
+			fx.As(new(reload.Reloadable)),
+		)),
+		fx.Provide(fx.Annotate(
+			func(l *logging.Level) *logging.Level { return l },
+			fx.ResultTags(`group:"reloadables"`),
+			fx.As(new(reload.Reloadable)),
+		)),
+		fx.Provide(fx.Annotate(
+			func(r *logging.Redactor) *logging.Redactor { return r },
+			fx.ResultTags(`group:"reloadables"`),
+			fx.As(new(reload.Reloadable)),
+		)),
+		fx.Provide(fx.Annotate(
+			func(n *notify.Notifier) *notify.Notifier { return n },
+			fx.ResultTags(`group:"reloadables"`),
+			fx.As(new(reload.Reloadable)),
+		)),
+		fx.Provide(fx.Annotate(
+			reload.NewWatcher,
+			fx.ParamTags(``, ``, ``, ``, `group:"reloadables"`),
+		)),
+
+		// Start the application (all servers) and the config watcher
 		fx.Invoke(fx.Annotate(
-			func([]server.Server) {},
+			func([]server.Server, *reload.Watcher) {},
 			fx.ParamTags(`group:"servers"`),
 		)),
+	)
+}
+
+// NewCycle creates an application that only wires the monitoring cycle, with
+// its observers, preservers and pause switch, for the once command. The targets
+// are populated from the container, as with fx.Populate.
+func NewCycle(src config.Source, targets ...any) *fx.App {
+	return fx.New(
+		monitor(src),
+		fx.Populate(targets...),
+	)
+}
+
+// monitor provides the configuration, the logger, the metrics and the pod
+// monitor with everything its cycles use
+func monitor(src config.Source) fx.Option {
+	return fx.Options(
+		// Configuration module, loaded from the file, the environment and the flags
+		fx.Supply(src),
+		fx.Provide(config.NewConfig),
+
+		// Logging module, the level and the secrets scrubbed follow config reloads
+		fx.Provide(logging.NewLevel),
+		fx.Provide(logging.NewRedactor),
+		fx.Provide(logging.NewLogger),
+		fx.Provide(logging.NewSugaredLogger),
+
+		// Metrics registry, every component registers its metrics with it
+		fx.Provide(metrics.NewRegistry),
+		fx.Provide(fx.Annotate(
+			func(r *prometheus.Registry) *prometheus.Registry { return r },
+			fx.As(new(prometheus.Registerer)),
+		)),
+
+		// OpenTelemetry export of the cycle traces and the registry's metrics
+		fx.Provide(telemetry.NewTelemetry),
+		fx.Provide(func(t *telemetry.Telemetry) trace.TracerProvider { return t.TracerProvider() }),
+
+		// Kubernetes client module
+		fx.Provide(client.NewKubernetesClient),
+
+		// Sharding module
+		fx.Provide(sharding.NewMembership),
+		fx.Provide(fx.Annotate(
+			func(m *sharding.Membership) *sharding.Membership { return m },
+			fx.As(new(monitoring.Sharder)),
+		)),
+
+		// Pause switch, also used by the API
+		fx.Provide(pause.NewSwitch),
+		fx.Provide(fx.Annotate(
+			func(s *pause.Switch) *pause.Switch { return s },
+			fx.As(new(monitoring.Pauser)),
+		)),
+
+		// Pre-termination preservers
+		fx.Provide(fx.Annotate(
+			snapshot.NewSnapshotter,
+			fx.ResultTags(`group:"preservers"`),
+			fx.As(new(monitoring.Preserver)),
+		)),
+		fx.Provide(fx.Annotate(
+			logarchive.NewArchiver,
+			fx.ResultTags(`group:"preservers"`),
+			fx.As(new(monitoring.Preserver)),
+		)),
+
+		// Decision observers
+		fx.Provide(fx.Annotate(
+			events.NewRecorder,
+			fx.ResultTags(`group:"observers"`),
+			fx.As(new(monitoring.Observer)),
+		)),
+		fx.Provide(notify.NewNotifier),
+		fx.Provide(fx.Annotate(
+			func(n *notify.Notifier) *notify.Notifier { return n },
+			fx.ResultTags(`group:"observers"`),
+			fx.As(new(monitoring.Observer)),
+		)),
+		fx.Provide(fx.Annotate(
+			audit.NewSink,
+			fx.ResultTags(`group:"observers"`),
+			fx.As(new(monitoring.Observer)),
+		)),
+
+		// Monitoring module
+		fx.Provide(fx.Annotate(
+			monitoring.NewPodMonitor,
+			fx.ParamTags(``, ``, ``, ``, ``, ``, ``, `group:"preservers"`, `group:"observers"`),
+		)),
 
 		fx.WithLogger(func(log *zap.Logger) fxevent.Logger {
 			return &fxevent.ZapLogger{Logger: log.Named("fx")}
@@ -4,11 +4,13 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/isdmx/watchdog/internal/config"
 )
 
 func TestNewApplication(t *testing.T) {
 	t.Run("creates application successfully", func(t *testing.T) {
-		app := NewApplication()
+		app := NewApplication(config.Source{})
 		require.NotNil(t, app)
 
 		// Test that the app can be started (though we won't actually run it in tests)
@@ -16,7 +18,7 @@ func TestNewApplication(t *testing.T) {
 	})
 
 	t.Run("application has required modules", func(t *testing.T) {
-		app := NewApplication()
+		app := NewApplication(config.Source{})
 		require.NotNil(t, app)
 
 		// The app should have all the required modules as providers
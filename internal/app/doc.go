@@ -10,6 +10,10 @@
 // each major functionality (config, logging, client, monitoring, server) is handled
 // by its own package and injected where needed.
 //
+// NewCycle shares the configuration, logging, metrics and monitoring wiring with
+// NewApplication but leaves out the servers, for the once command to run a
+// single cycle.
+//
 // Key features of this package:
 //   - Centralized dependency injection using Uber fx
 //   - Modular architecture with clear separation of concerns
@@ -0,0 +1,14 @@
+// Package audit keeps a durable audit log of watchdog decisions.
+//
+// Production logs rotate away long before compliance asks who deleted a pod
+// and why. The Sink observes every decision made by the monitoring package and
+// appends it as a single JSON line to the standard output or to a local file
+// that is rotated by size. Each record carries the timestamp, monitoring cycle
+// id, policy, pod UID, name, namespace and owner, the expiry reason, the action
+// taken, the dry-run flag and the result of the API call.
+//
+// With the hash chain enabled every record holds the SHA-256 of its own
+// content and of the previous record, so editing, removing or reordering lines
+// is detectable. The chain continues across rotations and restarts. The
+// Verifier checks a chain, and is exposed as `watchdog audit verify`.
+package audit
@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+
+	f, err := openRotatingFile(path, 10, 2)
+	require.NoError(t, err)
+	for _, line := range []string{"aaaaaa\n", "bbbbbb\n", "cccccc\n", "dddddd\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Close())
+
+	read := func(name string) string {
+		data, err := os.ReadFile(name)
+		require.NoError(t, err)
+		return string(data)
+	}
+	require.Equal(t, "dddddd\n", read(path))
+	require.Equal(t, "cccccc\n", read(path+".1"))
+	require.Equal(t, "bbbbbb\n", read(path+".2"))
+	require.NoFileExists(t, path+".3")
+
+	t.Run("appends to an existing file", func(t *testing.T) {
+		f, err := openRotatingFile(path, 100, 2)
+		require.NoError(t, err)
+		_, err = f.Write([]byte("eeeeee\n"))
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		require.Equal(t, "dddddd\neeeeee\n", read(path))
+	})
+
+	t.Run("without backups", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		f, err := openRotatingFile(path, 10, 0)
+		require.NoError(t, err)
+		for _, line := range []string{"aaaaaa\n", "bbbbbb\n"} {
+			_, err := f.Write([]byte(line))
+			require.NoError(t, err)
+		}
+		require.NoError(t, f.Close())
+		require.Equal(t, "bbbbbb\n", read(path))
+		require.NoFileExists(t, path+".1")
+	})
+}
+
+func TestLastRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	record, err := lastRecord(filepath.Join(dir, "missing.jsonl"))
+	require.NoError(t, err)
+	require.Nil(t, record)
+
+	path := filepath.Join(dir, "audit.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"seq":1,"hash":"a"}`+"\n"+`{"seq":2,"hash":"b"}`+"\n\n"), 0o600))
+	record, err = lastRecord(path)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), record.Seq)
+	require.Equal(t, "b", record.Hash)
+}
@@ -0,0 +1,31 @@
+package audit
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	resultWritten = "written"
+	resultFailed  = "failed"
+)
+
+// metrics are the metrics of the audit sink
+type metrics struct {
+	// recordsTotal counts audit records by write result
+	recordsTotal *prometheus.CounterVec
+}
+
+// newMetrics registers the audit metrics, a nil registerer leaves them unregistered
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	factory := promauto.With(registerer)
+	return &metrics{
+		recordsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "watchdog_audit_records_total",
+				Help: "Total number of audit records by result (written, failed)",
+			},
+			[]string{"result"},
+		),
+	}
+}
@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/isdmx/watchdog/internal/client"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+const (
+	resultSuccess = "success"
+	resultFailure = "failure"
+)
+
+// Record is a single line of the audit log
+type Record struct {
+	Seq        uint64    `json:"seq"`
+	Time       time.Time `json:"time"`
+	CycleID    string    `json:"cycleId"`
+	Policy     string    `json:"policy"`
+	Pod        Pod       `json:"pod"`
+	Action     string    `json:"action"`
+	Reason     string    `json:"reason,omitempty"`
+	Deadline   time.Time `json:"deadline,omitzero"`
+	DryRun     bool      `json:"dryRun"`
+	Result     string    `json:"result"`
+	ErrorClass string    `json:"errorClass,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	PrevHash   string    `json:"prevHash,omitempty"`
+	Hash       string    `json:"hash,omitempty"`
+}
+
+// Pod identifies the pod a record is about
+type Pod struct {
+	UID       string `json:"uid"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Owner     string `json:"owner,omitempty"`
+}
+
+// NewRecord creates the audit record of a decision, without sequence number or hashes
+func NewRecord(decision monitoring.Decision, now time.Time) Record {
+	pod := decision.Pod
+	record := Record{
+		Time:    now.UTC(),
+		CycleID: decision.CycleID,
+		Policy:  decision.Policy,
+		Pod: Pod{
+			UID:       string(pod.UID),
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+		Action: string(decision.Action),
+		Reason: decision.Verdict.Reason,
+		DryRun: decision.DryRun,
+		Result: resultSuccess,
+	}
+	if !decision.Verdict.Deadline.IsZero() {
+		record.Deadline = decision.Verdict.Deadline.UTC()
+	}
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		record.Pod.Owner = owner.Kind + "/" + owner.Name
+	}
+	if decision.Err != nil {
+		record.Result = resultFailure
+		record.Error = decision.Err.Error()
+		if decision.Action == monitoring.ActionFailed {
+			record.ErrorClass = string(client.Classify(decision.Err))
+		}
+	}
+	return record
+}
+
+// ComputeHash returns the SHA-256 of the record's JSON encoding without its own hash,
+// so the hash covers the previous record's hash as well
+func ComputeHash(record Record) (string, error) {
+	record.Hash = ""
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+func newTestDecision(action monitoring.Action) monitoring.Decision {
+	controller := true
+	return monitoring.Decision{
+		CycleID: "cycle-1",
+		Policy:  "default",
+		Pod: &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "sandbox-1",
+				Namespace: "default",
+				UID:       "uid-1",
+				OwnerReferences: []metav1.OwnerReference{{
+					Kind:       "Job",
+					Name:       "sandbox",
+					Controller: &controller,
+				}},
+			},
+		},
+		Action: action,
+		Verdict: monitoring.Verdict{
+			Expired:  true,
+			Reason:   monitoring.ReasonTTLLabel,
+			Deadline: time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+func TestNewRecord(t *testing.T) {
+	now := time.Date(2026, 1, 2, 4, 0, 0, 0, time.UTC)
+
+	t.Run("terminated", func(t *testing.T) {
+		record := NewRecord(newTestDecision(monitoring.ActionTerminated), now)
+		require.Equal(t, Record{
+			Time:     now,
+			CycleID:  "cycle-1",
+			Policy:   "default",
+			Pod:      Pod{UID: "uid-1", Name: "sandbox-1", Namespace: "default", Owner: "Job/sandbox"},
+			Action:   "terminated",
+			Reason:   monitoring.ReasonTTLLabel,
+			Deadline: time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC),
+			Result:   resultSuccess,
+		}, record)
+	})
+
+	t.Run("failed deletion records the error class", func(t *testing.T) {
+		decision := newTestDecision(monitoring.ActionFailed)
+		decision.Err = apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "sandbox-1", errors.New("denied"))
+
+		record := NewRecord(decision, now)
+		require.Equal(t, resultFailure, record.Result)
+		require.Equal(t, "forbidden", record.ErrorClass)
+		require.NotEmpty(t, record.Error)
+	})
+
+	t.Run("invalid TTL has no deadline", func(t *testing.T) {
+		decision := newTestDecision(monitoring.ActionInvalid)
+		decision.Verdict = monitoring.Verdict{}
+		decision.Err = errors.New("bad label")
+
+		record := NewRecord(decision, now)
+		require.True(t, record.Deadline.IsZero())
+		require.Equal(t, resultFailure, record.Result)
+		require.Empty(t, record.ErrorClass)
+		require.Equal(t, "bad label", record.Error)
+	})
+}
+
+func TestComputeHash(t *testing.T) {
+	record := NewRecord(newTestDecision(monitoring.ActionTerminated), time.Now())
+
+	hash, err := ComputeHash(record)
+	require.NoError(t, err)
+	require.Len(t, hash, 64)
+
+	record.Hash = hash
+	again, err := ComputeHash(record)
+	require.NoError(t, err)
+	require.Equal(t, hash, again, "the record's own hash is not part of the hash")
+
+	record.PrevHash = "abc"
+	chained, err := ComputeHash(record)
+	require.NoError(t, err)
+	require.NotEqual(t, hash, chained)
+}
@@ -0,0 +1,166 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+const (
+	// OutputStdout writes the audit log to the standard output
+	OutputStdout = "stdout"
+	// OutputFile writes the audit log to a rotating local file
+	OutputFile = "file"
+)
+
+var _ monitoring.Observer = (*Sink)(nil)
+
+// Sink writes one JSON line per watchdog decision to the audit log
+type Sink struct {
+	config  config.AuditConfig
+	logger  *zap.SugaredLogger
+	metrics *metrics
+
+	mu       sync.Mutex
+	writer   io.Writer
+	closer   io.Closer
+	seq      uint64
+	prevHash string
+}
+
+// NewSink creates a new audit sink
+func NewSink(lc fx.Lifecycle, cfg *config.Config, logger *zap.SugaredLogger, registerer prometheus.Registerer) (*Sink, error) {
+	switch cfg.Audit.Output {
+	case OutputStdout, OutputFile:
+	default:
+		return nil, fmt.Errorf("unknown audit output %q, expected %q or %q", cfg.Audit.Output, OutputStdout, OutputFile)
+	}
+
+	s := &Sink{
+		config:  cfg.Audit,
+		logger:  logger.Named("AuditSink"),
+		metrics: newMetrics(registerer),
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: s.Start,
+		OnStop:  s.Shutdown,
+	})
+
+	return s, nil
+}
+
+// Start opens the audit log and resumes the hash chain from its last record
+func (s *Sink) Start(_ context.Context) error {
+	if !s.config.Enabled {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.config.Output == OutputStdout {
+		s.writer = os.Stdout
+		s.logger.Infow("Writing audit log", "output", OutputStdout, "hashChain", s.config.HashChain)
+		return nil
+	}
+
+	if err := s.resume(); err != nil {
+		return fmt.Errorf("failed to read audit log %s: %w", s.config.Path, err)
+	}
+	file, err := openRotatingFile(s.config.Path, int64(s.config.MaxSizeMB)*1024*1024, s.config.MaxBackups)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log %s: %w", s.config.Path, err)
+	}
+	s.writer = file
+	s.closer = file
+
+	s.logger.Infow("Writing audit log", "output", OutputFile, "path", s.config.Path,
+		"hashChain", s.config.HashChain, "seq", s.seq)
+	return nil
+}
+
+// Shutdown closes the audit log
+func (s *Sink) Shutdown(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.writer = nil
+	if s.closer == nil {
+		return nil
+	}
+	err := s.closer.Close()
+	s.closer = nil
+	return err
+}
+
+// Observe appends the decision to the audit log
+func (s *Sink) Observe(_ context.Context, decision monitoring.Decision) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.writer == nil {
+		return
+	}
+	if err := s.append(NewRecord(decision, time.Now())); err != nil {
+		s.logger.Errorw("Failed to write audit record",
+			"namespace", decision.Pod.Namespace, "pod", decision.Pod.Name, "action", decision.Action, "error", err)
+		s.metrics.recordsTotal.WithLabelValues(resultFailed).Inc()
+		return
+	}
+	s.metrics.recordsTotal.WithLabelValues(resultWritten).Inc()
+}
+
+// append numbers and chains the record and writes it as a single line. The sequence
+// and chain only advance once the line is written.
+func (s *Sink) append(record Record) error {
+	record.Seq = s.seq + 1
+	if s.config.HashChain {
+		record.PrevHash = s.prevHash
+		hash, err := ComputeHash(record)
+		if err != nil {
+			return err
+		}
+		record.Hash = hash
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := s.writer.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	s.seq = record.Seq
+	s.prevHash = record.Hash
+	return nil
+}
+
+// resume continues the sequence and hash chain of an existing audit log, falling
+// back to the newest backup when the log was just rotated
+func (s *Sink) resume() error {
+	for _, path := range []string{s.config.Path, s.config.Path + ".1"} {
+		record, err := lastRecord(path)
+		if err != nil {
+			return err
+		}
+		if record != nil {
+			s.seq = record.Seq
+			s.prevHash = record.Hash
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+func newTestSink(t *testing.T, auditConfig config.AuditConfig) *Sink {
+	t.Helper()
+	sink, err := NewSink(fxtest.NewLifecycle(t), &config.Config{Audit: auditConfig}, zap.NewNop().Sugar(), nil)
+	require.NoError(t, err)
+	return sink
+}
+
+func TestNewSink(t *testing.T) {
+	_, err := NewSink(fxtest.NewLifecycle(t), &config.Config{Audit: config.AuditConfig{Output: "syslog"}}, zap.NewNop().Sugar(), nil)
+	require.Error(t, err)
+}
+
+func TestSinkDisabled(t *testing.T) {
+	sink := newTestSink(t, config.AuditConfig{Output: OutputFile, Path: filepath.Join(t.TempDir(), "audit.jsonl")})
+	require.NoError(t, sink.Start(context.Background()))
+
+	sink.Observe(context.Background(), newTestDecision(monitoring.ActionTerminated))
+	require.NoError(t, sink.Shutdown(context.Background()))
+	require.NoFileExists(t, sink.config.Path)
+}
+
+func TestSinkWritesChainedRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditConfig := config.AuditConfig{Enabled: true, Output: OutputFile, Path: path, MaxSizeMB: 1, MaxBackups: 1, HashChain: true}
+
+	sink := newTestSink(t, auditConfig)
+	require.NoError(t, sink.Start(context.Background()))
+	sink.Observe(context.Background(), newTestDecision(monitoring.ActionWarned))
+	sink.Observe(context.Background(), newTestDecision(monitoring.ActionTerminated))
+	require.NoError(t, sink.Shutdown(context.Background()))
+
+	// A restarted sink continues the chain
+	sink = newTestSink(t, auditConfig)
+	require.NoError(t, sink.Start(context.Background()))
+	sink.Observe(context.Background(), newTestDecision(monitoring.ActionTerminated))
+	require.NoError(t, sink.Shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+
+	var first Record
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.Equal(t, uint64(1), first.Seq)
+	require.Equal(t, "cycle-1", first.CycleID)
+	require.Equal(t, "warned", first.Action)
+	require.Empty(t, first.PrevHash)
+	require.NotEmpty(t, first.Hash)
+
+	verifier := &Verifier{}
+	require.NoError(t, verifier.Verify(bytes.NewReader(data)))
+	require.Equal(t, 3, verifier.Records())
+}
+
+func TestSinkWithoutHashChain(t *testing.T) {
+	var buf bytes.Buffer
+	sink := newTestSink(t, config.AuditConfig{Enabled: true, Output: OutputStdout})
+	sink.writer = &buf
+
+	sink.Observe(context.Background(), newTestDecision(monitoring.ActionDryRun))
+
+	var record Record
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Equal(t, uint64(1), record.Seq)
+	require.True(t, strings.HasSuffix(buf.String(), "}\n"))
+	require.Empty(t, record.Hash)
+	require.Empty(t, record.PrevHash)
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestSinkWriteFailure(t *testing.T) {
+	sink := newTestSink(t, config.AuditConfig{Enabled: true, Output: OutputStdout, HashChain: true})
+	sink.writer = failingWriter{}
+
+	sink.Observe(context.Background(), newTestDecision(monitoring.ActionTerminated))
+	require.InDelta(t, 1, testutil.ToFloat64(sink.metrics.recordsTotal.WithLabelValues(resultFailed)), 0)
+	require.Zero(t, sink.seq, "a failed write does not advance the chain")
+	require.Empty(t, sink.prevHash)
+}
@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+// writeChain returns an audit log with the given number of chained records
+func writeChain(t *testing.T, records int) []string {
+	t.Helper()
+	var buf bytes.Buffer
+	sink := newTestSink(t, config.AuditConfig{Enabled: true, Output: OutputStdout, HashChain: true})
+	sink.writer = &buf
+	for range records {
+		sink.Observe(context.Background(), newTestDecision(monitoring.ActionTerminated))
+	}
+	return strings.SplitAfter(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
+
+func TestVerifier(t *testing.T) {
+	verify := func(lines ...string) (*Verifier, error) {
+		verifier := &Verifier{}
+		return verifier, verifier.Verify(strings.NewReader(strings.Join(lines, "")))
+	}
+
+	t.Run("valid chain", func(t *testing.T) {
+		lines := writeChain(t, 3)
+		verifier, err := verify(lines...)
+		require.NoError(t, err)
+		require.Equal(t, 3, verifier.Records())
+		require.NotEmpty(t, verifier.LastHash())
+	})
+
+	t.Run("chain continues across files", func(t *testing.T) {
+		lines := writeChain(t, 4)
+		verifier := &Verifier{}
+		require.NoError(t, verifier.Verify(strings.NewReader(strings.Join(lines[:2], ""))))
+		require.NoError(t, verifier.Verify(strings.NewReader(strings.Join(lines[2:], ""))))
+		require.Equal(t, 4, verifier.Records())
+
+		verifier = &Verifier{}
+		require.NoError(t, verifier.Verify(strings.NewReader(lines[0])))
+		require.Error(t, verifier.Verify(strings.NewReader(lines[2])), "a missing file breaks the chain")
+	})
+
+	t.Run("detects tampering", func(t *testing.T) {
+		lines := writeChain(t, 3)
+		tests := map[string][]string{
+			"edited":    {lines[0], strings.Replace(lines[1], `"terminated"`, `"dry_run"`, 1), lines[2]},
+			"removed":   {lines[0], lines[2]},
+			"reordered": {lines[0], lines[2], lines[1]},
+			"added":     {lines[0], strings.Replace(lines[1], `{"seq"`, `{"note":"x","seq"`, 1), lines[2]},
+			"malformed": {lines[0], "not json\n"},
+		}
+		for name, tampered := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := verify(tampered...)
+				var verifyErr *VerifyError
+				require.ErrorAs(t, err, &verifyErr)
+				require.Equal(t, 2, verifyErr.Line)
+			})
+		}
+	})
+
+	t.Run("rejects records without hash", func(t *testing.T) {
+		var buf bytes.Buffer
+		sink := newTestSink(t, config.AuditConfig{Enabled: true, Output: OutputStdout})
+		sink.writer = &buf
+		sink.Observe(context.Background(), newTestDecision(monitoring.ActionTerminated))
+
+		_, err := verify(buf.String())
+		require.ErrorContains(t, err, "not hash chained")
+	})
+}
@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/server"
+)
+
+var _ server.Middleware = (*Auth)(nil)
+
+// Identity is the authenticated caller of a request
+type Identity struct {
+	Name   string   `json:"name"`
+	UID    string   `json:"uid,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+	// Static identities come from the token files and may call every endpoint
+	Static bool `json:"static,omitempty"`
+}
+
+// Attributes describe an action to authorize, either on the pods of a namespace or
+// on a path of the watchdog when Path is set
+type Attributes struct {
+	Verb      string
+	Namespace string
+	Path      string
+}
+
+// PodAttributes describes an action on the pods of a namespace
+func PodAttributes(verb, namespace string) Attributes {
+	return Attributes{Verb: verb, Namespace: namespace}
+}
+
+// PathAttributes describes an action on a path of the watchdog
+func PathAttributes(verb, path string) Attributes {
+	return Attributes{Verb: verb, Path: path}
+}
+
+// String describes the action for error messages
+func (a Attributes) String() string {
+	if a.Path != "" {
+		return a.Verb + " " + a.Path
+	}
+	return a.Verb + " pods in namespace " + a.Namespace
+}
+
+// Authenticator resolves bearer tokens to identities
+type Authenticator interface {
+	// Authenticate returns the identity of the token, ok is false for unknown tokens
+	Authenticate(ctx context.Context, token string) (identity Identity, ok bool, err error)
+}
+
+// Authorizer decides whether an identity may perform an action
+type Authorizer interface {
+	Authorize(ctx context.Context, identity Identity, attrs Attributes) (bool, error)
+}
+
+// Auth authenticates the requests of the HTTP server. Handlers authorize the actions
+// of the caller with Allowed.
+type Auth struct {
+	authenticators []Authenticator
+	authorizer     Authorizer
+	config         config.AuthConfig
+	logger         *zap.SugaredLogger
+	metrics        *metrics
+}
+
+// NewAuth creates the authenticators and the authorizer configured under http.auth
+func NewAuth(
+	clientset kubernetes.Interface,
+	cfg *config.Config,
+	logger *zap.SugaredLogger,
+	registerer prometheus.Registerer,
+) (*Auth, error) {
+	a := &Auth{
+		config:  cfg.HTTP.Auth,
+		logger:  logger.Named("Auth"),
+		metrics: newMetrics(registerer),
+	}
+
+	if len(a.config.Tokens) > 0 {
+		tokens, err := LoadTokens(a.config.Tokens)
+		if err != nil {
+			return nil, err
+		}
+		a.authenticators = append(a.authenticators, tokens)
+	}
+	if a.config.Kubernetes.Enabled {
+		reviewer := NewReviewer(clientset, a.config.Kubernetes)
+		a.authenticators = append(a.authenticators, reviewer)
+		a.authorizer = reviewer
+	}
+
+	if a.Enabled() {
+		a.logger.Infow("Authentication enabled", "tokens", len(a.config.Tokens), "kubernetes", a.config.Kubernetes.Enabled,
+			"protectProbes", a.config.ProtectProbes, "protectMetrics", a.config.ProtectMetrics)
+	} else {
+		a.logger.Warn("Authentication disabled, anyone reaching the HTTP server can use the API")
+	}
+	return a, nil
+}
+
+// Enabled reports whether requests are authenticated
+func (a *Auth) Enabled() bool {
+	return len(a.authenticators) > 0
+}
+
+// Wrap authenticates every request but the public ones. Protected probes and
+// metrics also need to be authorized for their path.
+func (a *Auth) Wrap(next http.Handler) http.Handler {
+	if !a.Enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.public(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		identity, ok, err := a.authenticate(r)
+		if err != nil {
+			a.logger.Errorw("Failed to authenticate request", "path", r.URL.Path, "error", err)
+			a.metrics.count(ResultError)
+			writeError(w, http.StatusServiceUnavailable, "authentication unavailable")
+			return
+		}
+		if !ok {
+			a.metrics.count(ResultUnauthenticated)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="watchdog"`)
+			writeError(w, http.StatusUnauthorized, "a valid bearer token is required")
+			return
+		}
+		a.metrics.count(ResultAuthenticated)
+
+		ctx := newContext(r.Context(), &caller{identity: identity, authorizer: a.authorizer, metrics: a.metrics})
+		if isProbeOrMetrics(r.URL.Path) {
+			attrs := PathAttributes(strings.ToLower(r.Method), r.URL.Path)
+			allowed, err := Allowed(ctx, attrs)
+			if err != nil {
+				a.logger.Errorw("Failed to authorize request", "path", r.URL.Path, "user", identity.Name, "error", err)
+				writeError(w, http.StatusServiceUnavailable, "authorization unavailable")
+				return
+			}
+			if !allowed {
+				writeError(w, http.StatusForbidden, "forbidden: cannot "+attrs.String())
+				return
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// public reports whether the path is served without authentication. The dashboard's
+// static assets hold no data, the API calls made by the dashboard are authenticated.
+func (a *Auth) public(path string) bool {
+	switch path {
+	case "/healthz", "/readyz":
+		return !a.config.ProtectProbes
+	case "/metrics":
+		return !a.config.ProtectMetrics
+	case "/", "/dashboard":
+		return true
+	}
+	return strings.HasPrefix(path, "/dashboard/")
+}
+
+// isProbeOrMetrics reports whether the path is a probe or the metrics endpoint
+func isProbeOrMetrics(path string) bool {
+	return path == "/healthz" || path == "/readyz" || path == "/metrics"
+}
+
+// authenticate tries the authenticators in order, the first one knowing the token wins
+func (a *Auth) authenticate(r *http.Request) (Identity, bool, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return Identity{}, false, nil
+	}
+
+	var errs []error
+	for _, authenticator := range a.authenticators {
+		identity, ok, err := authenticator.Authenticate(r.Context(), token)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			return identity, true, nil
+		}
+	}
+	return Identity{}, false, errors.Join(errs...)
+}
+
+// bearerToken returns the token of the Authorization header
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// callerKey is the context key of the caller
+type callerKey struct{}
+
+// caller is the authenticated identity of a request and the authorizer deciding its
+// actions, along with the metrics counting its decisions
+type caller struct {
+	identity   Identity
+	authorizer Authorizer
+	metrics    *metrics
+}
+
+// NewContext returns a context carrying the caller's identity and the authorizer
+// deciding its actions
+func NewContext(ctx context.Context, identity Identity, authorizer Authorizer) context.Context {
+	return newContext(ctx, &caller{identity: identity, authorizer: authorizer})
+}
+
+// newContext returns a context carrying the caller
+func newContext(ctx context.Context, c *caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, c)
+}
+
+// IdentityFrom returns the authenticated identity of the request context
+func IdentityFrom(ctx context.Context) (Identity, bool) {
+	c, ok := ctx.Value(callerKey{}).(*caller)
+	if !ok {
+		return Identity{}, false
+	}
+	return c.identity, true
+}
+
+// Allowed reports whether the caller of the request context may perform the action.
+// Everything is allowed when authentication is disabled and for static identities.
+func Allowed(ctx context.Context, attrs Attributes) (bool, error) {
+	c, ok := ctx.Value(callerKey{}).(*caller)
+	if !ok || c.identity.Static {
+		return true, nil
+	}
+	if c.authorizer == nil {
+		return false, nil
+	}
+
+	allowed, err := c.authorizer.Authorize(ctx, c.identity, attrs)
+	if err != nil {
+		c.metrics.count(ResultError)
+		return false, err
+	}
+	if !allowed {
+		c.metrics.count(ResultForbidden)
+	}
+	return allowed, nil
+}
+
+// writeError writes a JSON error response like the API does
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+// rules is an authorizer allowing the listed actions of every identity
+type rules map[Attributes]bool
+
+func (r rules) Authorize(_ context.Context, _ Identity, attrs Attributes) (bool, error) {
+	return r[attrs], nil
+}
+
+func writeToken(t *testing.T, token string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(file, []byte(token+"\n"), 0o600))
+	return file
+}
+
+func newTestAuth(t *testing.T, authCfg config.AuthConfig) *Auth {
+	t.Helper()
+	a, err := NewAuth(fake.NewSimpleClientset(), &config.Config{HTTP: config.HTTPConfig{Auth: authCfg}}, zap.NewNop().Sugar(), nil)
+	require.NoError(t, err)
+	return a
+}
+
+// whoami echoes the identity of the request
+var whoami = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	identity, ok := IdentityFrom(r.Context())
+	if !ok {
+		_, _ = w.Write([]byte("anonymous"))
+		return
+	}
+	_, _ = w.Write([]byte(identity.Name))
+})
+
+func serve(handler http.Handler, path, token string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, path, http.NoBody)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestWrap(t *testing.T) {
+	t.Run("disabled passes requests through", func(t *testing.T) {
+		a := newTestAuth(t, config.AuthConfig{})
+		require.False(t, a.Enabled())
+
+		w := serve(a.Wrap(whoami), "/api/v1/status", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "anonymous", w.Body.String())
+	})
+
+	t.Run("requires a valid token", func(t *testing.T) {
+		a := newTestAuth(t, config.AuthConfig{Tokens: []config.TokenConfig{{User: "ops", File: writeToken(t, "s3cret")}}})
+		handler := a.Wrap(whoami)
+
+		w := serve(handler, "/api/v1/status", "")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Equal(t, `Bearer realm="watchdog"`, w.Header().Get("WWW-Authenticate"))
+		require.JSONEq(t, `{"error": "a valid bearer token is required"}`, w.Body.String())
+
+		require.Equal(t, http.StatusUnauthorized, serve(handler, "/api/v1/status", "wrong").Code)
+
+		w = serve(handler, "/api/v1/status", "s3cret")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "ops", w.Body.String())
+	})
+
+	t.Run("public paths", func(t *testing.T) {
+		a := newTestAuth(t, config.AuthConfig{Tokens: []config.TokenConfig{{User: "ops", File: writeToken(t, "s3cret")}}})
+		handler := a.Wrap(whoami)
+
+		for _, path := range []string{"/healthz", "/readyz", "/metrics", "/", "/dashboard/", "/dashboard/app.js"} {
+			w := serve(handler, path, "")
+			require.Equal(t, http.StatusOK, w.Code, path)
+			require.Equal(t, "anonymous", w.Body.String(), path)
+		}
+		require.Equal(t, http.StatusUnauthorized, serve(handler, "/unknown", "").Code)
+	})
+
+	t.Run("protected probes and metrics are authorized by path", func(t *testing.T) {
+		a := newTestAuth(t, config.AuthConfig{
+			Tokens:         []config.TokenConfig{{User: "ops", File: writeToken(t, "s3cret")}},
+			ProtectProbes:  true,
+			ProtectMetrics: true,
+		})
+		a.authenticators = append(a.authenticators, staticAuthenticator{"k8s": {Name: "prometheus"}})
+		a.authorizer = rules{PathAttributes("get", "/metrics"): true}
+		handler := a.Wrap(whoami)
+
+		require.Equal(t, http.StatusUnauthorized, serve(handler, "/healthz", "").Code)
+		require.Equal(t, http.StatusUnauthorized, serve(handler, "/metrics", "").Code)
+		require.Equal(t, http.StatusOK, serve(handler, "/healthz", "s3cret").Code)
+
+		require.Equal(t, http.StatusOK, serve(handler, "/metrics", "k8s").Code)
+		w := serve(handler, "/readyz", "k8s")
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.JSONEq(t, `{"error": "forbidden: cannot get /readyz"}`, w.Body.String())
+	})
+}
+
+// staticAuthenticator knows a fixed set of tokens
+type staticAuthenticator map[string]Identity
+
+func (s staticAuthenticator) Authenticate(_ context.Context, token string) (Identity, bool, error) {
+	identity, ok := s[token]
+	return identity, ok, nil
+}
+
+func TestAllowed(t *testing.T) {
+	ctx := context.Background()
+	attrs := PodAttributes("patch", "default")
+
+	// Without an authenticated caller authentication is disabled
+	allowed, err := Allowed(ctx, attrs)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	allowed, err = Allowed(NewContext(ctx, Identity{Name: "ops", Static: true}, nil), attrs)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	authorizer := rules{attrs: true}
+	allowed, err = Allowed(NewContext(ctx, Identity{Name: "alice"}, authorizer), attrs)
+	require.NoError(t, err)
+	require.True(t, allowed)
+	allowed, err = Allowed(NewContext(ctx, Identity{Name: "alice"}, authorizer), PodAttributes("patch", "kube-system"))
+	require.NoError(t, err)
+	require.False(t, allowed)
+	allowed, err = Allowed(NewContext(ctx, Identity{Name: "alice"}, nil), attrs)
+	require.NoError(t, err)
+	require.False(t, allowed)
+}
+
+func TestAttributesString(t *testing.T) {
+	require.Equal(t, "patch pods in namespace default", PodAttributes("patch", "default").String())
+	require.Equal(t, "post /api/v1/pause", PathAttributes("post", "/api/v1/pause").String())
+}
+
+func TestBearerToken(t *testing.T) {
+	for header, want := range map[string]string{
+		"Bearer abc":  "abc",
+		"bearer  abc": "abc",
+		"Basic abc":   "",
+		"Bearer":      "",
+		"":            "",
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		r.Header.Set("Authorization", header)
+		token, ok := bearerToken(r)
+		require.Equal(t, want, token, header)
+		require.Equal(t, want != "", ok, header)
+	}
+}
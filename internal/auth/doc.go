@@ -0,0 +1,15 @@
+// Package auth authenticates and authorizes the requests of the HTTP server.
+//
+// Once the API can pause the watchdog and extend pods, reaching its port must not
+// be enough to use it. Auth is a server.Middleware that requires a bearer token
+// on every request, resolved by the configured authenticators in order:
+//   - static tokens, each read from a file; their identities may call every endpoint
+//   - Kubernetes tokens, checked with a TokenReview
+//
+// Kubernetes identities are authorized with SubjectAccessReviews. Handlers call
+// Allowed with the action at hand: get or patch on pods in a namespace, or a verb
+// on a non-resource path such as post /api/v1/pause. Reviews are cached briefly.
+//
+// The probes and /metrics stay public unless protectProbes or protectMetrics is
+// set, the dashboard's static assets are always public.
+package auth
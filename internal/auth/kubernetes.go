@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+var (
+	_ Authenticator = (*Reviewer)(nil)
+	_ Authorizer    = (*Reviewer)(nil)
+)
+
+// maxCacheEntries bounds each review cache, expired entries are dropped first
+const maxCacheEntries = 1024
+
+// Reviewer authenticates Kubernetes tokens with a TokenReview and authorizes their
+// actions with a SubjectAccessReview. Reviews are cached for the configured TTL.
+type Reviewer struct {
+	clientset      kubernetes.Interface
+	audiences      []string
+	tokens         *reviewCache[Identity]
+	authorizations *reviewCache[bool]
+	now            func() time.Time
+}
+
+// NewReviewer creates a Reviewer
+func NewReviewer(clientset kubernetes.Interface, cfg config.KubernetesAuthConfig) *Reviewer {
+	return &Reviewer{
+		clientset:      clientset,
+		audiences:      cfg.Audiences,
+		tokens:         newReviewCache[Identity](cfg.CacheTTL),
+		authorizations: newReviewCache[bool](cfg.CacheTTL),
+		now:            time.Now,
+	}
+}
+
+// Authenticate reviews the token, only successful reviews are cached
+func (rv *Reviewer) Authenticate(ctx context.Context, token string) (Identity, bool, error) {
+	digest := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(digest[:])
+	if identity, ok := rv.tokens.get(key, rv.now()); ok {
+		return identity, true, nil
+	}
+
+	review, err := rv.clientset.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token, Audiences: rv.audiences},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return Identity{}, false, fmt.Errorf("token review: %w", err)
+	}
+	if !review.Status.Authenticated {
+		return Identity{}, false, nil
+	}
+
+	identity := Identity{
+		Name:   review.Status.User.Username,
+		UID:    review.Status.User.UID,
+		Groups: review.Status.User.Groups,
+	}
+	rv.tokens.put(key, identity, rv.now())
+	return identity, true, nil
+}
+
+// Authorize asks the API server whether the identity may perform the action: get
+// or patch on pods in a namespace, or a verb on a non-resource path
+func (rv *Reviewer) Authorize(ctx context.Context, identity Identity, attrs Attributes) (bool, error) {
+	key := strings.Join([]string{
+		identity.Name, identity.UID, strings.Join(identity.Groups, ","),
+		attrs.Verb, attrs.Namespace, attrs.Path,
+	}, "\x00")
+	if allowed, ok := rv.authorizations.get(key, rv.now()); ok {
+		return allowed, nil
+	}
+
+	spec := authorizationv1.SubjectAccessReviewSpec{
+		User:   identity.Name,
+		UID:    identity.UID,
+		Groups: identity.Groups,
+	}
+	if attrs.Path != "" {
+		spec.NonResourceAttributes = &authorizationv1.NonResourceAttributes{Path: attrs.Path, Verb: attrs.Verb}
+	} else {
+		spec.ResourceAttributes = &authorizationv1.ResourceAttributes{Namespace: attrs.Namespace, Verb: attrs.Verb, Resource: "pods"}
+	}
+
+	review, err := rv.clientset.AuthorizationV1().SubjectAccessReviews().Create(ctx,
+		&authorizationv1.SubjectAccessReview{Spec: spec}, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("subject access review: %w", err)
+	}
+	rv.authorizations.put(key, review.Status.Allowed, rv.now())
+	return review.Status.Allowed, nil
+}
+
+// reviewCache keeps review results until their TTL passes
+type reviewCache[V any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry[V]
+}
+
+// cacheEntry is a cached review result
+type cacheEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// newReviewCache creates a cache, a zero TTL disables caching
+func newReviewCache[V any](ttl time.Duration) *reviewCache[V] {
+	return &reviewCache[V]{ttl: ttl, entries: map[string]cacheEntry[V]{}}
+}
+
+func (c *reviewCache[V]) get(key string, now time.Time) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !now.Before(entry.expires) {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func (c *reviewCache[V]) put(key string, value V, now time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCacheEntries {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	if len(c.entries) >= maxCacheEntries {
+		clear(c.entries)
+	}
+	c.entries[key] = cacheEntry[V]{value: value, expires: now.Add(c.ttl)}
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+// newFakeReviews answers TokenReviews for the token "valid" and allows alice to get
+// pods in the default namespace and to post /api/v1/pause
+func newFakeReviews(reviews *int) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		switch review.Spec.Token {
+		case "valid":
+			review.Status = authenticationv1.TokenReviewStatus{
+				Authenticated: true,
+				User:          authenticationv1.UserInfo{Username: "alice", UID: "1", Groups: []string{"dev"}},
+			}
+		case "broken":
+			return true, nil, errors.New("api server unavailable")
+		}
+		return true, review, nil
+	})
+	clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		spec := review.Spec
+		switch {
+		case spec.User != "alice" || len(spec.Groups) != 1:
+		case spec.ResourceAttributes != nil:
+			review.Status.Allowed = spec.ResourceAttributes.Resource == "pods" &&
+				spec.ResourceAttributes.Namespace == "default" && spec.ResourceAttributes.Verb == "get"
+		case spec.NonResourceAttributes != nil:
+			review.Status.Allowed = *spec.NonResourceAttributes == authorizationv1.NonResourceAttributes{Path: "/api/v1/pause", Verb: "post"}
+		}
+		return true, review, nil
+	})
+	return clientset
+}
+
+func TestReviewer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("authenticates with token reviews", func(t *testing.T) {
+		var reviews int
+		rv := NewReviewer(newFakeReviews(&reviews), config.KubernetesAuthConfig{Audiences: []string{"watchdog"}, CacheTTL: time.Minute})
+
+		identity, ok, err := rv.Authenticate(ctx, "valid")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, Identity{Name: "alice", UID: "1", Groups: []string{"dev"}}, identity)
+
+		_, ok, err = rv.Authenticate(ctx, "invalid")
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		_, _, err = rv.Authenticate(ctx, "broken")
+		require.ErrorContains(t, err, "token review")
+	})
+
+	t.Run("authorizes with subject access reviews", func(t *testing.T) {
+		var reviews int
+		rv := NewReviewer(newFakeReviews(&reviews), config.KubernetesAuthConfig{})
+		alice := Identity{Name: "alice", UID: "1", Groups: []string{"dev"}}
+
+		for attrs, want := range map[Attributes]bool{
+			PodAttributes("get", "default"):         true,
+			PodAttributes("patch", "default"):       false,
+			PodAttributes("get", "kube-system"):     false,
+			PathAttributes("post", "/api/v1/pause"): true,
+			PathAttributes("post", "/api/v1/runs"):  false,
+		} {
+			allowed, err := rv.Authorize(ctx, alice, attrs)
+			require.NoError(t, err)
+			require.Equal(t, want, allowed, attrs.String())
+		}
+
+		allowed, err := rv.Authorize(ctx, Identity{Name: "bob"}, PodAttributes("get", "default"))
+		require.NoError(t, err)
+		require.False(t, allowed)
+	})
+
+	t.Run("caches reviews for the TTL", func(t *testing.T) {
+		var reviews int
+		now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+		rv := NewReviewer(newFakeReviews(&reviews), config.KubernetesAuthConfig{CacheTTL: time.Minute})
+		rv.now = func() time.Time { return now }
+
+		for range 3 {
+			identity, ok, err := rv.Authenticate(ctx, "valid")
+			require.NoError(t, err)
+			require.True(t, ok)
+			allowed, err := rv.Authorize(ctx, identity, PodAttributes("get", "default"))
+			require.NoError(t, err)
+			require.True(t, allowed)
+		}
+		require.Equal(t, 2, reviews)
+
+		// Failed token reviews are not cached
+		_, ok, err := rv.Authenticate(ctx, "invalid")
+		require.NoError(t, err)
+		require.False(t, ok)
+		_, _, err = rv.Authenticate(ctx, "invalid")
+		require.NoError(t, err)
+		require.Equal(t, 4, reviews)
+
+		now = now.Add(time.Minute)
+		_, _, err = rv.Authenticate(ctx, "valid")
+		require.NoError(t, err)
+		require.Equal(t, 5, reviews)
+	})
+}
+
+func TestReviewCache(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	disabled := newReviewCache[bool](0)
+	disabled.put("key", true, now)
+	_, ok := disabled.get("key", now)
+	require.False(t, ok)
+
+	cache := newReviewCache[bool](time.Minute)
+	for i := range maxCacheEntries {
+		cache.put(string(rune(i)), true, now)
+	}
+	// A full cache drops its expired entries
+	cache.put("fresh", true, now.Add(time.Minute))
+	require.Len(t, cache.entries, 1)
+	value, ok := cache.get("fresh", now.Add(time.Minute))
+	require.True(t, ok)
+	require.True(t, value)
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Results of watchdog_http_auth_total
+const (
+	ResultAuthenticated   = "authenticated"
+	ResultUnauthenticated = "unauthenticated"
+	ResultForbidden       = "forbidden"
+	ResultError           = "error"
+)
+
+// metrics are the metrics of the authentication
+type metrics struct {
+	// requestsTotal counts authentication and authorization outcomes
+	requestsTotal *prometheus.CounterVec
+}
+
+// newMetrics registers the authentication metrics, a nil registerer leaves them unregistered
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	factory := promauto.With(registerer)
+	return &metrics{
+		requestsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "watchdog_http_auth_total",
+				Help: "Authentication and authorization outcomes of HTTP requests (authenticated, unauthenticated, forbidden, error)",
+			},
+			[]string{"result"},
+		),
+	}
+}
+
+// count counts an outcome, contexts created by NewContext carry no metrics
+func (m *metrics) count(result string) {
+	if m == nil {
+		return
+	}
+	m.requestsTotal.WithLabelValues(result).Inc()
+}
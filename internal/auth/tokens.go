@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+var _ Authenticator = (*Tokens)(nil)
+
+// Tokens authenticates static bearer tokens, each read from a file such as a
+// mounted Secret. Their identities may call every endpoint.
+type Tokens struct {
+	tokens []staticToken
+}
+
+// staticToken is the digest of a token and the user it belongs to
+type staticToken struct {
+	digest [sha256.Size]byte
+	user   string
+}
+
+// LoadTokens reads the token files, surrounding whitespace is ignored
+func LoadTokens(tokens []config.TokenConfig) (*Tokens, error) {
+	t := &Tokens{}
+	for _, token := range tokens {
+		if token.User == "" || token.File == "" {
+			return nil, errors.New("auth tokens need a user and a file")
+		}
+		data, err := os.ReadFile(token.File)
+		if err != nil {
+			return nil, fmt.Errorf("reading token of %s: %w", token.User, err)
+		}
+		value := strings.TrimSpace(string(data))
+		if value == "" {
+			return nil, fmt.Errorf("token file of %s is empty", token.User)
+		}
+		t.tokens = append(t.tokens, staticToken{digest: sha256.Sum256([]byte(value)), user: token.User})
+	}
+	return t, nil
+}
+
+// Authenticate compares digests in constant time, so neither the token nor its
+// length leaks through timing
+func (t *Tokens) Authenticate(_ context.Context, token string) (Identity, bool, error) {
+	digest := sha256.Sum256([]byte(token))
+	var user string
+	for _, static := range t.tokens {
+		if subtle.ConstantTimeCompare(digest[:], static.digest[:]) == 1 && user == "" {
+			user = static.user
+		}
+	}
+	if user == "" {
+		return Identity{}, false, nil
+	}
+	return Identity{Name: user, Static: true}, true, nil
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+func TestLoadTokens(t *testing.T) {
+	t.Run("authenticates the tokens of each user", func(t *testing.T) {
+		tokens, err := LoadTokens([]config.TokenConfig{
+			{User: "ops", File: writeToken(t, "ops-token")},
+			{User: "ci", File: writeToken(t, "  ci-token  ")},
+		})
+		require.NoError(t, err)
+
+		identity, ok, err := tokens.Authenticate(context.Background(), "ci-token")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, Identity{Name: "ci", Static: true}, identity)
+
+		identity, ok, err = tokens.Authenticate(context.Background(), "ops-token")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "ops", identity.Name)
+
+		_, ok, err = tokens.Authenticate(context.Background(), "ops-token2")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("rejects invalid token files", func(t *testing.T) {
+		_, err := LoadTokens([]config.TokenConfig{{User: "ops", File: filepath.Join(t.TempDir(), "missing")}})
+		require.ErrorContains(t, err, "reading token of ops")
+
+		empty := filepath.Join(t.TempDir(), "empty")
+		require.NoError(t, os.WriteFile(empty, []byte("\n"), 0o600))
+		_, err = LoadTokens([]config.TokenConfig{{User: "ops", File: empty}})
+		require.ErrorContains(t, err, "token file of ops is empty")
+
+		_, err = LoadTokens([]config.TokenConfig{{File: empty}})
+		require.ErrorContains(t, err, "need a user and a file")
+	})
+}
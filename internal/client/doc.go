@@ -10,6 +10,10 @@
 //   - Fallback to kubeconfig file when running outside the cluster (e.g., for local development)
 //   - Proper error handling and logging for client creation failures
 //   - Integration with the application's logging system
+//   - Classification of API errors into not-found, conflict, throttled,
+//     server-timeout, forbidden and fatal classes
+//   - Retry of transient API errors with exponential backoff and jitter
+//     that stays within the caller's context deadline
 //
 // The NewKubernetesClient function follows the dependency injection pattern used
 // throughout the application and returns a configured Kubernetes client interface
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"errors"
+	"net"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// ErrorClass groups Kubernetes API errors by how the caller should react to them
+type ErrorClass string
+
+const (
+	// ClassNotFound means the object is already gone
+	ClassNotFound ErrorClass = "not_found"
+	// ClassConflict means the object changed concurrently
+	ClassConflict ErrorClass = "conflict"
+	// ClassThrottled means the API server or a client rate limiter rejected the call (429)
+	ClassThrottled ErrorClass = "throttled"
+	// ClassServerTimeout covers server timeouts and unavailable or unreachable API servers
+	ClassServerTimeout ErrorClass = "server_timeout"
+	// ClassForbidden means the service account lacks the RBAC permission or credentials are invalid
+	ClassForbidden ErrorClass = "forbidden"
+	// ClassFatal covers every other error, retrying it will not help
+	ClassFatal ErrorClass = "fatal"
+)
+
+// Transient reports whether an error of this class may succeed when retried
+func (c ErrorClass) Transient() bool {
+	return c == ClassConflict || c == ClassThrottled || c == ClassServerTimeout
+}
+
+// Classify returns the class of a Kubernetes API error
+func Classify(err error) ErrorClass {
+	switch {
+	case apierrors.IsNotFound(err), apierrors.IsGone(err):
+		return ClassNotFound
+	case apierrors.IsConflict(err):
+		return ClassConflict
+	case apierrors.IsTooManyRequests(err):
+		return ClassThrottled
+	case apierrors.IsServerTimeout(err), apierrors.IsTimeout(err),
+		apierrors.IsServiceUnavailable(err), apierrors.IsInternalError(err):
+		return ClassServerTimeout
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		return ClassForbidden
+	case isNetworkError(err):
+		return ClassServerTimeout
+	default:
+		return ClassFatal
+	}
+}
+
+// isNetworkError reports errors raised before the API server answered
+func isNetworkError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err)
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestClassify(t *testing.T) {
+	pods := schema.GroupResource{Resource: "pods"}
+	tests := []struct {
+		name      string
+		err       error
+		expected  ErrorClass
+		transient bool
+	}{
+		{name: "not found", err: apierrors.NewNotFound(pods, "pod"), expected: ClassNotFound},
+		{name: "conflict", err: apierrors.NewConflict(pods, "pod", errors.New("changed")), expected: ClassConflict, transient: true},
+		{name: "too many requests", err: apierrors.NewTooManyRequests("slow down", 1), expected: ClassThrottled, transient: true},
+		{name: "server timeout", err: apierrors.NewServerTimeout(pods, "list", 1), expected: ClassServerTimeout, transient: true},
+		{name: "timeout", err: apierrors.NewTimeoutError("timeout", 1), expected: ClassServerTimeout, transient: true},
+		{name: "service unavailable", err: apierrors.NewServiceUnavailable("down"), expected: ClassServerTimeout, transient: true},
+		{name: "connection refused", err: syscall.ECONNREFUSED, expected: ClassServerTimeout, transient: true},
+		{name: "forbidden", err: apierrors.NewForbidden(pods, "pod", errors.New("rbac")), expected: ClassForbidden},
+		{name: "unauthorized", err: apierrors.NewUnauthorized("token"), expected: ClassForbidden},
+		{name: "bad request", err: apierrors.NewBadRequest("bad"), expected: ClassFatal},
+		{name: "plain error", err: errors.New("boom"), expected: ClassFatal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			class := Classify(tt.err)
+			require.Equal(t, tt.expected, class)
+			require.Equal(t, tt.transient, class.Transient())
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/isdmx/watchdog/internal/health"
+)
+
+// APIServerCheck makes the pod unready while the Kubernetes API server is unreachable
+type APIServerCheck struct {
+	clientset kubernetes.Interface
+}
+
+// NewAPIServerCheck creates the API server reachability check
+func NewAPIServerCheck(clientset kubernetes.Interface) *APIServerCheck {
+	return &APIServerCheck{clientset: clientset}
+}
+
+// HealthChecks returns the readiness check asking the API server for its version
+func (c *APIServerCheck) HealthChecks() []health.Check {
+	return []health.Check{{Name: "apiserver", Kind: health.Readiness, Run: c.check}}
+}
+
+// check gets /version, which every authenticated client may read
+func (c *APIServerCheck) check(ctx context.Context) error {
+	discovery := c.clientset.Discovery()
+	if restClient := discovery.RESTClient(); restClient != nil {
+		if err := restClient.Get().AbsPath("/version").Do(ctx).Error(); err != nil {
+			return fmt.Errorf("API server unreachable: %w", err)
+		}
+		return nil
+	}
+
+	// Clients without a REST client, such as the fake one, only answer ServerVersion
+	if _, err := discovery.ServerVersion(); err != nil {
+		return fmt.Errorf("API server unreachable: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestAPIServerCheck(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	checks := NewAPIServerCheck(clientset).HealthChecks()
+	require.Len(t, checks, 1)
+	require.Equal(t, "apiserver", checks[0].Name)
+	require.NoError(t, checks[0].Run(context.Background()))
+
+	clientset.PrependReactor("get", "version", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewServiceUnavailable("down")
+	})
+	require.ErrorContains(t, checks[0].Run(context.Background()), "API server unreachable: down")
+}
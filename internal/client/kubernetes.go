@@ -1,13 +1,19 @@
 package client
 
 import (
+	"context"
+	"fmt"
 	"path/filepath"
+	"sync"
 
 	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
+
+	"github.com/isdmx/watchdog/internal/config"
 )
 
 // NewKubernetesClient creates a new Kubernetes client
@@ -38,3 +44,36 @@ func NewKubernetesClient(logger *zap.SugaredLogger) (kubernetes.Interface, error
 
 	return clientset, nil
 }
+
+// NewSecretReader reads the Kubernetes Secrets referenced by k8s-secret settings,
+// the client is created on first use so that configurations without such
+// references load without a cluster
+func NewSecretReader() config.SecretReader {
+	var (
+		once      sync.Once
+		clientset kubernetes.Interface
+		clientErr error
+	)
+	return func(ctx context.Context, namespace, name, key string) ([]byte, error) {
+		once.Do(func() {
+			clientset, clientErr = NewKubernetesClient(zap.NewNop().Sugar())
+		})
+		if clientErr != nil {
+			return nil, clientErr
+		}
+		return ReadSecret(ctx, clientset, namespace, name, key)
+	}
+}
+
+// ReadSecret reads a key of a Secret
+func ReadSecret(ctx context.Context, clientset kubernetes.Interface, namespace, name, key string) ([]byte, error) {
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s has no key %q", namespace, name, key)
+	}
+	return value, nil
+}
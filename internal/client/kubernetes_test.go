@@ -1,11 +1,15 @@
 package client
 
 import (
+	"context"
 	"os"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestNewKubernetesClient(t *testing.T) {
@@ -44,3 +48,20 @@ func TestNewKubernetesClient(t *testing.T) {
 		require.NotNil(t, NewKubernetesClient)
 	})
 }
+
+func TestReadSecret(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "chat-webhook"},
+		Data:       map[string][]byte{"url": []byte("https://chat.example.com/hook")},
+	})
+
+	value, err := ReadSecret(context.Background(), clientset, "ops", "chat-webhook", "url")
+	require.NoError(t, err)
+	require.Equal(t, "https://chat.example.com/hook", string(value))
+
+	_, err = ReadSecret(context.Background(), clientset, "ops", "chat-webhook", "token")
+	require.EqualError(t, err, `secret ops/chat-webhook has no key "token"`)
+
+	_, err = ReadSecret(context.Background(), clientset, "ops", "missing", "url")
+	require.Error(t, err)
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+// Backoff describes how transient API errors are retried
+type Backoff struct {
+	Initial     time.Duration
+	Max         time.Duration
+	Factor      float64
+	Jitter      float64
+	MaxAttempts int
+}
+
+// NewBackoff creates a backoff from the retry configuration
+func NewBackoff(cfg config.RetryConfig) Backoff {
+	return Backoff{
+		Initial:     cfg.InitialBackoff,
+		Max:         cfg.MaxBackoff,
+		Factor:      cfg.Factor,
+		Jitter:      cfg.Jitter,
+		MaxAttempts: cfg.MaxAttempts,
+	}
+}
+
+// Retry calls fn until it succeeds, fails with a non-transient error, runs out of
+// attempts or the context deadline leaves no room for another attempt. onError is
+// called with the class of every failed attempt and may be nil.
+func Retry(ctx context.Context, backoff Backoff, fn func(context.Context) error, onError func(ErrorClass, error)) error {
+	delay := backoff.Initial
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+
+		class := Classify(err)
+		if onError != nil {
+			onError(class, err)
+		}
+		if !class.Transient() || attempt >= backoff.MaxAttempts {
+			return err
+		}
+
+		wait := backoff.jittered(delay)
+		if seconds, ok := apierrors.SuggestsClientDelay(err); ok {
+			wait = max(wait, time.Duration(seconds)*time.Second)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		delay = min(time.Duration(float64(delay)*backoff.Factor), backoff.Max)
+	}
+}
+
+// jittered spreads the delay by up to ±Jitter of its value
+func (b Backoff) jittered(delay time.Duration) time.Duration {
+	if b.Jitter <= 0 {
+		return delay
+	}
+	spread := (rand.Float64()*2 - 1) * b.Jitter //nolint:gosec // jitter does not need a secure source
+	return time.Duration(float64(delay) * (1 + spread))
+}
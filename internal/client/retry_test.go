@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+func newTestBackoff() Backoff {
+	return Backoff{
+		Initial:     time.Millisecond,
+		Max:         5 * time.Millisecond,
+		Factor:      2,
+		Jitter:      0.2,
+		MaxAttempts: 4,
+	}
+}
+
+func TestNewBackoff(t *testing.T) {
+	backoff := NewBackoff(config.RetryConfig{
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Factor:         3,
+		Jitter:         0.1,
+		MaxAttempts:    6,
+	})
+	require.Equal(t, Backoff{Initial: time.Second, Max: time.Minute, Factor: 3, Jitter: 0.1, MaxAttempts: 6}, backoff)
+}
+
+func TestRetry(t *testing.T) {
+	transient := apierrors.NewServerTimeout(schema.GroupResource{Resource: "pods"}, "list", 0)
+
+	t.Run("returns nil on first success", func(t *testing.T) {
+		calls := 0
+		err := Retry(context.Background(), newTestBackoff(), func(context.Context) error {
+			calls++
+			return nil
+		}, nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("retries transient errors until success", func(t *testing.T) {
+		calls := 0
+		var classes []ErrorClass
+		err := Retry(context.Background(), newTestBackoff(), func(context.Context) error {
+			calls++
+			if calls < 3 {
+				return transient
+			}
+			return nil
+		}, func(class ErrorClass, _ error) {
+			classes = append(classes, class)
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, calls)
+		require.Equal(t, []ErrorClass{ClassServerTimeout, ClassServerTimeout}, classes)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		calls := 0
+		err := Retry(context.Background(), newTestBackoff(), func(context.Context) error {
+			calls++
+			return transient
+		}, nil)
+		require.ErrorIs(t, err, transient)
+		require.Equal(t, 4, calls)
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		calls := 0
+		forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "pod", errors.New("rbac"))
+		err := Retry(context.Background(), newTestBackoff(), func(context.Context) error {
+			calls++
+			return forbidden
+		}, nil)
+		require.Equal(t, ClassForbidden, Classify(err))
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("stops when the deadline leaves no room", func(t *testing.T) {
+		backoff := newTestBackoff()
+		backoff.Initial = time.Hour
+		backoff.Max = time.Hour
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		calls := 0
+		start := time.Now()
+		err := Retry(ctx, backoff, func(context.Context) error {
+			calls++
+			return transient
+		}, nil)
+		require.Error(t, err)
+		require.Equal(t, 1, calls)
+		require.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("honors the suggested client delay", func(t *testing.T) {
+		backoff := newTestBackoff()
+		backoff.MaxAttempts = 2
+
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+
+		calls := 0
+		err := Retry(ctx, backoff, func(context.Context) error {
+			calls++
+			return apierrors.NewTooManyRequests("slow down", 5)
+		}, nil)
+		require.Equal(t, ClassThrottled, Classify(err))
+		require.Equal(t, 1, calls)
+	})
+}
+
+func TestBackoffJitter(t *testing.T) {
+	backoff := newTestBackoff()
+	for range 100 {
+		delay := backoff.jittered(100 * time.Millisecond)
+		require.GreaterOrEqual(t, delay, 80*time.Millisecond)
+		require.LessOrEqual(t, delay, 120*time.Millisecond)
+	}
+
+	backoff.Jitter = 0
+	require.Equal(t, 100*time.Millisecond, backoff.jittered(100*time.Millisecond))
+}
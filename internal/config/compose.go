@@ -0,0 +1,401 @@
+package config
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	// ConfDir is the directory next to the config file whose YAML files are
+	// merged into it in lexical order
+	ConfDir = "conf.d"
+	// includeKey lists the files a config file includes, relative to it
+	includeKey = "include"
+)
+
+// configNames are the config files looked up when no path is given
+var configNames = []string{"config.yaml", "config.yml"}
+
+// findConfig returns the config file given or found in the lookup directories
+func findConfig(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	dirs := []string{".", "config", "/etc/watchdog"}
+	for _, dir := range dirs {
+		for _, name := range configNames {
+			candidate := filepath.Join(dir, name)
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("config file %s not found in %s", configNames[0], strings.Join(dirs, ", "))
+}
+
+// composition is the config file merged with the files it includes and the
+// files of its conf.d directory
+type composition struct {
+	// dir is the directory of the config file, files are shown relative to it
+	dir string
+	// files lists the files merged, in merge order
+	files []string
+	// loading holds the files being included, to detect cycles
+	loading []string
+	root    *yaml.Node
+	// origins holds the files, or the flag or environment variable, each
+	// setting comes from by YAML path
+	origins map[string][]string
+	// problems holds the unknown settings and the conflicts between the files
+	problems []Problem
+	// digest, when set, only hashes the files without merging them
+	digest hash.Hash
+}
+
+// compose merges the config file with its includes and its conf.d directory.
+// Mappings are merged key by key, lists of values are concatenated without
+// duplicates and lists of objects, such as webhooks, are concatenated. A setting
+// given different values by two files is a conflict, reported by Validate.
+func compose(path string) (*composition, error) {
+	c := &composition{dir: filepath.Dir(path), origins: map[string][]string{}}
+	if err := c.compose(path); err != nil {
+		return nil, err
+	}
+	if c.root == nil {
+		c.root = &yaml.Node{Kind: yaml.MappingNode}
+	}
+	return c, nil
+}
+
+// Digest hashes the content of every file the configuration is composed of, it
+// changes when any of them changes or a file is added to conf.d. It fails when
+// the config file cannot be read, other errors are hashed so that a broken
+// composition is reloaded, and rejected, once.
+func (s Source) Digest() ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	path, err := findConfig(s.path())
+	if err != nil {
+		return sum, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return sum, err
+	}
+	c := &composition{dir: filepath.Dir(path), digest: sha256.New()}
+	if err := c.compose(path); err != nil {
+		fmt.Fprintf(c.digest, "\x00%v", err)
+	}
+	copy(sum[:], c.digest.Sum(nil))
+	return sum, nil
+}
+
+// compose merges the config file followed by the files of its conf.d directory
+func (c *composition) compose(path string) error {
+	if err := c.include(path); err != nil {
+		return err
+	}
+	snippets, err := confFiles(filepath.Join(c.dir, ConfDir))
+	if err != nil {
+		return err
+	}
+	for _, snippet := range snippets {
+		if err := c.include(snippet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// confFiles lists the YAML files of the directory in lexical order, none when
+// it does not exist
+func confFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		// ConfigMap volumes link the files, so follow links to tell directories
+		if info, err := os.Stat(filepath.Join(dir, entry.Name())); err == nil && !info.IsDir() {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// include merges the file and then the files it includes, a file is merged once
+func (c *composition) include(path string) error {
+	path = filepath.Clean(path)
+	if slices.Contains(c.loading, path) {
+		return fmt.Errorf("include cycle: %s", strings.Join(append(c.display(c.loading), c.display([]string{path})...), " -> "))
+	}
+	if slices.Contains(c.files, path) {
+		return nil
+	}
+	c.files = append(c.files, path)
+	c.loading = append(c.loading, path)
+	defer func() { c.loading = c.loading[:len(c.loading)-1] }()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if c.digest != nil {
+		fmt.Fprintf(c.digest, "%s\x00%d\x00", path, len(data))
+		c.digest.Write(data)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	root := resolveAlias(doc.Content[0])
+	if isNull(root) {
+		return nil
+	}
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: expected a mapping of settings", path)
+	}
+
+	includes, err := takeIncludes(root)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if c.digest == nil {
+		c.merge(root, path)
+	}
+
+	for _, pattern := range includes {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("%s: bad include %q: %w", path, pattern, err)
+		}
+		if len(matches) == 0 && !hasMeta(pattern) {
+			return fmt.Errorf("%s: included file %s does not exist", path, pattern)
+		}
+		for _, match := range matches {
+			if err := c.include(match); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// merge merges the settings of a file into the composition
+func (c *composition) merge(root *yaml.Node, path string) {
+	var unknown []string
+	walkSettings(root, reflect.TypeFor[Config](), "", &unknown)
+	for _, setting := range unknown {
+		message := "unknown setting"
+		if len(c.files) > 1 {
+			message += " in " + c.display([]string{path})[0]
+		}
+		c.problems = append(c.problems, Problem{Path: setting, Message: message})
+	}
+
+	if c.root == nil {
+		c.root = &yaml.Node{Kind: yaml.MappingNode}
+	}
+	c.mergeNode(c.root, root, reflect.TypeFor[Config](), "", path)
+}
+
+// mergeNode merges the node of a file into the node holding the value of the
+// setting at path, which decodes into typ
+func (c *composition) mergeNode(into, from *yaml.Node, typ reflect.Type, path, file string) {
+	from = resolveAlias(from)
+	switch {
+	case isNull(from):
+		return
+	case typ.Kind() == reflect.Struct && into.Kind == yaml.MappingNode && from.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(from.Content); i += 2 {
+			key, value := from.Content[i], from.Content[i+1]
+			field, ok := settingField(typ, key.Value)
+			if !ok {
+				continue // reported as unknown
+			}
+			name := settingName(field)
+			if existing := mappingValue(into, name, strings.EqualFold); existing != nil {
+				c.mergeNode(existing, value, field.Type, joinPath(path, name), file)
+				continue
+			}
+			value = resolveAlias(value)
+			into.Content = append(into.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, value)
+			c.record(value, field.Type, joinPath(path, name), file)
+		}
+	case typ.Kind() == reflect.Map && into.Kind == yaml.MappingNode && from.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(from.Content); i += 2 {
+			key, value := from.Content[i], from.Content[i+1]
+			entry := fmt.Sprintf("%s[%q]", path, key.Value)
+			if existing := mappingValue(into, key.Value, func(a, b string) bool { return a == b }); existing != nil {
+				c.mergeNode(existing, value, typ.Elem(), entry, file)
+				continue
+			}
+			into.Content = append(into.Content, key, value)
+			c.record(value, typ.Elem(), entry, file)
+		}
+	case typ.Kind() == reflect.Slice && into.Kind == yaml.SequenceNode && from.Kind == yaml.SequenceNode:
+		for _, item := range from.Content {
+			item = resolveAlias(item)
+			duplicate := func(n *yaml.Node) bool { return sameValue(n, item, typ.Elem()) }
+			if typ.Elem().Kind() != reflect.Struct && slices.ContainsFunc(into.Content, duplicate) {
+				continue
+			}
+			into.Content = append(into.Content, item)
+		}
+		c.addOrigin(path, file)
+	case isNull(into):
+		*into = *from
+		c.record(into, typ, path, file)
+	case !sameValue(into, from, typ):
+		c.problems = append(c.problems, Problem{
+			Path: path,
+			Message: fmt.Sprintf("conflicting values %s in %s and %s in %s",
+				nodeText(into), strings.Join(c.display(c.origins[path]), ", "), nodeText(from), c.display([]string{file})[0]),
+		})
+		*into = *from
+		c.origins[path] = []string{file}
+	default:
+		c.addOrigin(path, file)
+	}
+}
+
+// record notes the file every setting of a value added to the composition comes from
+func (c *composition) record(node *yaml.Node, typ reflect.Type, path, file string) {
+	node = resolveAlias(node)
+	switch {
+	case typ.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if field, ok := settingField(typ, node.Content[i].Value); ok {
+				c.record(node.Content[i+1], field.Type, joinPath(path, settingName(field)), file)
+			}
+		}
+	case typ.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			c.record(node.Content[i+1], typ.Elem(), fmt.Sprintf("%s[%q]", path, node.Content[i].Value), file)
+		}
+	default:
+		c.addOrigin(path, file)
+	}
+}
+
+func (c *composition) addOrigin(path, file string) {
+	if !slices.Contains(c.origins[path], file) {
+		c.origins[path] = append(c.origins[path], file)
+	}
+}
+
+// display shows the files relative to the directory of the config file
+func (c *composition) display(files []string) []string {
+	shown := make([]string, len(files))
+	for i, file := range files {
+		shown[i] = file
+		if rel, err := filepath.Rel(c.dir, file); err == nil && !strings.HasPrefix(rel, "..") {
+			shown[i] = rel
+		}
+	}
+	return shown
+}
+
+// takeIncludes removes the include key from the settings and returns the files listed
+func takeIncludes(root *yaml.Node) ([]string, error) {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if !strings.EqualFold(root.Content[i].Value, includeKey) {
+			continue
+		}
+		value := resolveAlias(root.Content[i+1])
+		root.Content = slices.Delete(root.Content, i, i+2)
+
+		var includes []string
+		switch value.Kind {
+		case yaml.ScalarNode:
+			if !isNull(value) {
+				includes = []string{value.Value}
+			}
+		case yaml.SequenceNode:
+			if err := value.Decode(&includes); err != nil {
+				return nil, fmt.Errorf("include: expected a list of files: %w", err)
+			}
+		default:
+			return nil, errors.New("include: expected a file or a list of files")
+		}
+		return includes, nil
+	}
+	return nil, nil
+}
+
+// mappingValue returns the value of the key in a mapping node
+func mappingValue(node *yaml.Node, key string, equal func(a, b string) bool) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if equal(node.Content[i].Value, key) {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sameValue tells whether two nodes hold the same value of the type, such as
+// 1m and 60s for a duration
+func sameValue(a, b *yaml.Node, typ reflect.Type) bool {
+	a, b = resolveAlias(a), resolveAlias(b)
+	if a.Kind != yaml.ScalarNode || b.Kind != yaml.ScalarNode {
+		return a.Kind == b.Kind && nodeText(a) == nodeText(b)
+	}
+	if typ == reflect.TypeFor[time.Duration]() {
+		da, errA := time.ParseDuration(a.Value)
+		db, errB := time.ParseDuration(b.Value)
+		if errA == nil && errB == nil {
+			return da == db
+		}
+	}
+	return a.Value == b.Value
+}
+
+// nodeText renders a node on one line for messages
+func nodeText(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		return fmt.Sprintf("%q", node.Value)
+	}
+	data, err := yaml.Marshal(&yaml.Node{Kind: node.Kind, Style: yaml.FlowStyle, Content: node.Content})
+	if err != nil {
+		return "?"
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+// hasMeta tells whether the include pattern is a glob, which may match no file
+func hasMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles writes the files, by path relative to the directory
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+}
+
+func TestCompose(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": `
+include: policies/*.yaml
+watchdog:
+  namespaces: [default]
+  labelSelectors:
+    app: sandbox
+  scheduleInterval: 1m
+notifications:
+  webhooks:
+    - name: chat
+      url: https://chat.example.com/hook
+`,
+		"policies/logging.yaml": "logging:\n  level: debug\n",
+		"conf.d/20-team-b.yaml": `
+watchdog:
+  namespaces: [team-b, default]
+  dryRun: true
+`,
+		"conf.d/10-team-a.yaml": `
+watchdog:
+  namespaces: [team-a]
+  scheduleInterval: 60s
+  labelSelectors:
+    tier: ci
+notifications:
+  webhooks:
+    - name: tickets
+      url: https://tickets.example.com/hook
+`,
+		"conf.d/notes.txt": "not: merged\n",
+	})
+
+	cfg, err := NewConfig(Source{Path: filepath.Join(dir, "config.yaml")})
+	require.NoError(t, err)
+	require.Equal(t, []string{"config.yaml", "policies/logging.yaml", "conf.d/10-team-a.yaml", "conf.d/20-team-b.yaml"}, cfg.Files())
+	require.Equal(t, []string{"default", "team-a", "team-b"}, cfg.Watchdog.Namespaces, "lists are concatenated in lexical order without duplicates")
+	require.Equal(t, map[string]string{"app": "sandbox", "tier": "ci"}, cfg.Watchdog.LabelSelectors, "maps are merged key by key")
+	require.Equal(t, time.Minute, cfg.Watchdog.ScheduleInterval, "equal values do not conflict")
+	require.True(t, cfg.Watchdog.DryRun)
+	require.Equal(t, "debug", cfg.Logging.Level)
+	require.Len(t, cfg.Notify.Webhooks, 2)
+	require.Equal(t, "tickets", cfg.Notify.Webhooks[1].Name)
+
+	require.Equal(t, "config.yaml, conf.d/10-team-a.yaml, conf.d/20-team-b.yaml", cfg.origins["watchdog.namespaces"])
+	require.Equal(t, "conf.d/10-team-a.yaml", cfg.origins[`watchdog.labelSelectors["tier"]`])
+	require.Equal(t, "config.yaml, conf.d/10-team-a.yaml", cfg.origins["watchdog.scheduleInterval"])
+	require.Equal(t, "policies/logging.yaml", cfg.origins["logging.level"])
+
+	data, err := cfg.YAML()
+	require.NoError(t, err)
+	require.Contains(t, string(data), "  dryRun: true # conf.d/20-team-b.yaml\n")
+	require.Contains(t, string(data), "  namespaces: # config.yaml, conf.d/10-team-a.yaml, conf.d/20-team-b.yaml\n")
+	require.Contains(t, string(data), "  maxPodLifetime: 1h0m0s\n", "defaults have no origin")
+
+	t.Run("overrides replace the origin", func(t *testing.T) {
+		t.Setenv("WATCHDOG_WATCHDOG_LABELSELECTORS", "app=other")
+		cfg, err := NewConfig(Source{Path: filepath.Join(dir, "config.yaml"), Flags: map[string]string{"watchdog.dryRun": "false"}})
+		require.NoError(t, err)
+		data, err := cfg.YAML()
+		require.NoError(t, err)
+		require.Contains(t, string(data), "  labelSelectors: # WATCHDOG_WATCHDOG_LABELSELECTORS\n    app: other\n")
+		require.Contains(t, string(data), "  dryRun: false # --watchdog.dryRun\n")
+	})
+}
+
+func TestComposeConflicts(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml":      "watchdog:\n  namespaces: [default]\n  maxPodLifetime: 2h\n  labelSelectors:\n    app: sandbox\n",
+		"conf.d/10-a.yaml": "watchdog:\n  maxPodLifetime: 3h\n  labelSelectors:\n    app: other\n",
+		"conf.d/20-b.yaml": "watchdog:\n  maxPodLifetime: 120m\n  schedulInterval: 1m\n",
+	})
+
+	cfg, err := Load(filepath.Join(dir, "config.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, []Problem{
+		{Path: "watchdog.maxPodLifetime", Message: `conflicting values "2h" in config.yaml and "3h" in conf.d/10-a.yaml`},
+		{Path: `watchdog.labelSelectors["app"]`, Message: `conflicting values "sandbox" in config.yaml and "other" in conf.d/10-a.yaml`},
+		{Path: "watchdog.schedulInterval", Message: "unknown setting in conf.d/20-b.yaml"},
+		{Path: "watchdog.maxPodLifetime", Message: `conflicting values "3h" in conf.d/10-a.yaml and "120m" in conf.d/20-b.yaml`},
+	}, problems(t, cfg))
+}
+
+func TestComposeIncludes(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{"config.yaml": "include: [missing.yaml, optional/*.yaml]\n"})
+		_, err := Load(filepath.Join(dir, "config.yaml"))
+		require.EqualError(t, err, filepath.Join(dir, "config.yaml")+": included file "+filepath.Join(dir, "missing.yaml")+" does not exist")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{
+			"config.yaml": "include: a.yaml\n",
+			"a.yaml":      "include: b.yaml\n",
+			"b.yaml":      "include: a.yaml\n",
+		})
+		_, err := Load(filepath.Join(dir, "config.yaml"))
+		require.EqualError(t, err, "include cycle: config.yaml -> a.yaml -> b.yaml -> a.yaml")
+	})
+
+	t.Run("a file is merged once", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{
+			"config.yaml":      "include: [conf.d/10-a.yaml, conf.d/10-a.yaml]\nwatchdog:\n  namespaces: [default]\n",
+			"conf.d/10-a.yaml": "notifications:\n  webhooks:\n    - url: https://chat.example.com/hook\n",
+		})
+		cfg, err := Load(filepath.Join(dir, "config.yaml"))
+		require.NoError(t, err)
+		require.Equal(t, []string{"config.yaml", "conf.d/10-a.yaml"}, cfg.Files())
+		require.Len(t, cfg.Notify.Webhooks, 1)
+	})
+}
+
+func TestSourceDigest(t *testing.T) {
+	dir := t.TempDir()
+	src := Source{Path: filepath.Join(dir, "config.yaml")}
+	writeFiles(t, dir, map[string]string{"config.yaml": "watchdog:\n  namespaces: [default]\n"})
+	digest, err := src.Digest()
+	require.NoError(t, err)
+
+	writeFiles(t, dir, map[string]string{"conf.d/10-a.yaml": "watchdog:\n  dryRun: true\n"})
+	added, err := src.Digest()
+	require.NoError(t, err)
+	require.NotEqual(t, digest, added, "a file added to conf.d changes the digest")
+
+	writeFiles(t, dir, map[string]string{"conf.d/10-a.yaml": "watchdog: ["})
+	broken, err := src.Digest()
+	require.NoError(t, err, "a broken file is hashed, to be rejected on reload")
+	require.NotEqual(t, added, broken)
+
+	require.NoError(t, os.Remove(src.Path))
+	_, err = src.Digest()
+	require.Error(t, err)
+}
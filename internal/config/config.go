@@ -1,16 +1,125 @@
 package config
 
 import (
+	"bytes"
+	"cmp"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
 )
 
 // Config holds the application configuration
 type Config struct {
-	Watchdog WatchdogConfig `mapstructure:"watchdog"`
-	Logging  LoggingConfig  `mapstructure:"logging"`
-	HTTP     HTTPConfig     `mapstructure:"http"`
+	Watchdog   WatchdogConfig   `mapstructure:"watchdog"`
+	Logging    LoggingConfig    `mapstructure:"logging"`
+	HTTP       HTTPConfig       `mapstructure:"http"`
+	Sharding   ShardingConfig   `mapstructure:"sharding"`
+	Notify     NotifyConfig     `mapstructure:"notifications"`
+	Audit      AuditConfig      `mapstructure:"audit"`
+	Snapshots  SnapshotConfig   `mapstructure:"snapshots"`
+	LogArchive LogArchiveConfig `mapstructure:"logArchive"`
+	Pause      PauseConfig      `mapstructure:"pause"`
+	Health     HealthConfig     `mapstructure:"health"`
+	Metrics    MetricsConfig    `mapstructure:"metrics"`
+	Telemetry  TelemetryConfig  `mapstructure:"telemetry"`
+	Reload     ReloadConfig     `mapstructure:"reload"`
+
+	// path is the file the configuration was loaded from
+	path string
+	// source is where the configuration was loaded from
+	source Source
+	// files lists the files merged into the configuration, relative to the
+	// directory of the config file
+	files []string
+	// origins holds the file, flag or environment variable each setting given
+	// comes from by YAML path
+	origins map[string]string
+	// problems holds the unknown settings and the conflicts between the files
+	problems []Problem
+	// references holds the secret references resolved by YAML path
+	references map[string]string
+	// secrets holds the values scrubbed by Redact, longest first
+	secrets []string
+}
+
+// ReloadConfig controls how often the config file is checked for changes, a
+// changed file is applied without a restart once it is valid
+type ReloadConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// MetricsConfig holds the settings of the optional metrics
+type MetricsConfig struct {
+	Prometheus  PrometheusMetricsConfig `mapstructure:"prometheus"`
+	PodExpiry   PodExpiryMetricsConfig  `mapstructure:"podExpiry"`
+	Pushgateway PushgatewayConfig       `mapstructure:"pushgateway"`
+}
+
+// PrometheusMetricsConfig toggles the /metrics endpoint, which may be turned off
+// when the metrics are exported over OTLP instead
+type PrometheusMetricsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// PodExpiryMetricsConfig enables a seconds-until-expiry series per pod, at most
+// MaxSeries of them for the pods expiring first
+type PodExpiryMetricsConfig struct {
+	Enabled   bool `mapstructure:"enabled"`
+	MaxSeries int  `mapstructure:"maxSeries"`
+}
+
+// PushgatewayConfig pushes the metrics of a cycle run by the once command to a
+// Prometheus Pushgateway before it exits, nothing is pushed without a URL
+type PushgatewayConfig struct {
+	URL     string        `mapstructure:"url"`
+	Job     string        `mapstructure:"job"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// TelemetryConfig holds the OpenTelemetry settings, traces and metrics are
+// exported to the same OTLP endpoint
+type TelemetryConfig struct {
+	ServiceName string                 `mapstructure:"serviceName"`
+	OTLP        OTLPConfig             `mapstructure:"otlp"`
+	Tracing     TracingConfig          `mapstructure:"tracing"`
+	Metrics     TelemetryMetricsConfig `mapstructure:"metrics"`
+}
+
+// OTLPConfig describes the OTLP endpoint. Endpoint is a host:port or a URL, the
+// HTTP protocol appends /v1/traces and /v1/metrics to the path of a URL. An empty
+// endpoint falls back to the OTEL_EXPORTER_OTLP_ENDPOINT environment variable.
+type OTLPConfig struct {
+	Endpoint string            `mapstructure:"endpoint"`
+	Protocol string            `mapstructure:"protocol"`
+	Insecure bool              `mapstructure:"insecure"`
+	Headers  map[string]string `mapstructure:"headers" redact:"true"`
+	Timeout  time.Duration     `mapstructure:"timeout"`
+}
+
+// TracingConfig enables a trace per monitoring cycle, SampleRatio is the share
+// of cycles traced
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	SampleRatio float64 `mapstructure:"sampleRatio"`
+}
+
+// TelemetryMetricsConfig enables pushing the metrics over OTLP every Interval
+type TelemetryMetricsConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// HealthConfig holds the liveness and readiness check settings. A zero StallTimeout
+// is the cycle timeout plus two schedule intervals, a zero MaxCycleAge is three
+// schedule intervals plus the cycle timeout.
+type HealthConfig struct {
+	CheckTimeout time.Duration `mapstructure:"checkTimeout"`
+	StallTimeout time.Duration `mapstructure:"stallTimeout"`
+	MaxCycleAge  time.Duration `mapstructure:"maxCycleAge"`
 }
 
 // HTTPConfig holds the healthcheck-specific configuration
@@ -18,16 +127,202 @@ type HTTPConfig struct {
 	Addr         string        `mapstructure:"addr"`
 	ReadTimeout  time.Duration `mapstructure:"readTimeout"`
 	WriteTimeout time.Duration `mapstructure:"writeTimeout"`
+	TLS          TLSConfig     `mapstructure:"tls"`
+	// ShutdownDelay keeps serving after /readyz starts failing on shutdown, so the
+	// pod leaves the Service endpoints before the listeners close
+	ShutdownDelay time.Duration   `mapstructure:"shutdownDelay"`
+	Probes        ListenerConfig  `mapstructure:"probes"`
+	Metrics       ListenerConfig  `mapstructure:"metrics"`
+	Dashboard     DashboardConfig `mapstructure:"dashboard"`
+	Auth          AuthConfig      `mapstructure:"auth"`
+}
+
+// ListenerConfig holds a separate listener for the probes or the metrics. Without an
+// address they are served by the main listener, zero timeouts use the main ones.
+type ListenerConfig struct {
+	Addr         string        `mapstructure:"addr"`
+	ReadTimeout  time.Duration `mapstructure:"readTimeout"`
+	WriteTimeout time.Duration `mapstructure:"writeTimeout"`
+	TLS          TLSConfig     `mapstructure:"tls"`
+}
+
+// TLSConfig enables TLS once a certificate and key are set. With a client CA, clients
+// must present a certificate signed by it, or may when clientAuth is "optional".
+type TLSConfig struct {
+	CertFile     string `mapstructure:"certFile"`
+	KeyFile      string `mapstructure:"keyFile"`
+	ClientCAFile string `mapstructure:"clientCAFile"`
+	ClientAuth   string `mapstructure:"clientAuth"`
+}
+
+// Enabled reports whether TLS is configured
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// AuthConfig holds the authentication settings of the HTTP server, requests are
+// authenticated once tokens or Kubernetes authentication are configured
+type AuthConfig struct {
+	Tokens         []TokenConfig        `mapstructure:"tokens"`
+	Kubernetes     KubernetesAuthConfig `mapstructure:"kubernetes"`
+	ProtectProbes  bool                 `mapstructure:"protectProbes"`
+	ProtectMetrics bool                 `mapstructure:"protectMetrics"`
+	// AllowUnauthenticatedPause serves the pause and resume endpoints without authentication
+	AllowUnauthenticatedPause bool `mapstructure:"allowUnauthenticatedPause"`
+}
+
+// Enabled reports whether requests are authenticated
+func (a AuthConfig) Enabled() bool {
+	return len(a.Tokens) > 0 || a.Kubernetes.Enabled
+}
+
+// TokenConfig holds a static bearer token read from a file
+type TokenConfig struct {
+	User string `mapstructure:"user"`
+	File string `mapstructure:"file"`
+}
+
+// KubernetesAuthConfig holds the settings for TokenReview and SubjectAccessReview
+type KubernetesAuthConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Audiences []string      `mapstructure:"audiences"`
+	CacheTTL  time.Duration `mapstructure:"cacheTTL"`
+}
+
+// DashboardConfig holds the settings of the embedded web dashboard
+type DashboardConfig struct {
+	Enabled bool `mapstructure:"enabled"`
 }
 
 // WatchdogConfig holds the watchdog-specific configuration
 type WatchdogConfig struct {
+	Policy           string            `mapstructure:"policy"`
 	Namespaces       []string          `mapstructure:"namespaces"`
 	LabelSelectors   map[string]string `mapstructure:"labelSelectors"`
 	ScheduleInterval time.Duration     `mapstructure:"scheduleInterval"`
 	MaxPodLifetime   time.Duration     `mapstructure:"maxPodLifetime"`
 	TtlLabel         string            `mapstructure:"ttlLabel"`
 	DryRun           bool              `mapstructure:"dryRun"`
+	CycleTimeout     time.Duration     `mapstructure:"cycleTimeout"`
+	Retry            RetryConfig       `mapstructure:"retry"`
+	Events           EventsConfig      `mapstructure:"events"`
+	Warning          WarningConfig     `mapstructure:"warning"`
+	Extensions       ExtensionsConfig  `mapstructure:"extensions"`
+}
+
+// ExtensionsConfig holds the limits for extending pod lifetimes through the API, a zero
+// MaxLifetime allows extensions up to MaxPodLifetime
+type ExtensionsConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	MaxLifetime   time.Duration `mapstructure:"maxLifetime"`
+	MaxExtensions int           `mapstructure:"maxExtensions"`
+}
+
+// WarningConfig holds the advance expiry warning settings
+type WarningConfig struct {
+	Enabled  bool              `mapstructure:"enabled"`
+	LeadTime time.Duration     `mapstructure:"leadTime"`
+	HTTP     WarningHTTPConfig `mapstructure:"http"`
+}
+
+// WarningHTTPConfig holds the settings for posting expiry notices to the pods themselves
+type WarningHTTPConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Port    int           `mapstructure:"port"`
+	Path    string        `mapstructure:"path"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// EventsConfig holds the Kubernetes Events settings, zero QPS and Burst use the client-go defaults
+type EventsConfig struct {
+	Enabled bool    `mapstructure:"enabled"`
+	QPS     float32 `mapstructure:"qps"`
+	Burst   int     `mapstructure:"burst"`
+}
+
+// RetryConfig holds the backoff settings for transient Kubernetes API errors
+type RetryConfig struct {
+	InitialBackoff time.Duration `mapstructure:"initialBackoff"`
+	MaxBackoff     time.Duration `mapstructure:"maxBackoff"`
+	Factor         float64       `mapstructure:"factor"`
+	Jitter         float64       `mapstructure:"jitter"`
+	MaxAttempts    int           `mapstructure:"maxAttempts"`
+}
+
+// ShardingConfig holds the namespace sharding configuration
+type ShardingConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Identity       string        `mapstructure:"identity"`
+	Group          string        `mapstructure:"group"`
+	LeaseNamespace string        `mapstructure:"leaseNamespace"`
+	LeaseDuration  time.Duration `mapstructure:"leaseDuration"`
+	RenewInterval  time.Duration `mapstructure:"renewInterval"`
+	VirtualNodes   int           `mapstructure:"virtualNodes"`
+}
+
+// NotifyConfig holds the outbound webhook notification settings
+type NotifyConfig struct {
+	QueueSize int             `mapstructure:"queueSize"`
+	Workers   int             `mapstructure:"workers"`
+	Webhooks  []WebhookConfig `mapstructure:"webhooks"`
+}
+
+// WebhookConfig holds the settings of a single notification endpoint
+type WebhookConfig struct {
+	Name        string            `mapstructure:"name"`
+	URL         string            `mapstructure:"url"`
+	Events      []string          `mapstructure:"events"`
+	Template    string            `mapstructure:"template"`
+	ContentType string            `mapstructure:"contentType"`
+	Headers     map[string]string `mapstructure:"headers" redact:"true"`
+	Secret      string            `mapstructure:"secret" redact:"true"`
+	Timeout     time.Duration     `mapstructure:"timeout"`
+	MaxAttempts int               `mapstructure:"maxAttempts"`
+	Backoff     time.Duration     `mapstructure:"backoff"`
+}
+
+// AuditConfig holds the audit log settings
+type AuditConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	Output     string `mapstructure:"output"`
+	Path       string `mapstructure:"path"`
+	MaxSizeMB  int    `mapstructure:"maxSizeMB"`
+	MaxBackups int    `mapstructure:"maxBackups"`
+	HashChain  bool   `mapstructure:"hashChain"`
+}
+
+// SnapshotConfig holds the settings for saving pod manifests before termination
+type SnapshotConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Store     string        `mapstructure:"store"`
+	Directory string        `mapstructure:"directory"`
+	Namespace string        `mapstructure:"namespace"`
+	Retention time.Duration `mapstructure:"retention"`
+	Required  bool          `mapstructure:"required"`
+}
+
+// LogArchiveConfig holds the settings for archiving container logs before termination
+type LogArchiveConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Directory string        `mapstructure:"directory"`
+	TailLines int64         `mapstructure:"tailLines"`
+	MaxBytes  int64         `mapstructure:"maxBytes"`
+	Previous  bool          `mapstructure:"previous"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+	Retention time.Duration `mapstructure:"retention"`
+	Required  bool          `mapstructure:"required"`
+}
+
+// PauseConfig holds the settings of the kill switch that pauses terminations
+type PauseConfig struct {
+	ConfigMap PauseConfigMapConfig `mapstructure:"configMap"`
+}
+
+// PauseConfigMapConfig holds the settings of the watched ConfigMap that pauses terminations
+type PauseConfigMapConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	Namespace string `mapstructure:"namespace"`
+	Name      string `mapstructure:"name"`
 }
 
 // LoggingConfig holds the logging configuration
@@ -37,43 +332,229 @@ type LoggingConfig struct {
 }
 
 const (
-	defaultHTTPAddr         = ":8080"
-	defaultReadTimeout      = 5 * time.Second
-	defaultWriteTimeout     = 10 * time.Second
-	defaultScheduleInterval = 10 * time.Minute
-	defaultMaxPodLifetime   = 1 * time.Hour
-	defaultDryRun           = false
-	defaultLogLevel         = "info"
-	defaultLogMode          = "production"
+	defaultHTTPAddr          = ":8080"
+	defaultReadTimeout       = 5 * time.Second
+	defaultWriteTimeout      = 10 * time.Second
+	defaultAuthCacheTTL      = time.Minute
+	defaultCheckTimeout      = 5 * time.Second
+	defaultScheduleInterval  = 10 * time.Minute
+	defaultMaxPodLifetime    = 1 * time.Hour
+	defaultDryRun            = false
+	defaultLogLevel          = "info"
+	defaultLogMode           = "production"
+	defaultEventsEnabled     = true
+	defaultWarningLeadTime   = 15 * time.Minute
+	defaultWarningPort       = 8080
+	defaultWarningPath       = "/watchdog/expiry"
+	defaultWarningTimeout    = 5 * time.Second
+	defaultMaxExtensions     = 3
+	defaultInitialBackoff    = 200 * time.Millisecond
+	defaultMaxBackoff        = 10 * time.Second
+	defaultBackoffFactor     = 2.0
+	defaultBackoffJitter     = 0.2
+	defaultMaxAttempts       = 5
+	defaultNotifyQueueSize   = 100
+	defaultNotifyWorkers     = 2
+	defaultShardGroup        = "watchdog"
+	defaultLeaseNamespace    = "default"
+	defaultLeaseDuration     = 30 * time.Second
+	defaultRenewInterval     = 10 * time.Second
+	defaultVirtualNodes      = 64
+	defaultPolicy            = "default"
+	defaultAuditOutput       = "stdout"
+	defaultAuditPath         = "/var/log/watchdog/audit.jsonl"
+	defaultAuditMaxSizeMB    = 100
+	defaultAuditMaxBackups   = 5
+	defaultSnapshotStore     = "directory"
+	defaultSnapshotDirectory = "/var/lib/watchdog/snapshots"
+	defaultSnapshotNamespace = "default"
+	defaultSnapshotRetention = 7 * 24 * time.Hour
+	defaultLogArchiveDir     = "/var/lib/watchdog/logs"
+	defaultLogTailLines      = 1000
+	defaultLogMaxBytes       = 1024 * 1024
+	defaultLogTimeout        = 30 * time.Second
+	defaultLogRetention      = 7 * 24 * time.Hour
+	defaultPauseNamespace    = "default"
+	defaultPauseConfigMap    = "watchdog-pause"
+	defaultPodExpirySeries   = 500
+	defaultPushgatewayJob    = "watchdog"
+	defaultPushTimeout       = 10 * time.Second
+	defaultServiceName       = "watchdog"
+	defaultOTLPProtocol      = "grpc"
+	defaultOTLPTimeout       = 10 * time.Second
+	defaultTraceSampleRatio  = 1.0
+	defaultOTLPInterval      = time.Minute
+	defaultReloadInterval    = 10 * time.Second
 )
 
-// NewConfig loads the configuration from the config file
-func NewConfig() (*Config, error) {
-	viper.SetOptions(viper.KeyDelimiter("::")) // because labelSelectors may contain `.`
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
-	viper.AddConfigPath("config")
+// NewConfig loads the configuration from the source and fails unless it is valid
+func NewConfig(src Source) (*Config, error) {
+	cfg, err := src.Load()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Load loads the configuration from the file and the environment without
+// validating it, an empty path looks the file up like NewConfig
+func Load(path string) (*Config, error) {
+	return Source{Path: path}.Load()
+}
 
-	// Set default values
-	viper.SetDefault("http::addr", defaultHTTPAddr)
-	viper.SetDefault("http::readTimeout", defaultReadTimeout)
-	viper.SetDefault("http::writeTimeout", defaultWriteTimeout)
-	viper.SetDefault("watchdog::scheduleInterval", defaultScheduleInterval)
-	viper.SetDefault("watchdog::maxPodLifetime", defaultMaxPodLifetime)
-	viper.SetDefault("watchdog::dryRun", defaultDryRun)
-	viper.SetDefault("logging::mode", defaultLogMode)
-	viper.SetDefault("logging::level", defaultLogLevel)
-
-	// Read the config file
-	if err := viper.ReadInConfig(); err != nil {
+// Load loads the configuration from the source without validating it. Config
+// reloads use it to parse the changed file from scratch.
+func (s Source) Load() (*Config, error) {
+	path, err := findConfig(s.path())
+	if err != nil {
+		return nil, err
+	}
+	composed, err := compose(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(composed.root)
+	if err != nil {
 		return nil, err
 	}
 
-	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
+	v := newViper()
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	overridden, err := s.applyOverrides(v)
+	if err != nil {
 		return nil, err
 	}
 
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, err
+	}
+	cfg.path = path
+	if err := s.resolveSecrets(&cfg); err != nil {
+		return nil, err
+	}
+	cfg.files = composed.display(composed.files)
+	cfg.origins = map[string]string{}
+	for setting, files := range composed.origins {
+		cfg.origins[setting] = strings.Join(composed.display(files), ", ")
+	}
+	for setting, origin := range overridden {
+		for key := range cfg.origins {
+			if strings.HasPrefix(key, setting+"[") {
+				delete(cfg.origins, key)
+			}
+		}
+		cfg.origins[setting] = origin
+	}
+	cfg.problems = composed.problems
+	cfg.source = Source{Path: path, Flags: s.Flags, Secrets: s.Secrets, Unresolved: s.Unresolved}
 	return &cfg, nil
 }
+
+// path returns the config file given, "" to look it up
+func (s Source) path() string {
+	return cmp.Or(s.Path, os.Getenv(EnvConfig))
+}
+
+// Source returns where the configuration was loaded from, with the path of
+// the file found
+func (c *Config) Source() Source {
+	return c.source
+}
+
+// Path returns the file the configuration was loaded from
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Files returns the files merged into the configuration: the config file, the
+// files it includes and the files of its conf.d directory
+func (c *Config) Files() []string {
+	return c.files
+}
+
+// newViper creates a viper instance holding the default values
+func newViper() *viper.Viper {
+	v := viper.NewWithOptions(viper.KeyDelimiter("::")) // because labelSelectors may contain `.`
+	v.SetConfigType("yaml")
+
+	// Set default values
+	v.SetDefault("http::addr", defaultHTTPAddr)
+	v.SetDefault("http::readTimeout", defaultReadTimeout)
+	v.SetDefault("http::writeTimeout", defaultWriteTimeout)
+	v.SetDefault("http::shutdownDelay", 0)
+	v.SetDefault("http::dashboard::enabled", true)
+	v.SetDefault("health::checkTimeout", defaultCheckTimeout)
+	v.SetDefault("http::auth::kubernetes::enabled", false)
+	v.SetDefault("http::auth::kubernetes::cacheTTL", defaultAuthCacheTTL)
+	v.SetDefault("http::auth::allowUnauthenticatedPause", false)
+	v.SetDefault("watchdog::policy", defaultPolicy)
+	v.SetDefault("watchdog::scheduleInterval", defaultScheduleInterval)
+	v.SetDefault("watchdog::maxPodLifetime", defaultMaxPodLifetime)
+	v.SetDefault("watchdog::dryRun", defaultDryRun)
+	v.SetDefault("watchdog::events::enabled", defaultEventsEnabled)
+	v.SetDefault("watchdog::warning::leadTime", defaultWarningLeadTime)
+	v.SetDefault("watchdog::warning::http::port", defaultWarningPort)
+	v.SetDefault("watchdog::warning::http::path", defaultWarningPath)
+	v.SetDefault("watchdog::warning::http::timeout", defaultWarningTimeout)
+	v.SetDefault("watchdog::extensions::enabled", false)
+	v.SetDefault("watchdog::extensions::maxExtensions", defaultMaxExtensions)
+	v.SetDefault("watchdog::retry::initialBackoff", defaultInitialBackoff)
+	v.SetDefault("watchdog::retry::maxBackoff", defaultMaxBackoff)
+	v.SetDefault("watchdog::retry::factor", defaultBackoffFactor)
+	v.SetDefault("watchdog::retry::jitter", defaultBackoffJitter)
+	v.SetDefault("watchdog::retry::maxAttempts", defaultMaxAttempts)
+	v.SetDefault("logging::mode", defaultLogMode)
+	v.SetDefault("logging::level", defaultLogLevel)
+	v.SetDefault("notifications::queueSize", defaultNotifyQueueSize)
+	v.SetDefault("notifications::workers", defaultNotifyWorkers)
+	v.SetDefault("sharding::enabled", false)
+	v.SetDefault("sharding::group", defaultShardGroup)
+	v.SetDefault("sharding::leaseNamespace", defaultLeaseNamespace)
+	v.SetDefault("sharding::leaseDuration", defaultLeaseDuration)
+	v.SetDefault("sharding::renewInterval", defaultRenewInterval)
+	v.SetDefault("sharding::virtualNodes", defaultVirtualNodes)
+	v.SetDefault("audit::enabled", false)
+	v.SetDefault("audit::output", defaultAuditOutput)
+	v.SetDefault("audit::path", defaultAuditPath)
+	v.SetDefault("audit::maxSizeMB", defaultAuditMaxSizeMB)
+	v.SetDefault("audit::maxBackups", defaultAuditMaxBackups)
+	v.SetDefault("audit::hashChain", true)
+	v.SetDefault("snapshots::enabled", false)
+	v.SetDefault("snapshots::store", defaultSnapshotStore)
+	v.SetDefault("snapshots::directory", defaultSnapshotDirectory)
+	v.SetDefault("snapshots::namespace", defaultSnapshotNamespace)
+	v.SetDefault("snapshots::retention", defaultSnapshotRetention)
+	v.SetDefault("logArchive::enabled", false)
+	v.SetDefault("logArchive::directory", defaultLogArchiveDir)
+	v.SetDefault("logArchive::tailLines", defaultLogTailLines)
+	v.SetDefault("logArchive::maxBytes", defaultLogMaxBytes)
+	v.SetDefault("logArchive::previous", true)
+	v.SetDefault("logArchive::timeout", defaultLogTimeout)
+	v.SetDefault("logArchive::retention", defaultLogRetention)
+	v.SetDefault("pause::configMap::enabled", false)
+	v.SetDefault("pause::configMap::namespace", defaultPauseNamespace)
+	v.SetDefault("pause::configMap::name", defaultPauseConfigMap)
+	v.SetDefault("metrics::prometheus::enabled", true)
+	v.SetDefault("metrics::podExpiry::enabled", false)
+	v.SetDefault("metrics::podExpiry::maxSeries", defaultPodExpirySeries)
+	v.SetDefault("metrics::pushgateway::job", defaultPushgatewayJob)
+	v.SetDefault("metrics::pushgateway::timeout", defaultPushTimeout)
+	v.SetDefault("telemetry::serviceName", defaultServiceName)
+	v.SetDefault("telemetry::otlp::protocol", defaultOTLPProtocol)
+	v.SetDefault("telemetry::otlp::insecure", false)
+	v.SetDefault("telemetry::otlp::timeout", defaultOTLPTimeout)
+	v.SetDefault("telemetry::tracing::enabled", false)
+	v.SetDefault("telemetry::tracing::sampleRatio", defaultTraceSampleRatio)
+	v.SetDefault("telemetry::metrics::enabled", false)
+	v.SetDefault("telemetry::metrics::interval", defaultOTLPInterval)
+	v.SetDefault("reload::enabled", true)
+	v.SetDefault("reload::interval", defaultReloadInterval)
+
+	return v
+}
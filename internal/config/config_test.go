@@ -16,6 +16,7 @@ func TestNewConfig(t *testing.T) {
 		cfgPath := filepath.Join(tmpDir, "config.yaml")
 		err := os.WriteFile(cfgPath, []byte(`
 watchdog:
+  policy: sandboxes
   namespaces: ["default", "kube-system"]
   labelSelectors:
     app: test
@@ -23,13 +24,143 @@ watchdog:
   maxPodLifetime: 30m
   dryRun: true
   ttlLabel: "sandbox.kill_time"
+  cycleTimeout: 2m
+  retry:
+    initialBackoff: 100ms
+    maxBackoff: 5s
+    factor: 3
+    jitter: 0.5
+    maxAttempts: 7
+  events:
+    enabled: false
+    qps: 0.5
+    burst: 10
+  warning:
+    enabled: true
+    leadTime: 30m
+    http:
+      enabled: true
+      port: 9000
+      path: /expiry
+      timeout: 2s
+  extensions:
+    enabled: true
+    maxLifetime: 8h
+    maxExtensions: 2
 http:
   addr: ":9090"
   readTimeout: 10s
   writeTimeout: 20s
+  tls:
+    certFile: /etc/watchdog/tls/tls.crt
+    keyFile: /etc/watchdog/tls/tls.key
+    clientCAFile: /etc/watchdog/tls/ca.crt
+  shutdownDelay: 5s
+  probes:
+    addr: ":8081"
+  metrics:
+    addr: ":9091"
+    readTimeout: 2s
+    writeTimeout: 3s
+    tls:
+      certFile: /etc/watchdog/metrics/tls.crt
+      keyFile: /etc/watchdog/metrics/tls.key
+      clientAuth: optional
+  dashboard:
+    enabled: false
+  auth:
+    tokens:
+      - user: ops
+        file: /etc/watchdog/tokens/ops
+    kubernetes:
+      enabled: true
+      audiences: [watchdog]
+      cacheTTL: 30s
+    protectProbes: true
+    protectMetrics: true
+    allowUnauthenticatedPause: true
 logging:
   mode: development
   level: debug
+notifications:
+  queueSize: 10
+  workers: 1
+  webhooks:
+    - name: chat
+      url: https://chat.example.com/hook
+      events: [terminated, failed]
+      template: '{"text": "{{ .Pod.Name }}"}'
+      headers:
+        X-Team: sandbox
+      secret: s3cr3t
+      timeout: 3s
+      maxAttempts: 4
+      backoff: 1s
+sharding:
+  enabled: true
+  identity: watchdog-0
+  group: sandbox
+  leaseNamespace: watchdog
+  leaseDuration: 15s
+  renewInterval: 5s
+  virtualNodes: 16
+audit:
+  enabled: true
+  output: file
+  path: /tmp/audit.jsonl
+  maxSizeMB: 10
+  maxBackups: 2
+  hashChain: false
+snapshots:
+  enabled: true
+  store: secret
+  directory: /tmp/snapshots
+  namespace: watchdog
+  retention: 24h
+  required: true
+logArchive:
+  enabled: true
+  directory: /tmp/logs
+  tailLines: 50
+  maxBytes: 4096
+  previous: false
+  timeout: 10s
+  retention: 48h
+  required: true
+pause:
+  configMap:
+    enabled: true
+    namespace: watchdog
+    name: kill-switch
+health:
+  checkTimeout: 2s
+  stallTimeout: 10m
+  maxCycleAge: 15m
+metrics:
+  prometheus:
+    enabled: false
+  podExpiry:
+    enabled: true
+    maxSeries: 100
+  pushgateway:
+    url: http://pushgateway:9091
+    job: watchdog-sandboxes
+    timeout: 5s
+telemetry:
+  serviceName: watchdog-test
+  otlp:
+    endpoint: http://collector:4318
+    protocol: http
+    insecure: true
+    headers:
+      authorization: Bearer secret
+    timeout: 3s
+  tracing:
+    enabled: true
+    sampleRatio: 0.5
+  metrics:
+    enabled: true
+    interval: 30s
 `), 0o600)
 		require.NoError(t, err)
 
@@ -42,26 +173,148 @@ logging:
 			_ = os.Chdir(origDir)
 		})
 
-		config, err := NewConfig()
+		config, err := NewConfig(Source{})
 		require.NoError(t, err)
 		require.NotNil(t, config)
 
 		// Check watchdog config
+		require.Equal(t, "sandboxes", config.Watchdog.Policy)
 		require.Equal(t, []string{"default", "kube-system"}, config.Watchdog.Namespaces)
 		require.Equal(t, map[string]string{"app": "test"}, config.Watchdog.LabelSelectors)
 		require.Equal(t, 5*time.Minute, config.Watchdog.ScheduleInterval)
 		require.Equal(t, 30*time.Minute, config.Watchdog.MaxPodLifetime)
 		require.Equal(t, "sandbox.kill_time", config.Watchdog.TtlLabel)
 		require.True(t, config.Watchdog.DryRun)
+		require.Equal(t, 2*time.Minute, config.Watchdog.CycleTimeout)
+		require.Equal(t, RetryConfig{
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     5 * time.Second,
+			Factor:         3,
+			Jitter:         0.5,
+			MaxAttempts:    7,
+		}, config.Watchdog.Retry)
+		require.Equal(t, EventsConfig{Enabled: false, QPS: 0.5, Burst: 10}, config.Watchdog.Events)
+		require.Equal(t, WarningConfig{
+			Enabled:  true,
+			LeadTime: 30 * time.Minute,
+			HTTP:     WarningHTTPConfig{Enabled: true, Port: 9000, Path: "/expiry", Timeout: 2 * time.Second},
+		}, config.Watchdog.Warning)
+		require.Equal(t, ExtensionsConfig{Enabled: true, MaxLifetime: 8 * time.Hour, MaxExtensions: 2}, config.Watchdog.Extensions)
 
 		// Check http config
 		require.Equal(t, ":9090", config.HTTP.Addr)
 		require.Equal(t, 10*time.Second, config.HTTP.ReadTimeout)
 		require.Equal(t, 20*time.Second, config.HTTP.WriteTimeout)
+		require.False(t, config.HTTP.Dashboard.Enabled)
+		require.Equal(t, TLSConfig{
+			CertFile:     "/etc/watchdog/tls/tls.crt",
+			KeyFile:      "/etc/watchdog/tls/tls.key",
+			ClientCAFile: "/etc/watchdog/tls/ca.crt",
+		}, config.HTTP.TLS)
+		require.Equal(t, ListenerConfig{Addr: ":8081"}, config.HTTP.Probes)
+		require.Equal(t, 5*time.Second, config.HTTP.ShutdownDelay)
+		require.Equal(t, ListenerConfig{
+			Addr:         ":9091",
+			ReadTimeout:  2 * time.Second,
+			WriteTimeout: 3 * time.Second,
+			TLS:          TLSConfig{CertFile: "/etc/watchdog/metrics/tls.crt", KeyFile: "/etc/watchdog/metrics/tls.key", ClientAuth: "optional"},
+		}, config.HTTP.Metrics)
+		require.Equal(t, AuthConfig{
+			Tokens:                    []TokenConfig{{User: "ops", File: "/etc/watchdog/tokens/ops"}},
+			Kubernetes:                KubernetesAuthConfig{Enabled: true, Audiences: []string{"watchdog"}, CacheTTL: 30 * time.Second},
+			ProtectProbes:             true,
+			ProtectMetrics:            true,
+			AllowUnauthenticatedPause: true,
+		}, config.HTTP.Auth)
 
 		// Check logging config
 		require.Equal(t, "development", config.Logging.Mode)
 		require.Equal(t, "debug", config.Logging.Level)
+
+		// Check notifications config
+		require.Equal(t, 10, config.Notify.QueueSize)
+		require.Equal(t, 1, config.Notify.Workers)
+		require.Equal(t, []WebhookConfig{{
+			Name:        "chat",
+			URL:         "https://chat.example.com/hook",
+			Events:      []string{"terminated", "failed"},
+			Template:    `{"text": "{{ .Pod.Name }}"}`,
+			Headers:     map[string]string{"x-team": "sandbox"},
+			Secret:      "s3cr3t",
+			Timeout:     3 * time.Second,
+			MaxAttempts: 4,
+			Backoff:     time.Second,
+		}}, config.Notify.Webhooks)
+
+		// Check sharding config
+		require.True(t, config.Sharding.Enabled)
+		require.Equal(t, "watchdog-0", config.Sharding.Identity)
+		require.Equal(t, "sandbox", config.Sharding.Group)
+		require.Equal(t, "watchdog", config.Sharding.LeaseNamespace)
+		require.Equal(t, 15*time.Second, config.Sharding.LeaseDuration)
+		require.Equal(t, 5*time.Second, config.Sharding.RenewInterval)
+		require.Equal(t, 16, config.Sharding.VirtualNodes)
+
+		// Check audit config
+		require.Equal(t, AuditConfig{
+			Enabled:    true,
+			Output:     "file",
+			Path:       "/tmp/audit.jsonl",
+			MaxSizeMB:  10,
+			MaxBackups: 2,
+			HashChain:  false,
+		}, config.Audit)
+
+		// Check snapshot config
+		require.Equal(t, SnapshotConfig{
+			Enabled:   true,
+			Store:     "secret",
+			Directory: "/tmp/snapshots",
+			Namespace: "watchdog",
+			Retention: 24 * time.Hour,
+			Required:  true,
+		}, config.Snapshots)
+
+		// Check log archive config
+		require.Equal(t, LogArchiveConfig{
+			Enabled:   true,
+			Directory: "/tmp/logs",
+			TailLines: 50,
+			MaxBytes:  4096,
+			Previous:  false,
+			Timeout:   10 * time.Second,
+			Retention: 48 * time.Hour,
+			Required:  true,
+		}, config.LogArchive)
+
+		// Check metrics and telemetry config
+		require.Equal(t, MetricsConfig{
+			Prometheus: PrometheusMetricsConfig{Enabled: false},
+			PodExpiry:  PodExpiryMetricsConfig{Enabled: true, MaxSeries: 100},
+			Pushgateway: PushgatewayConfig{
+				URL:     "http://pushgateway:9091",
+				Job:     "watchdog-sandboxes",
+				Timeout: 5 * time.Second,
+			},
+		}, config.Metrics)
+		require.Equal(t, TelemetryConfig{
+			ServiceName: "watchdog-test",
+			OTLP: OTLPConfig{
+				Endpoint: "http://collector:4318",
+				Protocol: "http",
+				Insecure: true,
+				Headers:  map[string]string{"authorization": "Bearer secret"},
+				Timeout:  3 * time.Second,
+			},
+			Tracing: TracingConfig{Enabled: true, SampleRatio: 0.5},
+			Metrics: TelemetryMetricsConfig{Enabled: true, Interval: 30 * time.Second},
+		}, config.Telemetry)
+
+		// Check pause config
+		require.Equal(t, PauseConfig{
+			ConfigMap: PauseConfigMapConfig{Enabled: true, Namespace: "watchdog", Name: "kill-switch"},
+		}, config.Pause)
+		require.Equal(t, HealthConfig{CheckTimeout: 2 * time.Second, StallTimeout: 10 * time.Minute, MaxCycleAge: 15 * time.Minute}, config.Health)
 	})
 
 	t.Run("uses default values when config is missing", func(t *testing.T) {
@@ -76,7 +329,7 @@ logging:
 			_ = os.Chdir(origDir)
 		})
 
-		config, err := NewConfig()
+		config, err := NewConfig(Source{})
 		// This should fail because it can't find the config file
 		require.Error(t, err)
 		require.Nil(t, config)
@@ -96,7 +349,7 @@ logging:
 			_ = os.Chdir(origDir)
 		})
 
-		config, err := NewConfig()
+		config, err := NewConfig(Source{})
 		require.Error(t, err)
 		require.Nil(t, config)
 	})
@@ -106,7 +359,7 @@ func TestConfigDefaults(t *testing.T) {
 	// Test with default values when config file exists but doesn't specify all values
 	tmpDir := t.TempDir()
 	cfgPath := filepath.Join(tmpDir, "config.yaml")
-	err := os.WriteFile(cfgPath, []byte(`watchdog: {}`), 0o600)
+	err := os.WriteFile(cfgPath, []byte(`watchdog: {namespaces: [default]}`), 0o600)
 	require.NoError(t, err)
 
 	origDir, err := os.Getwd()
@@ -117,7 +370,7 @@ func TestConfigDefaults(t *testing.T) {
 		_ = os.Chdir(origDir)
 	})
 
-	config, err := NewConfig()
+	config, err := NewConfig(Source{})
 	require.NoError(t, err)
 	require.NotNil(t, config)
 
@@ -125,10 +378,100 @@ func TestConfigDefaults(t *testing.T) {
 	require.Equal(t, defaultHTTPAddr, config.HTTP.Addr)
 	require.Equal(t, defaultReadTimeout, config.HTTP.ReadTimeout)
 	require.Equal(t, defaultWriteTimeout, config.HTTP.WriteTimeout)
+	require.True(t, config.HTTP.Dashboard.Enabled)
+	require.False(t, config.HTTP.TLS.Enabled())
+	require.Empty(t, config.HTTP.Probes.Addr)
+	require.Empty(t, config.HTTP.Metrics.Addr)
+	require.Zero(t, config.HTTP.ShutdownDelay)
+	require.Equal(t, HealthConfig{CheckTimeout: defaultCheckTimeout}, config.Health)
+	require.Equal(t, PodExpiryMetricsConfig{MaxSeries: defaultPodExpirySeries}, config.Metrics.PodExpiry)
+	require.Equal(t, PushgatewayConfig{Job: defaultPushgatewayJob, Timeout: defaultPushTimeout}, config.Metrics.Pushgateway)
+	require.True(t, config.Metrics.Prometheus.Enabled)
+	require.Equal(t, TelemetryConfig{
+		ServiceName: defaultServiceName,
+		OTLP:        OTLPConfig{Protocol: defaultOTLPProtocol, Timeout: defaultOTLPTimeout},
+		Tracing:     TracingConfig{SampleRatio: defaultTraceSampleRatio},
+		Metrics:     TelemetryMetricsConfig{Interval: defaultOTLPInterval},
+	}, config.Telemetry)
+	require.Equal(t, ReloadConfig{Enabled: true, Interval: defaultReloadInterval}, config.Reload)
+	require.Equal(t, AuthConfig{Kubernetes: KubernetesAuthConfig{CacheTTL: defaultAuthCacheTTL}}, config.HTTP.Auth)
+	require.Equal(t, defaultPolicy, config.Watchdog.Policy)
 	require.Equal(t, defaultScheduleInterval, config.Watchdog.ScheduleInterval)
 	require.Equal(t, defaultMaxPodLifetime, config.Watchdog.MaxPodLifetime)
 	require.Empty(t, config.Watchdog.TtlLabel)
 	require.Equal(t, defaultDryRun, config.Watchdog.DryRun)
+	require.Zero(t, config.Watchdog.CycleTimeout)
+	require.Equal(t, defaultInitialBackoff, config.Watchdog.Retry.InitialBackoff)
+	require.Equal(t, defaultMaxBackoff, config.Watchdog.Retry.MaxBackoff)
+	require.InDelta(t, defaultBackoffFactor, config.Watchdog.Retry.Factor, 0)
+	require.InDelta(t, defaultBackoffJitter, config.Watchdog.Retry.Jitter, 0)
+	require.Equal(t, defaultMaxAttempts, config.Watchdog.Retry.MaxAttempts)
+	require.Equal(t, EventsConfig{Enabled: defaultEventsEnabled}, config.Watchdog.Events)
+	require.Equal(t, WarningConfig{
+		LeadTime: defaultWarningLeadTime,
+		HTTP:     WarningHTTPConfig{Port: defaultWarningPort, Path: defaultWarningPath, Timeout: defaultWarningTimeout},
+	}, config.Watchdog.Warning)
+	require.Equal(t, ExtensionsConfig{MaxExtensions: defaultMaxExtensions}, config.Watchdog.Extensions)
 	require.Equal(t, defaultLogMode, config.Logging.Mode)
 	require.Equal(t, defaultLogLevel, config.Logging.Level)
+	require.Equal(t, defaultNotifyQueueSize, config.Notify.QueueSize)
+	require.Equal(t, defaultNotifyWorkers, config.Notify.Workers)
+	require.Empty(t, config.Notify.Webhooks)
+	require.False(t, config.Sharding.Enabled)
+	require.Equal(t, defaultShardGroup, config.Sharding.Group)
+	require.Equal(t, defaultLeaseNamespace, config.Sharding.LeaseNamespace)
+	require.Equal(t, defaultLeaseDuration, config.Sharding.LeaseDuration)
+	require.Equal(t, defaultRenewInterval, config.Sharding.RenewInterval)
+	require.Equal(t, defaultVirtualNodes, config.Sharding.VirtualNodes)
+	require.Equal(t, AuditConfig{
+		Output:     defaultAuditOutput,
+		Path:       defaultAuditPath,
+		MaxSizeMB:  defaultAuditMaxSizeMB,
+		MaxBackups: defaultAuditMaxBackups,
+		HashChain:  true,
+	}, config.Audit)
+	require.Equal(t, SnapshotConfig{
+		Store:     defaultSnapshotStore,
+		Directory: defaultSnapshotDirectory,
+		Namespace: defaultSnapshotNamespace,
+		Retention: defaultSnapshotRetention,
+	}, config.Snapshots)
+	require.Equal(t, LogArchiveConfig{
+		Directory: defaultLogArchiveDir,
+		TailLines: defaultLogTailLines,
+		MaxBytes:  defaultLogMaxBytes,
+		Previous:  true,
+		Timeout:   defaultLogTimeout,
+		Retention: defaultLogRetention,
+	}, config.LogArchive)
+	require.Equal(t, PauseConfig{
+		ConfigMap: PauseConfigMapConfig{Namespace: defaultPauseNamespace, Name: defaultPauseConfigMap},
+	}, config.Pause)
+}
+
+func TestLoad(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "watchdog.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`
+watchdog:
+  scheduleInterval: 1m
+logging:
+  level: debug
+`), 0o600))
+
+	config, err := Load(cfgPath)
+	require.NoError(t, err)
+	require.Equal(t, cfgPath, config.Path())
+	require.Equal(t, time.Minute, config.Watchdog.ScheduleInterval)
+	require.Equal(t, "debug", config.Logging.Level)
+	require.Equal(t, defaultMaxPodLifetime, config.Watchdog.MaxPodLifetime, "defaults apply")
+
+	// Every load starts from the defaults, nothing is left over from a previous file
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`watchdog: {}`), 0o600))
+	config, err = Load(cfgPath)
+	require.NoError(t, err)
+	require.Equal(t, defaultScheduleInterval, config.Watchdog.ScheduleInterval)
+	require.Equal(t, defaultLogLevel, config.Logging.Level)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
 }
@@ -9,6 +9,7 @@
 //   - HttpConfig: HTTP server settings (address, timeouts)
 //   - WatchdogConfig: Pod monitoring settings (namespaces, selectors, intervals, limits)
 //   - LoggingConfig: Logging settings (mode, level)
+//   - ShardingConfig: Namespace sharding across replicas (identity, leases, hashing)
 //
 // The package provides:
 //   - Default configuration values for all settings
@@ -17,7 +18,13 @@
 //   - Integration with the application's dependency injection system
 //
 // Configuration can be loaded from:
-//   - config.yaml in the current directory
-//   - config/config.yaml in the config subdirectory
-//   - Environment variables (with proper prefixes)
+//   - the file given with --config or WATCHDOG_CONFIG, else config.yaml in the
+//     current directory, ./config or /etc/watchdog
+//   - the files it includes and the files of its conf.d directory, merged into
+//     it with conflicts reported by Validate
+//   - WATCHDOG_ environment variables and setting flags, which override the files
+//
+// Any string setting may reference a secret as file:/path, env:VAR or
+// k8s-secret:namespace/name#key, resolved on every load. Referenced values are
+// printed as their reference and scrubbed by Redact.
 package config
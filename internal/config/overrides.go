@@ -0,0 +1,220 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	// EnvConfig names the config file when --config is not given
+	EnvConfig = "WATCHDOG_CONFIG"
+	// EnvPrefix starts the environment variables overriding settings, such as
+	// WATCHDOG_WATCHDOG_DRYRUN for watchdog.dryRun
+	EnvPrefix = "WATCHDOG_"
+)
+
+// Source is where the configuration comes from. Settings are taken, from the
+// highest precedence down, from the command-line flags, the WATCHDOG_ environment
+// variables, the config file and the defaults.
+type Source struct {
+	// Path is the config file given with --config, WATCHDOG_CONFIG is used when
+	// it is empty, and then config.yaml is looked up in the working directory,
+	// ./config and /etc/watchdog
+	Path string
+	// Flags holds the settings given on the command line by YAML path
+	Flags map[string]string
+	// Secrets reads the Kubernetes Secrets of k8s-secret references, which fail
+	// to resolve without it
+	Secrets SecretReader
+	// Unresolved leaves the secret references as they are, for the offline
+	// commands that do not use the secrets, such as watchdog plan
+	Unresolved bool
+}
+
+// setting is a configurable value of the Config struct
+type setting struct {
+	// path holds the keys leading to the setting, such as [watchdog dryRun]
+	path []string
+	typ  reflect.Type
+}
+
+// name returns the YAML path of the setting, which also names its flag
+func (s setting) name() string {
+	return strings.Join(s.path, ".")
+}
+
+// env returns the environment variable overriding the setting
+func (s setting) env() string {
+	return EnvPrefix + strings.ToUpper(strings.Join(s.path, "_"))
+}
+
+// key returns the viper key of the setting. The keys are joined with the "::"
+// delimiter, label keys and other map keys may contain dots.
+func (s setting) key() string {
+	return strings.Join(s.path, "::")
+}
+
+// settings lists every setting of the configuration, lists and maps are single
+// settings
+func settings() []setting {
+	var all []setting
+	var walk func(typ reflect.Type, path []string)
+	walk = func(typ reflect.Type, path []string) {
+		if typ.Kind() != reflect.Struct || typ == reflect.TypeFor[time.Duration]() {
+			all = append(all, setting{path: path, typ: typ})
+			return
+		}
+		for i := range typ.NumField() {
+			field := typ.Field(i)
+			if name := settingName(field); name != "" {
+				walk(field.Type, append(append([]string(nil), path...), name))
+			}
+		}
+	}
+	walk(reflect.TypeFor[Config](), nil)
+	return all
+}
+
+// applyOverrides sets the settings given in the environment and on the command
+// line, it returns the flag or environment variable of each setting overridden
+func (s Source) applyOverrides(v *viper.Viper) (map[string]string, error) {
+	known := map[string]bool{}
+	overridden := map[string]string{}
+	for _, setting := range settings() {
+		known[setting.name()] = true
+
+		raw, set := s.Flags[setting.name()]
+		origin := "--" + setting.name()
+		if !set {
+			origin = setting.env()
+			raw, set = os.LookupEnv(origin)
+		}
+		if !set {
+			continue
+		}
+		value, err := parseOverride(setting.typ, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", origin, err)
+		}
+		v.Set(setting.key(), value)
+		overridden[setting.name()] = origin
+	}
+
+	for name := range s.Flags {
+		if !known[name] {
+			return nil, fmt.Errorf("--%s: unknown setting", name)
+		}
+	}
+	return overridden, nil
+}
+
+// parseOverride converts the text of a flag or environment variable. Lists are
+// comma separated or YAML, maps are k=v pairs or YAML, and lists of objects,
+// such as webhooks, are YAML. Scalars are decoded like the config file.
+func parseOverride(typ reflect.Type, raw string) (any, error) {
+	trimmed := strings.TrimSpace(raw)
+	switch typ.Kind() {
+	case reflect.Slice:
+		if strings.HasPrefix(trimmed, "[") || typ.Elem().Kind() == reflect.Struct {
+			var list []any
+			if err := yaml.Unmarshal([]byte(trimmed), &list); err != nil {
+				return nil, fmt.Errorf("expected a YAML list: %w", err)
+			}
+			return list, nil
+		}
+		if trimmed == "" {
+			return []string{}, nil
+		}
+		items := strings.Split(trimmed, ",")
+		for i := range items {
+			items[i] = strings.TrimSpace(items[i])
+		}
+		return items, nil
+	case reflect.Map:
+		if strings.HasPrefix(trimmed, "{") {
+			var m map[string]any
+			if err := yaml.Unmarshal([]byte(trimmed), &m); err != nil {
+				return nil, fmt.Errorf("expected a YAML map: %w", err)
+			}
+			return m, nil
+		}
+		m := map[string]any{}
+		if trimmed == "" {
+			return m, nil
+		}
+		for _, pair := range strings.Split(trimmed, ",") {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("expected key=value pairs, got %q", pair)
+			}
+			m[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+		return m, nil
+	}
+	return raw, nil
+}
+
+// RegisterFlags adds --config and a flag per setting to the flag set, the
+// flags given are recorded in the source
+func (s *Source) RegisterFlags(flags *flag.FlagSet) {
+	flags.StringVar(&s.Path, "config", "", "config file, defaults to $"+EnvConfig+" or config.yaml in ., ./config or /etc/watchdog")
+
+	all := settings()
+	sort.Slice(all, func(i, j int) bool { return all[i].name() < all[j].name() })
+	for _, setting := range all {
+		flags.Var(&settingFlag{source: s, setting: setting}, setting.name(),
+			fmt.Sprintf("overrides %s (%s), also $%s", setting.name(), typeName(setting.typ), setting.env()))
+	}
+}
+
+// settingFlag records a setting given on the command line
+type settingFlag struct {
+	source  *Source
+	setting setting
+}
+
+func (f *settingFlag) String() string {
+	if f.source == nil {
+		return ""
+	}
+	return f.source.Flags[f.setting.name()]
+}
+
+func (f *settingFlag) Set(value string) error {
+	if _, err := parseOverride(f.setting.typ, value); err != nil {
+		return err
+	}
+	if f.source.Flags == nil {
+		f.source.Flags = map[string]string{}
+	}
+	f.source.Flags[f.setting.name()] = value
+	return nil
+}
+
+// IsBoolFlag lets boolean settings be given as --watchdog.dryRun
+func (f *settingFlag) IsBoolFlag() bool {
+	return f.setting.typ.Kind() == reflect.Bool
+}
+
+// typeName describes the value of a setting in the flag usage
+func typeName(typ reflect.Type) string {
+	switch {
+	case typ == reflect.TypeFor[time.Duration]():
+		return "duration"
+	case typ.Kind() == reflect.Map:
+		return "k=v,..."
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Struct:
+		return "YAML list"
+	case typ.Kind() == reflect.Slice:
+		return "a,b,..."
+	}
+	return typ.Kind().String()
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceOverrides(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+watchdog:
+  namespaces: [default]
+  scheduleInterval: 5m
+  maxPodLifetime: 2h
+http:
+  addr: :9090
+`), 0o600))
+
+	t.Run("environment variables override the file", func(t *testing.T) {
+		t.Setenv("WATCHDOG_WATCHDOG_SCHEDULEINTERVAL", "1m")
+		t.Setenv("WATCHDOG_WATCHDOG_DRYRUN", "true")
+		t.Setenv("WATCHDOG_WATCHDOG_NAMESPACES", "sandboxes, ci")
+		t.Setenv("WATCHDOG_WATCHDOG_LABELSELECTORS", "app.kubernetes.io/name=sandbox,tier=ci")
+		cfg, err := NewConfig(Source{Path: path})
+		require.NoError(t, err)
+		require.Equal(t, time.Minute, cfg.Watchdog.ScheduleInterval)
+		require.Equal(t, 2*time.Hour, cfg.Watchdog.MaxPodLifetime, "the file still applies")
+		require.True(t, cfg.Watchdog.DryRun)
+		require.Equal(t, []string{"sandboxes", "ci"}, cfg.Watchdog.Namespaces)
+		require.Equal(t, map[string]string{"app.kubernetes.io/name": "sandbox", "tier": "ci"}, cfg.Watchdog.LabelSelectors)
+	})
+
+	t.Run("flags override the environment", func(t *testing.T) {
+		t.Setenv("WATCHDOG_HTTP_ADDR", ":8081")
+		var src Source
+		flags := flag.NewFlagSet("watchdog", flag.ContinueOnError)
+		src.RegisterFlags(flags)
+		require.NoError(t, flags.Parse([]string{
+			"--config", path,
+			"--http.addr=:8082",
+			"--watchdog.dryRun",
+			"--notifications.webhooks", `[{name: chat, url: "https://chat.example.com/hook"}]`,
+		}))
+
+		cfg, err := NewConfig(src)
+		require.NoError(t, err)
+		require.Equal(t, ":8082", cfg.HTTP.Addr)
+		require.True(t, cfg.Watchdog.DryRun)
+		require.Equal(t, "https://chat.example.com/hook", cfg.Notify.Webhooks[0].URL)
+		require.Equal(t, path, cfg.Source().Path)
+		require.Equal(t, src.Flags, cfg.Source().Flags, "reloads keep the flags")
+	})
+
+	t.Run("WATCHDOG_CONFIG names the file", func(t *testing.T) {
+		t.Setenv(EnvConfig, path)
+		cfg, err := NewConfig(Source{})
+		require.NoError(t, err)
+		require.Equal(t, path, cfg.Path())
+		require.Equal(t, 5*time.Minute, cfg.Watchdog.ScheduleInterval)
+	})
+
+	t.Run("bad values name their origin", func(t *testing.T) {
+		t.Setenv("WATCHDOG_WATCHDOG_LABELSELECTORS", "app")
+		_, err := NewConfig(Source{Path: path})
+		require.EqualError(t, err, `WATCHDOG_WATCHDOG_LABELSELECTORS: expected key=value pairs, got "app"`)
+	})
+
+	t.Run("bad flags are rejected", func(t *testing.T) {
+		_, err := NewConfig(Source{Path: path, Flags: map[string]string{"watchdog.dryrun": "true"}})
+		require.EqualError(t, err, "--watchdog.dryrun: unknown setting")
+
+		flags := flag.NewFlagSet("watchdog", flag.ContinueOnError)
+		flags.SetOutput(io.Discard)
+		(&Source{}).RegisterFlags(flags)
+		require.Error(t, flags.Parse([]string{"--watchdog.labelSelectors=app"}))
+	})
+
+	t.Run("overridden values are validated", func(t *testing.T) {
+		t.Setenv("WATCHDOG_WATCHDOG_SCHEDULEINTERVAL", "0s")
+		_, err := NewConfig(Source{Path: path})
+		require.EqualError(t, err, "invalid configuration: watchdog.scheduleInterval: must be positive, got 0s")
+	})
+}
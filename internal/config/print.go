@@ -0,0 +1,119 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// redacted replaces the values of fields tagged `redact:"true"` when printing,
+// and secrets in text scrubbed by Redact
+const redacted = "<redacted>"
+
+// YAML renders the effective configuration, defaults included, with the keys
+// of the config file. Secrets such as webhook signing keys are redacted, secret
+// references are printed instead of their value, and each setting given is
+// commented with the file, flag or environment variable it comes from.
+func (c *Config) YAML() ([]byte, error) {
+	node, err := c.settingsNode(reflect.ValueOf(*c), "", false)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// settingsNode renders the configuration value of the setting at path as a YAML node
+func (c *Config) settingsNode(value reflect.Value, path string, redact bool) (*yaml.Node, error) {
+	if duration, ok := value.Interface().(time.Duration); ok {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: duration.String()}, nil
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for i := range value.NumField() {
+			field := value.Type().Field(i)
+			name := settingName(field)
+			if name == "" {
+				continue
+			}
+			child, err := c.settingsNode(value.Field(i), joinPath(path, name), field.Tag.Get("redact") == "true")
+			if err != nil {
+				return nil, err
+			}
+			keyNode := originComment(&yaml.Node{Kind: yaml.ScalarNode, Value: name}, child, c.origins[joinPath(path, name)])
+			node.Content = append(node.Content, keyNode, child)
+		}
+		return node, nil
+	case reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		if value.Len() == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		keys := value.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+		for _, key := range keys {
+			entry := fmt.Sprintf("%s[%q]", path, key.String())
+			child, err := c.settingsNode(value.MapIndex(key), entry, redact)
+			if err != nil {
+				return nil, err
+			}
+			keyNode := originComment(&yaml.Node{Kind: yaml.ScalarNode, Value: key.String()}, child, c.origins[entry])
+			node.Content = append(node.Content, keyNode, child)
+		}
+		return node, nil
+	case reflect.Slice:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		if value.Len() == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		for i := range value.Len() {
+			child, err := c.settingsNode(value.Index(i), fmt.Sprintf("%s[%d]", path, i), redact)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	}
+
+	if reference, ok := c.references[path]; ok {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: reference}, nil
+	}
+	if redact && !value.IsZero() {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: redacted}, nil
+	}
+	node := &yaml.Node{}
+	if err := node.Encode(value.Interface()); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// originComment comments the key of a setting with its origin, on the value
+// when it is printed on the same line
+func originComment(key, value *yaml.Node, origin string) *yaml.Node {
+	if origin == "" {
+		return key
+	}
+	if value.Kind == yaml.ScalarNode || value.Style == yaml.FlowStyle {
+		value.LineComment = origin
+	} else {
+		key.LineComment = origin
+	}
+	return key
+}
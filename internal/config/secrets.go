@@ -0,0 +1,194 @@
+package config
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// RefFile references the content of a file, relative to the config file
+	RefFile = "file:"
+	// RefEnv references an environment variable
+	RefEnv = "env:"
+	// RefK8sSecret references a key of a Kubernetes Secret as namespace/name#key
+	RefK8sSecret = "k8s-secret:"
+
+	// secretTimeout bounds reading a Kubernetes Secret
+	secretTimeout = 10 * time.Second
+	// minRedactLength is the length below which secret values are not scrubbed
+	// from text, as they would match unrelated words
+	minRedactLength = 8
+)
+
+// SecretReader reads a key of a Kubernetes Secret, for k8s-secret references
+type SecretReader func(ctx context.Context, namespace, name, key string) ([]byte, error)
+
+// resolveSecrets replaces the secret references of the string settings with the
+// values they reference, and collects the values to redact: the referenced ones
+// and those of the fields tagged `redact:"true"`. An unresolved source only
+// records the references.
+func (s Source) resolveSecrets(cfg *Config) error {
+	cfg.references = map[string]string{}
+	cfg.secrets = nil
+	if err := s.resolveValue(cfg, reflect.ValueOf(cfg).Elem(), "", false); err != nil {
+		return err
+	}
+	// Longer secrets first, one may contain another
+	slices.SortFunc(cfg.secrets, func(a, b string) int { return cmp.Or(len(b)-len(a), strings.Compare(a, b)) })
+	cfg.secrets = slices.Compact(cfg.secrets)
+	return nil
+}
+
+func (s Source) resolveValue(cfg *Config, value reflect.Value, path string, redact bool) error {
+	switch value.Kind() {
+	case reflect.Struct:
+		for i := range value.NumField() {
+			field := value.Type().Field(i)
+			name := settingName(field)
+			if name == "" {
+				continue
+			}
+			if err := s.resolveValue(cfg, value.Field(i), joinPath(path, name), redact || field.Tag.Get("redact") == "true"); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := value.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+		for _, key := range keys {
+			// Map values are not addressable, so they are resolved in a copy
+			entry := reflect.New(value.Type().Elem()).Elem()
+			entry.Set(value.MapIndex(key))
+			if err := s.resolveValue(cfg, entry, fmt.Sprintf("%s[%q]", path, key.String()), redact); err != nil {
+				return err
+			}
+			value.SetMapIndex(key, entry)
+		}
+	case reflect.Slice:
+		for i := range value.Len() {
+			if err := s.resolveValue(cfg, value.Index(i), fmt.Sprintf("%s[%d]", path, i), redact); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		text := value.String()
+		if isReference(text) && s.Unresolved {
+			if err := checkReference(text); err != nil {
+				return fmt.Errorf("%s: invalid reference %s: %w", path, text, err)
+			}
+			cfg.references[path] = text
+			return nil
+		}
+		if isReference(text) {
+			resolved, err := s.resolve(cfg, text)
+			if err != nil {
+				return fmt.Errorf("%s: failed to resolve %s: %w", path, text, err)
+			}
+			value.SetString(resolved)
+			cfg.references[path] = text
+			redact = true
+		}
+		if redact && value.String() != "" {
+			cfg.secrets = append(cfg.secrets, value.String())
+		}
+	}
+	return nil
+}
+
+// isReference tells whether a setting references a secret
+func isReference(text string) bool {
+	return strings.HasPrefix(text, RefFile) || strings.HasPrefix(text, RefEnv) || strings.HasPrefix(text, RefK8sSecret)
+}
+
+// checkReference checks the syntax of a secret reference without resolving it
+func checkReference(reference string) error {
+	switch {
+	case strings.HasPrefix(reference, RefFile):
+		if reference == RefFile {
+			return errors.New("expected a file path")
+		}
+	case strings.HasPrefix(reference, RefEnv):
+		if reference == RefEnv {
+			return errors.New("expected an environment variable name")
+		}
+	default:
+		_, _, _, err := splitK8sSecret(reference)
+		return err
+	}
+	return nil
+}
+
+// splitK8sSecret returns the namespace, name and key of a k8s-secret reference
+func splitK8sSecret(reference string) (namespace, name, key string, err error) {
+	namespace, rest, _ := strings.Cut(strings.TrimPrefix(reference, RefK8sSecret), "/")
+	name, key, _ = strings.Cut(rest, "#")
+	if namespace == "" || name == "" || key == "" {
+		return "", "", "", fmt.Errorf("expected %snamespace/name#key", RefK8sSecret)
+	}
+	return namespace, name, key, nil
+}
+
+// resolve returns the value a secret reference points to
+func (s Source) resolve(cfg *Config, reference string) (string, error) {
+	switch {
+	case strings.HasPrefix(reference, RefFile):
+		path := strings.TrimPrefix(reference, RefFile)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(cfg.path), path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		// Files written by editors and kubectl end with a newline
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case strings.HasPrefix(reference, RefEnv):
+		name := strings.TrimPrefix(reference, RefEnv)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return value, nil
+	default:
+		namespace, name, key, err := splitK8sSecret(reference)
+		if err != nil {
+			return "", err
+		}
+		if s.Secrets == nil {
+			return "", errors.New("no Kubernetes client to read Secrets")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+		defer cancel()
+		data, err := s.Secrets(ctx, namespace, name, key)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
+
+// unresolved tells whether a setting still holds the secret reference it was
+// given, the checks of its format do not apply then
+func (c *Config) unresolved(path string) bool {
+	_, ok := c.references[path]
+	return ok && c.source.Unresolved
+}
+
+// Redact scrubs the secret values of the configuration from the text, such as
+// a webhook URL embedded in a delivery error
+func (c *Config) Redact(text string) string {
+	for _, secret := range c.secrets {
+		if len(secret) >= minRedactLength {
+			text = strings.ReplaceAll(text, secret, redacted)
+		}
+	}
+	return text
+}
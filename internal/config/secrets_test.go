@@ -0,0 +1,130 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretReferences(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": `
+watchdog:
+  namespaces: [default]
+notifications:
+  webhooks:
+    - name: chat
+      url: env:CHAT_WEBHOOK_URL
+      secret: file:secrets/hmac-key
+      headers:
+        Authorization: k8s-secret:ops/chat-webhook#token
+telemetry:
+  otlp:
+    endpoint: collector:4317
+`,
+		"secrets/hmac-key": "s3cr3t-hmac-key\n",
+	})
+	t.Setenv("CHAT_WEBHOOK_URL", "https://chat.example.com/hooks/T0KEN-1234")
+	reads := 0
+	src := Source{
+		Path: filepath.Join(dir, "config.yaml"),
+		Secrets: func(_ context.Context, namespace, name, key string) ([]byte, error) {
+			reads++
+			require.Equal(t, []string{"ops", "chat-webhook", "token"}, []string{namespace, name, key})
+			return []byte("Bearer api-token-5678"), nil
+		},
+	}
+
+	cfg, err := NewConfig(src)
+	require.NoError(t, err)
+	webhook := cfg.Notify.Webhooks[0]
+	require.Equal(t, "https://chat.example.com/hooks/T0KEN-1234", webhook.URL)
+	require.Equal(t, "s3cr3t-hmac-key", webhook.Secret, "the trailing newline is trimmed")
+	require.Equal(t, "Bearer api-token-5678", webhook.Headers["authorization"])
+	require.Equal(t, 1, reads)
+
+	t.Run("print shows the references", func(t *testing.T) {
+		data, err := cfg.YAML()
+		require.NoError(t, err)
+		require.Contains(t, string(data), "      url: env:CHAT_WEBHOOK_URL\n")
+		require.Contains(t, string(data), "      secret: file:secrets/hmac-key\n")
+		require.Contains(t, string(data), "        authorization: k8s-secret:ops/chat-webhook#token\n")
+		require.NotContains(t, string(data), "T0KEN")
+		require.NotContains(t, string(data), "s3cr3t")
+	})
+
+	t.Run("redact scrubs the values", func(t *testing.T) {
+		require.Equal(t, `Post "<redacted>": dial tcp: i/o timeout`,
+			cfg.Redact(`Post "https://chat.example.com/hooks/T0KEN-1234": dial tcp: i/o timeout`))
+		require.Equal(t, "signed with <redacted>", cfg.Redact("signed with s3cr3t-hmac-key"))
+		require.Equal(t, "collector:4317", cfg.Redact("collector:4317"), "plain settings are kept")
+	})
+
+	t.Run("validation messages are scrubbed", func(t *testing.T) {
+		t.Setenv("CHAT_WEBHOOK_URL", "chat.example.com/hooks/T0KEN-1234")
+		_, err := NewConfig(src)
+		require.EqualError(t, err, `invalid configuration: notifications.webhooks[0].url: must be an http or https URL, got "<redacted>"`)
+	})
+
+	t.Run("an unresolved source keeps the references", func(t *testing.T) {
+		t.Setenv("CHAT_WEBHOOK_URL", "")
+		offline := Source{Path: src.Path, Unresolved: true}
+		cfg, err := NewConfig(offline)
+		require.NoError(t, err, "references are not checked as URLs")
+		require.Equal(t, "env:CHAT_WEBHOOK_URL", cfg.Notify.Webhooks[0].URL)
+		require.Equal(t, "k8s-secret:ops/chat-webhook#token", cfg.Notify.Webhooks[0].Headers["authorization"])
+		require.Equal(t, "signed with s3cr3t-hmac-key", cfg.Redact("signed with s3cr3t-hmac-key"), "nothing was read to redact")
+		require.True(t, cfg.Source().Unresolved, "reloads stay unresolved")
+	})
+
+	t.Run("reloads resolve again", func(t *testing.T) {
+		t.Setenv("CHAT_WEBHOOK_URL", "https://chat.example.com/hooks/R0TATED")
+		reloaded, err := cfg.Source().Load()
+		require.NoError(t, err)
+		require.Equal(t, "https://chat.example.com/hooks/R0TATED", reloaded.Notify.Webhooks[0].URL)
+		require.Equal(t, 3, reads, "the Secret is read on every load")
+	})
+}
+
+func TestSecretReferenceErrors(t *testing.T) {
+	load := func(t *testing.T, value string, secrets SecretReader) error {
+		t.Helper()
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{"config.yaml": "watchdog:\n  namespaces: [default]\nnotifications:\n  webhooks:\n    - url: " + value + "\n"})
+		_, err := Source{Path: filepath.Join(dir, "config.yaml"), Secrets: secrets}.Load()
+		return err
+	}
+
+	require.EqualError(t, load(t, "env:MISSING_WEBHOOK_URL", nil),
+		"notifications.webhooks[0].url: failed to resolve env:MISSING_WEBHOOK_URL: environment variable MISSING_WEBHOOK_URL is not set")
+	require.ErrorContains(t, load(t, "file:/nonexistent/url", nil),
+		"notifications.webhooks[0].url: failed to resolve file:/nonexistent/url: open /nonexistent/url")
+	require.EqualError(t, load(t, "k8s-secret:ops/chat-webhook", nil),
+		"notifications.webhooks[0].url: failed to resolve k8s-secret:ops/chat-webhook: expected k8s-secret:namespace/name#key")
+	require.EqualError(t, load(t, "k8s-secret:ops/chat-webhook#url", nil),
+		"notifications.webhooks[0].url: failed to resolve k8s-secret:ops/chat-webhook#url: no Kubernetes client to read Secrets")
+	require.EqualError(t, load(t, "k8s-secret:ops/chat-webhook#url", func(context.Context, string, string, string) ([]byte, error) {
+		return nil, errors.New(`secrets "chat-webhook" is forbidden`)
+	}), `notifications.webhooks[0].url: failed to resolve k8s-secret:ops/chat-webhook#url: secrets "chat-webhook" is forbidden`)
+}
+
+func TestUnresolvedReferenceSyntax(t *testing.T) {
+	load := func(t *testing.T, value string) error {
+		t.Helper()
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{"config.yaml": "watchdog:\n  namespaces: [default]\nnotifications:\n  webhooks:\n    - url: " + value + "\n"})
+		_, err := Source{Path: filepath.Join(dir, "config.yaml"), Unresolved: true}.Load()
+		return err
+	}
+
+	require.NoError(t, load(t, "env:MISSING_WEBHOOK_URL"))
+	require.NoError(t, load(t, "file:/nonexistent/url"))
+	require.NoError(t, load(t, "k8s-secret:ops/chat-webhook#url"), "no Kubernetes client is needed")
+	require.EqualError(t, load(t, `"env:"`), "notifications.webhooks[0].url: invalid reference env:: expected an environment variable name")
+	require.EqualError(t, load(t, "k8s-secret:ops/chat-webhook"),
+		"notifications.webhooks[0].url: invalid reference k8s-secret:ops/chat-webhook: expected k8s-secret:namespace/name#key")
+}
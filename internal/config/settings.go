@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// walkSettings compares a YAML node with the type it decodes into and collects
+// the YAML paths of the settings that match no configuration field, such as a
+// misspelled key. Keys match fields regardless of case, the way they are decoded.
+func walkSettings(node *yaml.Node, typ reflect.Type, path string, unknown *[]string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			field, ok := settingField(typ, key)
+			if !ok {
+				*unknown = append(*unknown, joinPath(path, key))
+				continue
+			}
+			walkSettings(value, field.Type, joinPath(path, key), unknown)
+		}
+	case typ.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			walkSettings(node.Content[i+1], typ.Elem(), fmt.Sprintf("%s[%q]", path, node.Content[i].Value), unknown)
+		}
+	case typ.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for i, item := range node.Content {
+			walkSettings(item, typ.Elem(), fmt.Sprintf("%s[%d]", path, i), unknown)
+		}
+	}
+}
+
+// settingField returns the field of the struct decoded from the key
+func settingField(typ reflect.Type, key string) (reflect.StructField, bool) {
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if name := settingName(field); name != "" && strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// settingName returns the key of an exported field, "" for the others
+func settingName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+	return name
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
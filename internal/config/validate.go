@@ -0,0 +1,312 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Problem is a setting the watchdog cannot run with, Path is its YAML path
+type Problem struct {
+	Path    string
+	Message string
+}
+
+func (p Problem) Error() string {
+	return p.Path + ": " + p.Message
+}
+
+// ValidationError lists every problem of a configuration
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.Error()
+	}
+	if len(messages) == 1 {
+		return "invalid configuration: " + messages[0]
+	}
+	return fmt.Sprintf("invalid configuration, %d problems: %s", len(messages), strings.Join(messages, "; "))
+}
+
+// Validate reports every setting the watchdog cannot run with as a *ValidationError,
+// startup fails and a reloaded configuration is rejected unless it is valid
+func (c *Config) Validate() error {
+	v := &validator{}
+	v.problems = append(v.problems, c.problems...)
+
+	c.validateWatchdog(v)
+	c.validateHTTP(v)
+
+	v.oneOf("logging.mode", c.Logging.Mode, "production", "development")
+	if _, err := zapcore.ParseLevel(c.Logging.Level); err != nil {
+		v.addf("logging.level", "%v", err)
+	}
+
+	v.notNegative("health.checkTimeout", c.Health.CheckTimeout)
+	v.notNegative("health.stallTimeout", c.Health.StallTimeout)
+	v.notNegative("health.maxCycleAge", c.Health.MaxCycleAge)
+
+	if c.Sharding.Enabled {
+		v.required("sharding.group", c.Sharding.Group)
+		v.required("sharding.leaseNamespace", c.Sharding.LeaseNamespace)
+		v.positive("sharding.renewInterval", c.Sharding.RenewInterval)
+		if c.Sharding.RenewInterval >= c.Sharding.LeaseDuration {
+			v.addf("sharding.leaseDuration", "must be longer than renewInterval %s, got %s", c.Sharding.RenewInterval, c.Sharding.LeaseDuration)
+		}
+		if c.Sharding.VirtualNodes < 1 {
+			v.addf("sharding.virtualNodes", "must be at least 1, got %d", c.Sharding.VirtualNodes)
+		}
+	}
+
+	c.validateNotifications(v)
+
+	if c.Audit.Enabled {
+		v.oneOf("audit.output", c.Audit.Output, "stdout", "file")
+		if c.Audit.Output == "file" {
+			v.required("audit.path", c.Audit.Path)
+		}
+	}
+	if c.Snapshots.Enabled {
+		v.oneOf("snapshots.store", c.Snapshots.Store, "directory", "configmap", "secret")
+		v.notNegative("snapshots.retention", c.Snapshots.Retention)
+	}
+	if c.LogArchive.Enabled {
+		v.required("logArchive.directory", c.LogArchive.Directory)
+		v.notNegative("logArchive.retention", c.LogArchive.Retention)
+	}
+	if c.Pause.ConfigMap.Enabled {
+		v.required("pause.configMap.namespace", c.Pause.ConfigMap.Namespace)
+		v.required("pause.configMap.name", c.Pause.ConfigMap.Name)
+	}
+
+	if c.Metrics.PodExpiry.Enabled && c.Metrics.PodExpiry.MaxSeries < 1 {
+		v.addf("metrics.podExpiry.maxSeries", "must be at least 1, got %d", c.Metrics.PodExpiry.MaxSeries)
+	}
+	if p := c.Metrics.Pushgateway; p.URL != "" {
+		u, err := url.Parse(p.URL)
+		if !c.unresolved("metrics.pushgateway.url") && (err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "") {
+			v.addf("metrics.pushgateway.url", "must be an http or https URL, got %q", p.URL)
+		}
+		v.required("metrics.pushgateway.job", p.Job)
+		v.positive("metrics.pushgateway.timeout", p.Timeout)
+	}
+	if c.Telemetry.Tracing.Enabled || c.Telemetry.Metrics.Enabled {
+		v.oneOf("telemetry.otlp.protocol", c.Telemetry.OTLP.Protocol, "grpc", "http")
+		v.positive("telemetry.otlp.timeout", c.Telemetry.OTLP.Timeout)
+	}
+	if ratio := c.Telemetry.Tracing.SampleRatio; c.Telemetry.Tracing.Enabled && (ratio < 0 || ratio > 1) {
+		v.addf("telemetry.tracing.sampleRatio", "must be between 0 and 1, got %g", ratio)
+	}
+	if c.Telemetry.Metrics.Enabled {
+		v.positive("telemetry.metrics.interval", c.Telemetry.Metrics.Interval)
+	}
+	if c.Reload.Enabled {
+		v.positive("reload.interval", c.Reload.Interval)
+	}
+
+	if len(v.problems) > 0 {
+		// Messages quote values, which may be secrets
+		for i := range v.problems {
+			v.problems[i].Message = c.Redact(v.problems[i].Message)
+		}
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+// validateWatchdog checks the monitoring settings
+func (c *Config) validateWatchdog(v *validator) {
+	w := c.Watchdog
+	v.required("watchdog.policy", w.Policy)
+	if len(w.Namespaces) == 0 {
+		v.addf("watchdog.namespaces", "must list at least one namespace")
+	}
+	for i, namespace := range w.Namespaces {
+		for _, msg := range validation.IsDNS1123Label(namespace) {
+			v.addf(fmt.Sprintf("watchdog.namespaces[%d]", i), "%q is not a namespace name: %s", namespace, msg)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(w.LabelSelectors)) {
+		value := w.LabelSelectors[key]
+		path := fmt.Sprintf("watchdog.labelSelectors[%q]", key)
+		for _, msg := range validation.IsQualifiedName(key) {
+			v.addf(path, "invalid label key: %s", msg)
+		}
+		for _, msg := range validation.IsValidLabelValue(value) {
+			v.addf(path, "invalid label value %q: %s", value, msg)
+		}
+	}
+	if w.TtlLabel != "" {
+		for _, msg := range validation.IsQualifiedName(w.TtlLabel) {
+			v.addf("watchdog.ttlLabel", "invalid label key: %s", msg)
+		}
+	}
+
+	v.positive("watchdog.scheduleInterval", w.ScheduleInterval)
+	v.positive("watchdog.maxPodLifetime", w.MaxPodLifetime)
+	v.notNegative("watchdog.cycleTimeout", w.CycleTimeout)
+
+	v.positive("watchdog.retry.initialBackoff", w.Retry.InitialBackoff)
+	if w.Retry.MaxBackoff < w.Retry.InitialBackoff {
+		v.addf("watchdog.retry.maxBackoff", "must not be shorter than initialBackoff %s, got %s", w.Retry.InitialBackoff, w.Retry.MaxBackoff)
+	}
+	if w.Retry.Factor < 1 {
+		v.addf("watchdog.retry.factor", "must be at least 1, got %g", w.Retry.Factor)
+	}
+	if w.Retry.Jitter < 0 || w.Retry.Jitter > 1 {
+		v.addf("watchdog.retry.jitter", "must be between 0 and 1, got %g", w.Retry.Jitter)
+	}
+	if w.Retry.MaxAttempts < 1 {
+		v.addf("watchdog.retry.maxAttempts", "must be at least 1, got %d", w.Retry.MaxAttempts)
+	}
+
+	if w.Events.QPS < 0 {
+		v.addf("watchdog.events.qps", "must not be negative, got %g", w.Events.QPS)
+	}
+	if w.Events.Burst < 0 {
+		v.addf("watchdog.events.burst", "must not be negative, got %d", w.Events.Burst)
+	}
+
+	if w.Warning.Enabled {
+		v.positive("watchdog.warning.leadTime", w.Warning.LeadTime)
+		if w.Warning.HTTP.Enabled {
+			if w.Warning.HTTP.Port < 1 || w.Warning.HTTP.Port > 65535 {
+				v.addf("watchdog.warning.http.port", "must be a port number, got %d", w.Warning.HTTP.Port)
+			}
+			if !strings.HasPrefix(w.Warning.HTTP.Path, "/") {
+				v.addf("watchdog.warning.http.path", "must start with /, got %q", w.Warning.HTTP.Path)
+			}
+			v.positive("watchdog.warning.http.timeout", w.Warning.HTTP.Timeout)
+		}
+	}
+
+	if w.Extensions.Enabled {
+		if w.TtlLabel == "" {
+			v.addf("watchdog.extensions.enabled", "needs watchdog.ttlLabel, extensions are recorded in it")
+		}
+		v.notNegative("watchdog.extensions.maxLifetime", w.Extensions.MaxLifetime)
+		if w.Extensions.MaxExtensions < 0 {
+			v.addf("watchdog.extensions.maxExtensions", "must not be negative, got %d", w.Extensions.MaxExtensions)
+		}
+	}
+}
+
+// validateHTTP checks the listeners and their authentication
+func (c *Config) validateHTTP(v *validator) {
+	h := c.HTTP
+	v.required("http.addr", h.Addr)
+	v.notNegative("http.readTimeout", h.ReadTimeout)
+	v.notNegative("http.writeTimeout", h.WriteTimeout)
+	v.notNegative("http.shutdownDelay", h.ShutdownDelay)
+	validateTLS(v, "http.tls", h.TLS)
+	listeners := []struct {
+		path     string
+		listener ListenerConfig
+	}{{"http.probes", h.Probes}, {"http.metrics", h.Metrics}}
+	for _, l := range listeners {
+		path, listener := l.path, l.listener
+		if listener.Addr != "" && listener.Addr == h.Addr {
+			v.addf(path+".addr", "must differ from http.addr %q", h.Addr)
+		}
+		v.notNegative(path+".readTimeout", listener.ReadTimeout)
+		v.notNegative(path+".writeTimeout", listener.WriteTimeout)
+		validateTLS(v, path+".tls", listener.TLS)
+	}
+	if h.Probes.Addr != "" && h.Probes.Addr == h.Metrics.Addr {
+		v.addf("http.metrics.addr", "must differ from http.probes.addr %q", h.Probes.Addr)
+	}
+
+	for i, token := range h.Auth.Tokens {
+		path := fmt.Sprintf("http.auth.tokens[%d]", i)
+		v.required(path+".user", token.User)
+		v.required(path+".file", token.File)
+	}
+	if h.Auth.Kubernetes.Enabled {
+		v.notNegative("http.auth.kubernetes.cacheTTL", h.Auth.Kubernetes.CacheTTL)
+	}
+}
+
+// validateTLS checks the TLS settings of a listener
+func validateTLS(v *validator, path string, tls TLSConfig) {
+	if !tls.Enabled() && tls.ClientCAFile == "" && tls.ClientAuth == "" {
+		return
+	}
+	v.required(path+".certFile", tls.CertFile)
+	v.required(path+".keyFile", tls.KeyFile)
+	if tls.ClientAuth != "" {
+		v.oneOf(path+".clientAuth", tls.ClientAuth, "require", "optional")
+	}
+}
+
+// validateNotifications checks the notification queue and webhooks
+func (c *Config) validateNotifications(v *validator) {
+	n := c.Notify
+	if len(n.Webhooks) == 0 {
+		return
+	}
+	if n.QueueSize < 1 {
+		v.addf("notifications.queueSize", "must be at least 1, got %d", n.QueueSize)
+	}
+	if n.Workers < 1 {
+		v.addf("notifications.workers", "must be at least 1, got %d", n.Workers)
+	}
+	for i, webhook := range n.Webhooks {
+		path := fmt.Sprintf("notifications.webhooks[%d]", i)
+		u, err := url.Parse(webhook.URL)
+		if !c.unresolved(path+".url") && (err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "") {
+			v.addf(path+".url", "must be an http or https URL, got %q", webhook.URL)
+		}
+		for j, event := range webhook.Events {
+			v.oneOf(fmt.Sprintf("%s.events[%d]", path, j), event, "warned", "expired", "terminated", "failed")
+		}
+		v.notNegative(path+".timeout", webhook.Timeout)
+		v.notNegative(path+".backoff", webhook.Backoff)
+		if webhook.MaxAttempts < 0 {
+			v.addf(path+".maxAttempts", "must not be negative, got %d", webhook.MaxAttempts)
+		}
+	}
+}
+
+// validator collects the problems of a configuration
+type validator struct {
+	problems []Problem
+}
+
+func (v *validator) addf(path, format string, args ...any) {
+	v.problems = append(v.problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) required(path, value string) {
+	if value == "" {
+		v.addf(path, "must be set")
+	}
+}
+
+func (v *validator) positive(path string, d time.Duration) {
+	if d <= 0 {
+		v.addf(path, "must be positive, got %s", d)
+	}
+}
+
+func (v *validator) notNegative(path string, d time.Duration) {
+	if d < 0 {
+		v.addf(path, "must not be negative, got %s", d)
+	}
+}
+
+func (v *validator) oneOf(path, value string, allowed ...string) {
+	if slices.Contains(allowed, value) {
+		return
+	}
+	v.addf(path, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// loadConfig loads the config file content without validating it
+func loadConfig(t *testing.T, content string) *Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	return cfg
+}
+
+// problems returns the problems reported by Validate
+func problems(t *testing.T, cfg *Config) []Problem {
+	t.Helper()
+	var validationErr *ValidationError
+	require.True(t, errors.As(cfg.Validate(), &validationErr))
+	return validationErr.Problems
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *Config {
+		return loadConfig(t, "watchdog:\n  namespaces: [default]\n")
+	}
+	require.NoError(t, valid().Validate(), "the defaults are valid")
+
+	t.Run("reports every problem with its path", func(t *testing.T) {
+		cfg := valid()
+		cfg.Watchdog.Namespaces = nil
+		cfg.Watchdog.ScheduleInterval = 0
+		cfg.Watchdog.CycleTimeout = -time.Second
+		cfg.Logging.Level = "loud"
+		require.Equal(t, []Problem{
+			{Path: "watchdog.namespaces", Message: "must list at least one namespace"},
+			{Path: "watchdog.scheduleInterval", Message: "must be positive, got 0s"},
+			{Path: "watchdog.cycleTimeout", Message: "must not be negative, got -1s"},
+			{Path: "logging.level", Message: `unrecognized level: "loud"`},
+		}, problems(t, cfg))
+		require.EqualError(t, cfg.Validate(), "invalid configuration, 4 problems: "+
+			"watchdog.namespaces: must list at least one namespace; "+
+			"watchdog.scheduleInterval: must be positive, got 0s; "+
+			"watchdog.cycleTimeout: must not be negative, got -1s; "+
+			`logging.level: unrecognized level: "loud"`)
+	})
+
+	t.Run("checks names and labels", func(t *testing.T) {
+		cfg := valid()
+		cfg.Watchdog.Namespaces = []string{"default", "Not_A_Namespace"}
+		cfg.Watchdog.LabelSelectors = map[string]string{"app.kubernetes.io/name": "sandbox", "bad key": "x"}
+		cfg.Watchdog.TtlLabel = "sandbox.kill_time"
+		paths := map[string]bool{}
+		for _, problem := range problems(t, cfg) {
+			paths[problem.Path] = true
+		}
+		require.Equal(t, map[string]bool{`watchdog.namespaces[1]`: true, `watchdog.labelSelectors["bad key"]`: true}, paths)
+	})
+
+	t.Run("checks enabled sections only", func(t *testing.T) {
+		cfg := valid()
+		cfg.Audit.Output = "syslog"
+		cfg.Telemetry.OTLP.Protocol = "thrift"
+		require.NoError(t, cfg.Validate())
+
+		cfg.Audit.Enabled = true
+		cfg.Telemetry.Tracing.Enabled = true
+		require.Equal(t, []Problem{
+			{Path: "audit.output", Message: `must be one of stdout, file, got "syslog"`},
+			{Path: "telemetry.otlp.protocol", Message: `must be one of grpc, http, got "thrift"`},
+		}, problems(t, cfg))
+	})
+
+	t.Run("checks the listeners", func(t *testing.T) {
+		cfg := valid()
+		cfg.HTTP.Metrics.Addr = cfg.HTTP.Addr
+		cfg.HTTP.Probes.TLS.CertFile = "/tls.crt"
+		cfg.HTTP.Auth.Tokens = []TokenConfig{{User: "ops"}}
+		require.Equal(t, []Problem{
+			{Path: "http.probes.tls.keyFile", Message: "must be set"},
+			{Path: "http.metrics.addr", Message: `must differ from http.addr ":8080"`},
+			{Path: "http.auth.tokens[0].file", Message: "must be set"},
+		}, problems(t, cfg))
+	})
+
+	t.Run("checks the webhooks", func(t *testing.T) {
+		cfg := valid()
+		cfg.Notify.Webhooks = []WebhookConfig{
+			{Name: "chat", URL: "https://chat.example.com/hook", Events: []string{"terminated"}},
+			{Name: "tickets", URL: "tickets.example.com", Events: []string{"deleted"}},
+		}
+		require.Equal(t, []Problem{
+			{Path: "notifications.webhooks[1].url", Message: `must be an http or https URL, got "tickets.example.com"`},
+			{Path: "notifications.webhooks[1].events[0]", Message: `must be one of warned, expired, terminated, failed, got "deleted"`},
+		}, problems(t, cfg))
+	})
+
+	t.Run("checks sharding", func(t *testing.T) {
+		cfg := valid()
+		cfg.Sharding.Enabled = true
+		cfg.Sharding.RenewInterval = time.Minute
+		require.Equal(t, []Problem{
+			{Path: "sharding.leaseDuration", Message: "must be longer than renewInterval 1m0s, got 30s"},
+		}, problems(t, cfg))
+	})
+
+	t.Run("checks the pushgateway", func(t *testing.T) {
+		cfg := valid()
+		cfg.Metrics.Pushgateway.Job = ""
+		require.NoError(t, cfg.Validate(), "nothing is pushed without a URL")
+
+		cfg.Metrics.Pushgateway.URL = "pushgateway:9091"
+		require.Equal(t, []Problem{
+			{Path: "metrics.pushgateway.url", Message: `must be an http or https URL, got "pushgateway:9091"`},
+			{Path: "metrics.pushgateway.job", Message: "must be set"},
+		}, problems(t, cfg))
+	})
+
+	t.Run("checks the reload interval", func(t *testing.T) {
+		cfg := valid()
+		cfg.Reload.Interval = 0
+		require.Equal(t, []Problem{{Path: "reload.interval", Message: "must be positive, got 0s"}}, problems(t, cfg))
+	})
+}
+
+func TestUnknownSettings(t *testing.T) {
+	cfg := loadConfig(t, `
+watchdog:
+  namespaces: [default]
+  sheduleInterval: 5m
+  MaxPodLifetime: 2h
+  labelSelectors:
+    app.kubernetes.io/name: sandbox
+notifications:
+  webhooks:
+    - url: https://chat.example.com/hook
+      secrt: s3cr3t
+telemetry:
+  otlp:
+    headers:
+      x-any-header: value
+metric:
+  enabled: true
+`)
+	require.Equal(t, 2*time.Hour, cfg.Watchdog.MaxPodLifetime, "keys match regardless of case")
+	require.Equal(t, []Problem{
+		{Path: "watchdog.sheduleInterval", Message: "unknown setting"},
+		{Path: "notifications.webhooks[0].secrt", Message: "unknown setting"},
+		{Path: "metric", Message: "unknown setting"},
+	}, problems(t, cfg))
+}
+
+func TestYAML(t *testing.T) {
+	cfg := loadConfig(t, `
+watchdog:
+  namespaces: [default, sandboxes]
+  labelSelectors:
+    app.kubernetes.io/name: sandbox
+  scheduleInterval: 90s
+notifications:
+  webhooks:
+    - name: chat
+      url: https://chat.example.com/hook
+      secret: s3cr3t
+      headers:
+        Authorization: Bearer token
+`)
+	data, err := cfg.YAML()
+	require.NoError(t, err)
+	require.Contains(t, string(data), "  scheduleInterval: 1m30s # config.yaml\n", "with the file it comes from")
+	require.Contains(t, string(data), "  maxPodLifetime: 1h0m0s\n", "defaults are printed")
+	require.Contains(t, string(data), "      secret: <redacted>\n")
+	require.Contains(t, string(data), "        authorization: <redacted>\n")
+	require.NotContains(t, string(data), "s3cr3t")
+	require.NotContains(t, string(data), "Bearer token")
+
+	// The printed configuration loads back into the same one
+	printed := loadConfig(t, string(data))
+	require.Empty(t, printed.problems)
+	require.Equal(t, cfg.Watchdog, printed.Watchdog)
+	again, err := printed.YAML()
+	require.NoError(t, err)
+	// Every printed setting now comes from the file
+	uncommented := func(data []byte) string { return strings.ReplaceAll(string(data), " # config.yaml", "") }
+	require.Equal(t, uncommented(data), uncommented(again))
+}
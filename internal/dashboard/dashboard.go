@@ -0,0 +1,51 @@
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/server"
+)
+
+// contentSecurityPolicy keeps the dashboard on its own assets and API
+const contentSecurityPolicy = "default-src 'self'; frame-ancestors 'none'"
+
+//go:embed static
+var static embed.FS
+
+var _ server.Routes = (*Dashboard)(nil)
+
+// Dashboard serves the embedded web dashboard under /dashboard/
+type Dashboard struct {
+	enabled bool
+	files   fs.FS
+}
+
+// NewDashboard creates a new dashboard
+func NewDashboard(cfg *config.Config) (*Dashboard, error) {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		return nil, err
+	}
+	return &Dashboard{
+		enabled: cfg.HTTP.Dashboard.Enabled,
+		files:   files,
+	}, nil
+}
+
+// RegisterRoutes registers the dashboard and redirects / to it
+func (d *Dashboard) RegisterRoutes(mux *http.ServeMux) {
+	if !d.enabled {
+		return
+	}
+
+	files := http.StripPrefix("/dashboard/", http.FileServerFS(d.files))
+	mux.HandleFunc("GET /dashboard/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", contentSecurityPolicy)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		files.ServeHTTP(w, r)
+	})
+	mux.Handle("GET /{$}", http.RedirectHandler("/dashboard/", http.StatusFound))
+}
@@ -0,0 +1,80 @@
+package dashboard
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+func newTestMux(t *testing.T, enabled bool) *http.ServeMux {
+	t.Helper()
+	d, err := NewDashboard(&config.Config{HTTP: config.HTTPConfig{Dashboard: config.DashboardConfig{Enabled: enabled}}})
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	d.RegisterRoutes(mux)
+	return mux
+}
+
+func get(mux *http.ServeMux, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+	return recorder
+}
+
+func TestDashboard(t *testing.T) {
+	mux := newTestMux(t, true)
+
+	t.Run("serves the page", func(t *testing.T) {
+		recorder := get(mux, "/dashboard/")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Contains(t, recorder.Header().Get("Content-Type"), "text/html")
+		require.Equal(t, contentSecurityPolicy, recorder.Header().Get("Content-Security-Policy"))
+		require.Contains(t, recorder.Body.String(), `<script src="app.js"></script>`)
+	})
+
+	t.Run("serves the assets", func(t *testing.T) {
+		for path, contentType := range map[string]string{
+			"/dashboard/app.js":    "javascript",
+			"/dashboard/style.css": "text/css",
+		} {
+			recorder := get(mux, path)
+			require.Equal(t, http.StatusOK, recorder.Code, path)
+			require.Contains(t, recorder.Header().Get("Content-Type"), contentType, path)
+		}
+		require.Equal(t, http.StatusNotFound, get(mux, "/dashboard/missing.js").Code)
+	})
+
+	t.Run("redirects the root", func(t *testing.T) {
+		recorder := get(mux, "/")
+		require.Equal(t, http.StatusFound, recorder.Code)
+		require.Equal(t, "/dashboard/", recorder.Header().Get("Location"))
+		require.Equal(t, http.StatusNotFound, get(mux, "/other").Code)
+	})
+}
+
+func TestDashboardDisabled(t *testing.T) {
+	mux := newTestMux(t, false)
+	require.Equal(t, http.StatusNotFound, get(mux, "/dashboard/").Code)
+	require.Equal(t, http.StatusNotFound, get(mux, "/").Code)
+}
+
+func TestDashboardIsSelfContained(t *testing.T) {
+	err := fs.WalkDir(static, "static", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(static, path)
+		require.NoError(t, err)
+		for _, external := range []string{"http://", "https://", "//cdn", "@import"} {
+			require.NotContains(t, strings.ToLower(string(data)), external, path)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,11 @@
+// Package dashboard serves the watchdog's web dashboard on the HTTP server.
+//
+// Not everyone who owns pods watched by the watchdog can reach Prometheus, so the
+// dashboard shows the watched namespaces and policy, upcoming expirations with
+// live countdowns, recent terminations and the run history. When available it
+// also offers the pause and extend actions.
+//
+// The page, styles and script are embedded in the binary and only talk to the
+// JSON API of the same server, so the dashboard works without internet access.
+// It is served under /dashboard/, and / redirects to it.
+package dashboard
@@ -0,0 +1,370 @@
+"use strict";
+
+// The dashboard is served under /dashboard/, the API sits next to it
+const API = "../api/v1";
+const REFRESH_INTERVAL = 15000;
+const MAX_TERMINATIONS = 50;
+const TERMINATION_ACTIONS = new Set(["terminated", "dry_run", "paused", "failed"]);
+// The bearer token lives for the browser tab only
+const TOKEN_KEY = "watchdog-token";
+
+const state = {
+  status: null,
+  candidates: [],
+  runs: [],
+};
+
+const $ = (id) => document.getElementById(id);
+
+// el creates an element with text content, never HTML, so pod names cannot inject markup
+function el(tag, text, className) {
+  const node = document.createElement(tag);
+  if (text !== undefined && text !== null) {
+    node.textContent = String(text);
+  }
+  if (className) {
+    node.className = className;
+  }
+  return node;
+}
+
+function row(cells) {
+  const tr = document.createElement("tr");
+  for (const cell of cells) {
+    if (cell instanceof Node) {
+      const td = document.createElement("td");
+      td.appendChild(cell);
+      tr.appendChild(td);
+    } else {
+      tr.appendChild(el("td", cell));
+    }
+  }
+  return tr;
+}
+
+async function request(method, path, body) {
+  const options = { method, headers: {} };
+  if (body !== undefined) {
+    options.headers["Content-Type"] = "application/json";
+    options.body = JSON.stringify(body);
+  }
+  const token = sessionStorage.getItem(TOKEN_KEY);
+  if (token) {
+    options.headers["Authorization"] = "Bearer " + token;
+  }
+  const response = await fetch(API + path, options);
+  const data = await response.json().catch(() => ({}));
+  if (response.status === 401) {
+    showSignIn(true);
+  }
+  if (!response.ok) {
+    throw new Error(data.error || response.status + " " + response.statusText);
+  }
+  return data;
+}
+
+function showSignIn(signedOut) {
+  $("token-form").hidden = !signedOut;
+  $("sign-out").hidden = signedOut || !sessionStorage.getItem(TOKEN_KEY);
+}
+
+async function signIn(event) {
+  event.preventDefault();
+  const form = event.target;
+  sessionStorage.setItem(TOKEN_KEY, form.token.value.trim());
+  form.reset();
+  showSignIn(false);
+  await refresh();
+}
+
+function signOut() {
+  sessionStorage.removeItem(TOKEN_KEY);
+  showSignIn(true);
+}
+
+function showError(err) {
+  const node = $("error");
+  if (err) {
+    node.textContent = err.message || String(err);
+    node.hidden = false;
+  } else {
+    node.hidden = true;
+  }
+}
+
+function formatTime(value) {
+  if (!value) {
+    return "";
+  }
+  return new Date(value).toLocaleString();
+}
+
+function formatDuration(seconds) {
+  const sign = seconds < 0 ? "-" : "";
+  let rest = Math.abs(Math.round(seconds));
+  const days = Math.floor(rest / 86400);
+  rest %= 86400;
+  const hours = Math.floor(rest / 3600);
+  rest %= 3600;
+  const minutes = Math.floor(rest / 60);
+  const secs = rest % 60;
+  const parts = [];
+  if (days) parts.push(days + "d");
+  if (days || hours) parts.push(hours + "h");
+  if (days || hours || minutes) parts.push(minutes + "m");
+  parts.push(secs + "s");
+  return sign + parts.join(" ");
+}
+
+function renderStatus() {
+  const status = state.status;
+  $("policy").textContent = "policy " + status.policy;
+  $("dry-run").hidden = !status.dryRun;
+
+  const namespaces = $("namespaces");
+  namespaces.replaceChildren();
+  const owned = new Set(status.ownedNamespaces || []);
+  for (const namespace of status.namespaces || []) {
+    const tag = el("li", namespace, "badge" + (owned.has(namespace) ? " ok" : ""));
+    if (!owned.has(namespace)) {
+      tag.title = "handled by another replica";
+    }
+    namespaces.appendChild(tag);
+  }
+
+  const selectors = Object.entries(status.labelSelectors || {}).map(([key, value]) => key + "=" + value);
+  $("selectors").textContent = "Pods matching " + (selectors.length ? selectors.join(", ") : "any labels") +
+    ", checked every " + status.scheduleInterval + ".";
+
+  let limits = "Maximum lifetime " + status.maxPodLifetime;
+  if (status.ttlLabel) {
+    limits += ", or the time in the " + status.ttlLabel + " label";
+  }
+  if (status.extensions.enabled) {
+    limits += ". Pods can be extended " + status.extensions.maxExtensions +
+      " times, up to a lifetime of " + status.extensions.maxLifetime;
+  }
+  $("limits").textContent = limits + ".";
+
+  renderPause(status.pause);
+}
+
+function renderPause(pause) {
+  const badge = $("pause-state");
+  const pauses = pause.pauses || [];
+  if (pause.paused) {
+    badge.textContent = "paused";
+    badge.className = "badge bad";
+  } else if (pauses.length) {
+    badge.textContent = "partly paused";
+    badge.className = "badge warn";
+  } else {
+    badge.textContent = "active";
+    badge.className = "badge ok";
+  }
+
+  const list = $("pauses");
+  list.replaceChildren();
+  for (const p of pauses) {
+    const item = el("li");
+    const scope = p.policy === "*" ? "All policies" : "Policy " + p.policy;
+    let text = scope + " paused via " + p.source;
+    if (p.by) text += " by " + p.by;
+    if (p.reason) text += ": " + p.reason;
+    if (p.until) text += " (until " + formatTime(p.until) + ")";
+    item.appendChild(el("span", text + " "));
+    if (p.source === "api") {
+      const button = el("button", "Resume", "secondary");
+      button.type = "button";
+      button.addEventListener("click", () => resume(p.policy));
+      item.appendChild(button);
+    } else {
+      item.appendChild(el("span", "edit the ConfigMap to resume", "muted"));
+    }
+    list.appendChild(item);
+  }
+}
+
+function renderCandidates() {
+  const showExpired = $("show-expired").checked;
+  const extensions = state.status && state.status.extensions.enabled;
+  const body = $("candidates");
+  body.replaceChildren();
+
+  const now = Date.now();
+  const candidates = state.candidates.filter((c) => c.verdict === "active" || (showExpired && c.verdict === "expired"));
+  for (const candidate of candidates) {
+    const remaining = (new Date(candidate.deadline).getTime() - now) / 1000;
+    const countdown = el("span", formatDuration(remaining));
+    countdown.dataset.deadline = candidate.deadline;
+
+    let action = "";
+    if (extensions && candidate.verdict === "active") {
+      action = el("button", "Extend", "secondary");
+      action.type = "button";
+      action.addEventListener("click", () => extend(candidate));
+    }
+
+    const tr = row([
+      candidate.namespace,
+      candidate.name,
+      candidate.owner || "",
+      formatTime(candidate.deadline),
+      countdown,
+      candidate.reason,
+      action,
+    ]);
+    tr.children[4].className = remaining < 0 ? "overdue" : remaining < 900 ? "soon" : "";
+    body.appendChild(tr);
+  }
+  $("candidates-empty").hidden = candidates.length > 0;
+}
+
+// tick updates the countdowns between refreshes
+function tick() {
+  const now = Date.now();
+  for (const node of document.querySelectorAll("[data-deadline]")) {
+    const remaining = (new Date(node.dataset.deadline).getTime() - now) / 1000;
+    node.textContent = formatDuration(remaining);
+    node.parentElement.className = remaining < 0 ? "overdue" : remaining < 900 ? "soon" : "";
+  }
+}
+
+function renderTerminations() {
+  const body = $("terminations");
+  body.replaceChildren();
+
+  let count = 0;
+  for (const run of state.runs) {
+    const results = (run.report && run.report.results) || [];
+    for (const result of results) {
+      if (!TERMINATION_ACTIONS.has(result.action) || count >= MAX_TERMINATIONS) {
+        continue;
+      }
+      const action = el("span", result.action, "badge" + (result.action === "failed" ? " bad" : result.action === "terminated" ? "" : " warn"));
+      body.appendChild(row([
+        formatTime(run.finishedAt || run.startedAt),
+        result.namespace,
+        result.name,
+        action,
+        result.reason || "",
+        result.error || "",
+      ]));
+      count++;
+    }
+  }
+  $("terminations-empty").hidden = count > 0;
+}
+
+function renderRuns() {
+  const body = $("runs");
+  body.replaceChildren();
+
+  for (const run of state.runs) {
+    const report = run.report || {};
+    const actions = Object.entries(report.actions || {}).map(([action, count]) => action + " " + count).join(", ");
+    let duration = "";
+    if (run.startedAt && run.finishedAt) {
+      duration = formatDuration((new Date(run.finishedAt) - new Date(run.startedAt)) / 1000);
+    }
+    const statusBadge = el("span", run.status, "badge" + (run.status === "failed" ? " bad" : run.status === "succeeded" ? " ok" : " warn"));
+    if (run.error) {
+      statusBadge.title = run.error;
+    }
+    body.appendChild(row([
+      formatTime(run.startedAt || run.queuedAt),
+      run.trigger,
+      statusBadge,
+      duration,
+      report.examined ?? "",
+      actions,
+      el("code", run.id.slice(0, 8)),
+    ]));
+  }
+  $("runs-empty").hidden = state.runs.length > 0;
+}
+
+async function refresh() {
+  try {
+    const [status, candidates, runs] = await Promise.all([
+      request("GET", "/status"),
+      request("GET", "/candidates"),
+      request("GET", "/runs"),
+    ]);
+    state.status = status;
+    state.candidates = candidates.candidates || [];
+    state.runs = runs.runs || [];
+
+    renderStatus();
+    renderCandidates();
+    renderTerminations();
+    renderRuns();
+    $("updated").textContent = "updated " + new Date().toLocaleTimeString();
+    showError(null);
+  } catch (err) {
+    showError(err);
+  }
+}
+
+async function pause(event) {
+  event.preventDefault();
+  const form = event.target;
+  const body = { by: form.by.value, reason: form.reason.value };
+  if (form.duration.value) {
+    body.duration = form.duration.value;
+  }
+  try {
+    await request("POST", "/pause", body);
+    form.hidden = true;
+    form.reset();
+    await refresh();
+  } catch (err) {
+    showError(err);
+  }
+}
+
+async function resume(policy) {
+  const by = window.prompt("Resume terminations. Your name:");
+  if (by === null) {
+    return;
+  }
+  try {
+    await request("POST", "/resume", { policy: policy === "*" ? "" : policy, by });
+    await refresh();
+  } catch (err) {
+    showError(err);
+  }
+}
+
+async function extend(candidate) {
+  const duration = window.prompt("Extend " + candidate.namespace + "/" + candidate.name + " by (e.g. 1h):", "1h");
+  if (!duration) {
+    return;
+  }
+  const by = window.prompt("Your name:") || "";
+  try {
+    const extension = await request("POST", "/pods/" + encodeURIComponent(candidate.namespace) + "/" +
+      encodeURIComponent(candidate.name) + "/extend", { duration, by });
+    window.alert("New deadline " + formatTime(extension.deadline) + ", " +
+      extension.remainingExtensions + " extensions left.");
+    await refresh();
+  } catch (err) {
+    showError(err);
+  }
+}
+
+$("pause-button").addEventListener("click", () => {
+  $("pause-form").hidden = false;
+});
+$("pause-cancel").addEventListener("click", () => {
+  $("pause-form").hidden = true;
+});
+$("pause-form").addEventListener("submit", pause);
+$("token-form").addEventListener("submit", signIn);
+$("sign-out").addEventListener("click", signOut);
+$("show-expired").addEventListener("change", renderCandidates);
+
+showSignIn(false);
+refresh();
+setInterval(refresh, REFRESH_INTERVAL);
+setInterval(tick, 1000);
@@ -0,0 +1,19 @@
+// Package events posts Kubernetes Events about watchdog decisions.
+//
+// Pod terminations otherwise only show up in the watchdog's own logs, so users
+// whose pods disappear have no trace of why. The Recorder observes every
+// decision made by the monitoring package and posts an Event on the pod, and
+// on its controller (ReplicaSet, Job, StatefulSet, ...) when it has one, so the
+// reason stays visible in `kubectl describe` and `kubectl get events` after the
+// pod itself is gone.
+//
+// Events are posted for:
+//   - Terminations, with the expiry reason and deadline
+//   - Dry-run verdicts for pods that would have been terminated
+//   - Failed terminations (Warning)
+//   - Pods whose TTL label cannot be evaluated (Warning)
+//
+// Events go through the client-go broadcaster, whose correlator aggregates
+// similar events and rate limits them per object. Recording can be disabled in
+// the watchdog configuration.
+package events
@@ -0,0 +1,139 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+// Component is the event source reported on every watchdog Event
+const Component = "watchdog"
+
+// Event reasons posted by the watchdog
+const (
+	ReasonInvalidTTL        = "WatchdogInvalidTTL"
+	ReasonDryRun            = "WatchdogDryRun"
+	ReasonPaused            = "WatchdogPaused"
+	ReasonTerminated        = "WatchdogTerminated"
+	ReasonTerminationFailed = "WatchdogTerminationFailed"
+	ReasonExpiryWarning     = "WatchdogExpiryWarning"
+)
+
+var _ monitoring.Observer = (*Recorder)(nil)
+
+// Recorder posts Kubernetes Events about watchdog decisions on the pod and its owner
+type Recorder struct {
+	clientset   kubernetes.Interface
+	config      config.EventsConfig
+	broadcaster record.EventBroadcaster
+	recorder    record.EventRecorder
+	logger      *zap.SugaredLogger
+}
+
+// NewRecorder creates a new event recorder
+func NewRecorder(lc fx.Lifecycle, clientset kubernetes.Interface, cfg *config.Config, logger *zap.SugaredLogger) *Recorder {
+	broadcaster := record.NewBroadcaster(record.WithCorrelatorOptions(record.CorrelatorOptions{
+		QPS:       cfg.Watchdog.Events.QPS,
+		BurstSize: cfg.Watchdog.Events.Burst,
+	}))
+
+	r := &Recorder{
+		clientset:   clientset,
+		config:      cfg.Watchdog.Events,
+		broadcaster: broadcaster,
+		recorder:    broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: Component}),
+		logger:      logger.Named("EventRecorder"),
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: r.Start,
+		OnStop:  r.Shutdown,
+	})
+
+	return r
+}
+
+// Start begins sending recorded events to the API server
+func (r *Recorder) Start(_ context.Context) error {
+	if !r.config.Enabled {
+		r.logger.Info("Kubernetes Events are disabled")
+		return nil
+	}
+
+	r.broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: r.clientset.CoreV1().Events("")})
+	return nil
+}
+
+// Shutdown flushes and stops the event broadcaster
+func (r *Recorder) Shutdown(_ context.Context) error {
+	r.broadcaster.Shutdown()
+	return nil
+}
+
+// Observe posts an Event for the decision on the pod, and on its controller when it has one
+func (r *Recorder) Observe(_ context.Context, decision monitoring.Decision) {
+	if !r.config.Enabled {
+		return
+	}
+
+	eventType, reason, message := describe(decision)
+	r.recorder.Event(decision.Pod, eventType, reason, message)
+
+	if owner := ownerReference(decision.Pod); owner != nil {
+		r.recorder.Eventf(owner, eventType, reason, "Pod %s: %s", decision.Pod.Name, message)
+	}
+}
+
+// describe returns the event type, reason and message for a decision
+func describe(decision monitoring.Decision) (eventType, reason, message string) {
+	verdict := decision.Verdict
+	deadline := verdict.Deadline.UTC().Format(time.RFC3339)
+
+	switch decision.Action {
+	case monitoring.ActionTerminated:
+		return v1.EventTypeNormal, ReasonTerminated,
+			fmt.Sprintf("Pod expired (%s, deadline %s) and was terminated by the watchdog", verdict.Reason, deadline)
+	case monitoring.ActionDryRun:
+		return v1.EventTypeNormal, ReasonDryRun,
+			fmt.Sprintf("Pod expired (%s, deadline %s), dry run: it would have been terminated", verdict.Reason, deadline)
+	case monitoring.ActionPaused:
+		return v1.EventTypeNormal, ReasonPaused,
+			fmt.Sprintf("Pod expired (%s, deadline %s), terminations are paused: it will be terminated once resumed", verdict.Reason, deadline)
+	case monitoring.ActionFailed:
+		return v1.EventTypeWarning, ReasonTerminationFailed,
+			fmt.Sprintf("Failed to terminate expired pod (%s, deadline %s): %v", verdict.Reason, deadline, decision.Err)
+	case monitoring.ActionWarned:
+		return v1.EventTypeNormal, ReasonExpiryWarning,
+			fmt.Sprintf("Pod expires at %s (%s) and will then be terminated by the watchdog", deadline, verdict.Reason)
+	default:
+		return v1.EventTypeWarning, ReasonInvalidTTL,
+			fmt.Sprintf("Unable to evaluate pod lifetime: %v", decision.Err)
+	}
+}
+
+// ownerReference returns a reference to the pod's controller, or nil for bare pods
+func ownerReference(pod *v1.Pod) *v1.ObjectReference {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return nil
+	}
+	return &v1.ObjectReference{
+		APIVersion: owner.APIVersion,
+		Kind:       owner.Kind,
+		Name:       owner.Name,
+		Namespace:  pod.Namespace,
+		UID:        owner.UID,
+	}
+}
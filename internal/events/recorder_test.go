@@ -0,0 +1,155 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+func newTestPod(owned bool) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sandbox-1",
+			Namespace: "default",
+			UID:       "pod-uid",
+		},
+	}
+	if owned {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+			Name:       "sandbox",
+			UID:        "job-uid",
+			Controller: &controller,
+		}}
+	}
+	return pod
+}
+
+func newTestRecorder(enabled bool) (*Recorder, *record.FakeRecorder) {
+	fakeRecorder := record.NewFakeRecorder(10)
+	return &Recorder{
+		config:   config.EventsConfig{Enabled: enabled},
+		recorder: fakeRecorder,
+		logger:   zap.NewNop().Sugar(),
+	}, fakeRecorder
+}
+
+func TestNewRecorder(t *testing.T) {
+	cfg := &config.Config{
+		Watchdog: config.WatchdogConfig{
+			Events: config.EventsConfig{Enabled: true, QPS: 1, Burst: 5},
+		},
+	}
+
+	recorder := NewRecorder(fxtest.NewLifecycle(t), fake.NewSimpleClientset(), cfg, zap.NewNop().Sugar())
+	require.NotNil(t, recorder)
+	require.NotNil(t, recorder.recorder)
+
+	require.NoError(t, recorder.Start(context.Background()))
+	require.NoError(t, recorder.Shutdown(context.Background()))
+}
+
+func TestRecorderObserve(t *testing.T) {
+	deadline := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	verdict := monitoring.Verdict{Expired: true, Reason: monitoring.ReasonTTLLabel, Deadline: deadline}
+
+	tests := []struct {
+		name     string
+		decision monitoring.Decision
+		expected string
+	}{
+		{
+			name:     "terminated",
+			decision: monitoring.Decision{Action: monitoring.ActionTerminated, Verdict: verdict},
+			expected: "Normal WatchdogTerminated Pod expired (TTLExpired, deadline 2026-01-02T03:04:05Z) and was terminated by the watchdog",
+		},
+		{
+			name:     "dry run",
+			decision: monitoring.Decision{Action: monitoring.ActionDryRun, Verdict: verdict, DryRun: true},
+			expected: "Normal WatchdogDryRun Pod expired (TTLExpired, deadline 2026-01-02T03:04:05Z), dry run: it would have been terminated",
+		},
+		{
+			name:     "paused",
+			decision: monitoring.Decision{Action: monitoring.ActionPaused, Verdict: verdict},
+			expected: "Normal WatchdogPaused Pod expired (TTLExpired, deadline 2026-01-02T03:04:05Z), terminations are paused: it will be terminated once resumed",
+		},
+		{
+			name:     "failed",
+			decision: monitoring.Decision{Action: monitoring.ActionFailed, Verdict: verdict, Err: errors.New("forbidden")},
+			expected: "Warning WatchdogTerminationFailed Failed to terminate expired pod (TTLExpired, deadline 2026-01-02T03:04:05Z): forbidden",
+		},
+		{
+			name:     "warned",
+			decision: monitoring.Decision{Action: monitoring.ActionWarned, Verdict: monitoring.Verdict{Reason: monitoring.ReasonTTLLabel, Deadline: deadline}},
+			expected: "Normal WatchdogExpiryWarning Pod expires at 2026-01-02T03:04:05Z (TTLExpired) and will then be terminated by the watchdog",
+		},
+		{
+			name:     "invalid",
+			decision: monitoring.Decision{Action: monitoring.ActionInvalid, Err: errors.New("bad label")},
+			expected: "Warning WatchdogInvalidTTL Unable to evaluate pod lifetime: bad label",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder, fakeRecorder := newTestRecorder(true)
+			tt.decision.Pod = newTestPod(false)
+
+			recorder.Observe(context.Background(), tt.decision)
+			require.Len(t, fakeRecorder.Events, 1)
+			require.Equal(t, tt.expected, <-fakeRecorder.Events)
+		})
+	}
+}
+
+func TestRecorderObserveOwner(t *testing.T) {
+	recorder, fakeRecorder := newTestRecorder(true)
+	recorder.Observe(context.Background(), monitoring.Decision{
+		Pod:    newTestPod(true),
+		Action: monitoring.ActionTerminated,
+		Verdict: monitoring.Verdict{
+			Expired:  true,
+			Reason:   monitoring.ReasonMaxLifetime,
+			Deadline: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	})
+
+	require.Len(t, fakeRecorder.Events, 2)
+	<-fakeRecorder.Events
+	require.Equal(t,
+		"Normal WatchdogTerminated Pod sandbox-1: Pod expired (MaxLifetimeExceeded, deadline 2026-01-02T03:04:05Z) "+
+			"and was terminated by the watchdog",
+		<-fakeRecorder.Events)
+}
+
+func TestRecorderDisabled(t *testing.T) {
+	recorder, fakeRecorder := newTestRecorder(false)
+	recorder.Observe(context.Background(), monitoring.Decision{Pod: newTestPod(true), Action: monitoring.ActionTerminated})
+	require.Empty(t, fakeRecorder.Events)
+}
+
+func TestOwnerReference(t *testing.T) {
+	require.Nil(t, ownerReference(newTestPod(false)))
+
+	owner := ownerReference(newTestPod(true))
+	require.Equal(t, &v1.ObjectReference{
+		APIVersion: "batch/v1",
+		Kind:       "Job",
+		Name:       "sandbox",
+		Namespace:  "default",
+		UID:        "job-uid",
+	}, owner)
+}
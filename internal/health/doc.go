@@ -0,0 +1,14 @@
+// Package health provides the registry of liveness and readiness checks.
+//
+// Components implement Checker to register named checks, and the HTTP server
+// runs them on every probe: /healthz runs the liveness checks, /readyz the
+// readiness checks. Checks run concurrently, each bounded by a timeout, and a
+// check that fails, times out or panics fails its probe. With ?verbose, and
+// always on failure, the probes list every check the way the Kubernetes API
+// server does:
+//
+//	[+]apiserver ok
+//	[-]last-cycle failed: no successful cycle for 12m0s, expected within 4m0s
+//
+// The result of each check is exported as watchdog_health_check_status.
+package health
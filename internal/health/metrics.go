@@ -0,0 +1,26 @@
+package health
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// metrics are the metrics of the health registry
+type metrics struct {
+	// checkStatus is 1 when the check passed the last time a probe ran it, 0 when it failed
+	checkStatus *prometheus.GaugeVec
+}
+
+// newMetrics registers the health metrics, a nil registerer leaves them unregistered
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	factory := promauto.With(registerer)
+	return &metrics{
+		checkStatus: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "watchdog_health_check_status",
+				Help: "Result of the last run of a health check by check and kind (liveness, readiness), 1 when it passed",
+			},
+			[]string{"check", "kind"},
+		),
+	}
+}
@@ -0,0 +1,183 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+// Kind tells which probe runs a check
+type Kind string
+
+const (
+	// Liveness checks fail when the process is wedged and must be restarted
+	Liveness Kind = "liveness"
+	// Readiness checks fail while the process cannot do its work
+	Readiness Kind = "readiness"
+)
+
+// defaultCheckTimeout bounds each check when no timeout is configured
+const defaultCheckTimeout = 5 * time.Second
+
+// Check is a named health check, Run returns why it fails
+type Check struct {
+	Name string
+	Kind Kind
+	Run  func(ctx context.Context) error
+}
+
+// Checker is a component providing health checks
+type Checker interface {
+	HealthChecks() []Check
+}
+
+// Result is the outcome of a single check
+type Result struct {
+	Name     string        `json:"name"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Healthy reports whether the check passed
+func (r Result) Healthy() bool {
+	return r.Error == ""
+}
+
+// String formats the result like the Kubernetes API server's verbose probes
+func (r Result) String() string {
+	if r.Healthy() {
+		return "[+]" + r.Name + " ok"
+	}
+	return "[-]" + r.Name + " failed: " + r.Error
+}
+
+// Report is the outcome of every check of a kind
+type Report struct {
+	Healthy bool     `json:"healthy"`
+	Results []Result `json:"results"`
+}
+
+// String lists the results, one per line
+func (r Report) String() string {
+	lines := make([]string, 0, len(r.Results))
+	for _, result := range r.Results {
+		lines = append(lines, result.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Registry holds the checks registered by the components
+type Registry struct {
+	timeout time.Duration
+	metrics *metrics
+
+	mu     sync.RWMutex
+	checks []Check
+}
+
+// NewRegistry creates a registry holding the checks of the checkers, each check
+// run is bounded by health.checkTimeout
+func NewRegistry(cfg *config.Config, registerer prometheus.Registerer, checkers ...Checker) *Registry {
+	r := &Registry{timeout: cfg.Health.CheckTimeout, metrics: newMetrics(registerer)}
+	if r.timeout <= 0 {
+		r.timeout = defaultCheckTimeout
+	}
+	for _, checker := range checkers {
+		r.Register(checker.HealthChecks()...)
+	}
+	return r
+}
+
+// Register adds checks, a check replaces a registered one of the same name and kind
+func (r *Registry) Register(checks ...Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, check := range checks {
+		replaced := false
+		for i, registered := range r.checks {
+			if registered.Name == check.Name && registered.Kind == check.Kind {
+				r.checks[i] = check
+				replaced = true
+			}
+		}
+		if !replaced {
+			r.checks = append(r.checks, check)
+		}
+	}
+}
+
+// Run runs the checks of the kind concurrently and reports them in registration
+// order. A nil registry has no checks.
+func (r *Registry) Run(ctx context.Context, kind Kind) Report {
+	report := Report{Healthy: true}
+	if r == nil {
+		return report
+	}
+
+	r.mu.RLock()
+	checks := make([]Check, 0, len(r.checks))
+	for _, check := range r.checks {
+		if check.Kind == kind {
+			checks = append(checks, check)
+		}
+	}
+	r.mu.RUnlock()
+
+	report.Results = make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Go(func() {
+			report.Results[i] = r.run(ctx, check)
+		})
+	}
+	wg.Wait()
+
+	for _, result := range report.Results {
+		healthy := 0.0
+		if result.Healthy() {
+			healthy = 1
+		} else {
+			report.Healthy = false
+		}
+		r.metrics.checkStatus.WithLabelValues(result.Name, string(kind)).Set(healthy)
+	}
+	return report
+}
+
+// run runs a single check within the timeout, a check that does not return in
+// time fails even when it ignores its context
+func (r *Registry) run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("check panicked: %v", p)
+			}
+		}()
+		done <- check.Run(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("no answer within %s", r.timeout)
+	}
+
+	result := Result{Name: check.Name, Duration: time.Since(start)}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
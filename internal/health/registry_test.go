@@ -0,0 +1,75 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+// checks is a Checker returning fixed checks
+type checks []Check
+
+func (c checks) HealthChecks() []Check {
+	return c
+}
+
+func pass(context.Context) error {
+	return nil
+}
+
+func TestRegistryRun(t *testing.T) {
+	registry := NewRegistry(&config.Config{}, nil, checks{
+		{Name: "slow", Kind: Readiness, Run: func(context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}},
+		{Name: "loop", Kind: Liveness, Run: pass},
+		{Name: "apiserver", Kind: Readiness, Run: pass},
+	})
+
+	report := registry.Run(context.Background(), Readiness)
+	require.True(t, report.Healthy)
+	require.Len(t, report.Results, 2)
+	require.Equal(t, "[+]slow ok\n[+]apiserver ok", report.String())
+
+	registry.Register(Check{Name: "apiserver", Kind: Readiness, Run: func(context.Context) error {
+		return errors.New("connection refused")
+	}})
+	report = registry.Run(context.Background(), Readiness)
+	require.False(t, report.Healthy)
+	require.Equal(t, "[+]slow ok\n[-]apiserver failed: connection refused", report.String())
+
+	report = registry.Run(context.Background(), Liveness)
+	require.True(t, report.Healthy)
+	require.Equal(t, "[+]loop ok", report.String())
+}
+
+func TestRegistryRunFailures(t *testing.T) {
+	registry := NewRegistry(&config.Config{Health: config.HealthConfig{CheckTimeout: 10 * time.Millisecond}}, nil)
+	registry.Register(
+		Check{Name: "stuck", Kind: Liveness, Run: func(context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}},
+		Check{Name: "panics", Kind: Liveness, Run: func(context.Context) error {
+			panic("boom")
+		}},
+	)
+
+	report := registry.Run(context.Background(), Liveness)
+	require.False(t, report.Healthy)
+	require.Equal(t, "no answer within 10ms", report.Results[0].Error)
+	require.Equal(t, "check panicked: boom", report.Results[1].Error)
+}
+
+func TestNilRegistry(t *testing.T) {
+	var registry *Registry
+	report := registry.Run(context.Background(), Readiness)
+	require.True(t, report.Healthy)
+	require.Empty(t, report.Results)
+}
@@ -0,0 +1,283 @@
+package logarchive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+const (
+	// fileTimeLayout names archives so they sort by the time they were taken
+	fileTimeLayout = "20060102T150405Z"
+	// pruneInterval limits how often old archives are pruned
+	pruneInterval = time.Minute
+)
+
+var _ monitoring.Preserver = (*Archiver)(nil)
+
+// Archiver saves the logs of every container of a pod right before the watchdog terminates it
+type Archiver struct {
+	clientset kubernetes.Interface
+	config    config.LogArchiveConfig
+	logger    *zap.SugaredLogger
+	metrics   *metrics
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+// stream is the log of one container instance
+type stream struct {
+	container string
+	previous  bool
+}
+
+// fileName is the name of the stream's log inside the archive
+func (s stream) fileName() string {
+	if s.previous {
+		return s.container + ".previous.log"
+	}
+	return s.container + ".log"
+}
+
+// NewArchiver creates a new log archiver
+func NewArchiver(
+	clientset kubernetes.Interface,
+	cfg *config.Config,
+	logger *zap.SugaredLogger,
+	registerer prometheus.Registerer,
+) *Archiver {
+	return &Archiver{
+		clientset: clientset,
+		config:    cfg.LogArchive,
+		logger:    logger.Named("LogArchiver"),
+		metrics:   newMetrics(registerer),
+	}
+}
+
+// Name identifies the archiver in errors
+func (a *Archiver) Name() string {
+	return "log archive"
+}
+
+// Preserve writes the pod's container logs to a compressed archive. Logs that cannot
+// be fetched are noted in the archive and only hold back the termination when the
+// archive is required.
+func (a *Archiver) Preserve(ctx context.Context, pod *v1.Pod) error {
+	if !a.config.Enabled {
+		return nil
+	}
+
+	if a.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.config.Timeout)
+		defer cancel()
+	}
+
+	now := time.Now().UTC()
+	path, err := a.archive(ctx, pod, now)
+	if err != nil {
+		a.logger.Errorw("Failed to archive pod logs",
+			"namespace", pod.Namespace, "pod", pod.Name, "archive", path, "required", a.config.Required, "error", err)
+		a.metrics.archivesTotal.WithLabelValues(resultFailed).Inc()
+		if a.config.Required {
+			return err
+		}
+	} else {
+		a.logger.Debugw("Archived pod logs", "namespace", pod.Namespace, "pod", pod.Name, "archive", path)
+		a.metrics.archivesTotal.WithLabelValues(resultArchived).Inc()
+	}
+
+	a.prune(now)
+	return nil
+}
+
+// archive writes the logs of every container instance into <directory>/<namespace>/<pod>-<time>.tar.gz.
+// It returns the archive path and the errors of the streams that could not be fetched.
+func (a *Archiver) archive(ctx context.Context, pod *v1.Pod, now time.Time) (string, error) {
+	dir := filepath.Join(a.config.Directory, pod.Namespace)
+	path := filepath.Join(dir, pod.Name+"-"+now.Format(fileTimeLayout)+".tar.gz")
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return path, err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated archive
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return path, err
+	}
+	defer os.Remove(tmp)
+
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+
+	var fetchErrs []error
+	for _, s := range a.streams(pod) {
+		data, err := a.fetch(ctx, pod, s)
+		if err != nil {
+			fetchErrs = append(fetchErrs, fmt.Errorf("%s: %w", s.fileName(), err))
+			data = []byte(fmt.Sprintf("failed to fetch logs: %v\n", err))
+		}
+		if err := writeFile(tw, s.fileName(), data, now); err != nil {
+			_ = file.Close()
+			return path, err
+		}
+	}
+
+	if err := errors.Join(tw.Close(), gz.Close(), file.Sync(), file.Close()); err != nil {
+		return path, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return path, err
+	}
+	return path, errors.Join(fetchErrs...)
+}
+
+// streams lists the container instances to archive, previous instances included for restarted containers
+func (a *Archiver) streams(pod *v1.Pod) []stream {
+	restarted := map[string]bool{}
+	for _, statuses := range [][]v1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, status := range statuses {
+			restarted[status.Name] = status.RestartCount > 0
+		}
+	}
+
+	var streams []stream
+	for _, containers := range [][]v1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			if a.config.Previous && restarted[container.Name] {
+				streams = append(streams, stream{container: container.Name, previous: true})
+			}
+			streams = append(streams, stream{container: container.Name})
+		}
+	}
+	return streams
+}
+
+// fetch reads the tail of a container instance's log
+func (a *Archiver) fetch(ctx context.Context, pod *v1.Pod, s stream) ([]byte, error) {
+	options := &v1.PodLogOptions{
+		Container:  s.container,
+		Previous:   s.previous,
+		Timestamps: true,
+	}
+	if a.config.TailLines > 0 {
+		options.TailLines = &a.config.TailLines
+	}
+
+	reader, err := a.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, options).Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := readTail(reader, a.config.MaxBytes)
+	a.metrics.bytesTotal.Add(float64(len(data)))
+	return data, err
+}
+
+// prune deletes the archives older than the retention, at most once per pruneInterval
+func (a *Archiver) prune(now time.Time) {
+	if a.config.Retention <= 0 {
+		return
+	}
+
+	a.mu.Lock()
+	if now.Sub(a.lastPruned) < pruneInterval {
+		a.mu.Unlock()
+		return
+	}
+	a.lastPruned = now
+	a.mu.Unlock()
+
+	pruned, err := prune(a.config.Directory, now.Add(-a.config.Retention))
+	a.metrics.prunedTotal.Add(float64(pruned))
+	if err != nil {
+		a.logger.Warnw("Failed to prune old log archives", "error", err)
+		return
+	}
+	if pruned > 0 {
+		a.logger.Infow("Pruned old log archives", "count", pruned, "retention", a.config.Retention)
+	}
+}
+
+// readTail reads the whole stream and keeps its last maxBytes bytes, zero keeps everything
+func readTail(r io.Reader, maxBytes int64) ([]byte, error) {
+	if maxBytes <= 0 {
+		return io.ReadAll(r)
+	}
+
+	var data []byte
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		data = append(data, buf[:n]...)
+		if over := int64(len(data)) - maxBytes; over > 0 {
+			data = append(data[:0], data[over:]...)
+		}
+		if errors.Is(err, io.EOF) {
+			return data, nil
+		}
+		if err != nil {
+			return data, err
+		}
+	}
+}
+
+// writeFile adds a file to the tar archive
+func writeFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// prune deletes the archives modified before the given time and returns how many were deleted
+func prune(directory string, before time.Time) (int, error) {
+	pruned := 0
+	err := filepath.WalkDir(directory, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() || filepath.Ext(path) != ".gz" {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().Before(before) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			pruned++
+		}
+		return nil
+	})
+	return pruned, err
+}
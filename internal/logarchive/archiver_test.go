@@ -0,0 +1,147 @@
+package logarchive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+func newTestPod() *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "ci-1", Namespace: "default"},
+		Spec: v1.PodSpec{
+			InitContainers: []v1.Container{{Name: "init"}},
+			Containers:     []v1.Container{{Name: "main"}, {Name: "sidecar"}},
+		},
+		Status: v1.PodStatus{
+			InitContainerStatuses: []v1.ContainerStatus{{Name: "init"}},
+			ContainerStatuses:     []v1.ContainerStatus{{Name: "main", RestartCount: 2}, {Name: "sidecar"}},
+		},
+	}
+}
+
+func newTestArchiver(logArchive config.LogArchiveConfig) *Archiver {
+	return NewArchiver(fake.NewSimpleClientset(), &config.Config{LogArchive: logArchive}, zap.NewNop().Sugar(), nil)
+}
+
+// readArchive returns the files of a tar.gz archive
+func readArchive(t *testing.T, path string) map[string]string {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[header.Name] = string(data)
+	}
+}
+
+func TestArchiverStreams(t *testing.T) {
+	archiver := newTestArchiver(config.LogArchiveConfig{Previous: true})
+	require.Equal(t, []stream{
+		{container: "init"},
+		{container: "main", previous: true},
+		{container: "main"},
+		{container: "sidecar"},
+	}, archiver.streams(newTestPod()))
+
+	archiver = newTestArchiver(config.LogArchiveConfig{})
+	require.Len(t, archiver.streams(newTestPod()), 3)
+}
+
+func TestArchiverPreserve(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, newTestArchiver(config.LogArchiveConfig{Directory: dir}).Preserve(context.Background(), newTestPod()))
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("writes a compressed archive", func(t *testing.T) {
+		dir := t.TempDir()
+		archiver := newTestArchiver(config.LogArchiveConfig{Enabled: true, Directory: dir, TailLines: 10, Previous: true, Timeout: time.Second})
+		require.NoError(t, archiver.Preserve(context.Background(), newTestPod()))
+
+		archives, err := filepath.Glob(filepath.Join(dir, "default", "ci-1-*.tar.gz"))
+		require.NoError(t, err)
+		require.Len(t, archives, 1)
+
+		files := readArchive(t, archives[0])
+		require.Len(t, files, 4)
+		require.Contains(t, files, "main.previous.log")
+		require.Equal(t, "fake logs", files["main.log"])
+	})
+
+	t.Run("failures only block when required", func(t *testing.T) {
+		// A file where the namespace directory should be makes every archive fail
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "default"), nil, 0o600))
+
+		logArchive := config.LogArchiveConfig{Enabled: true, Directory: dir}
+		archiver := newTestArchiver(logArchive)
+		require.NoError(t, archiver.Preserve(context.Background(), newTestPod()))
+		require.InDelta(t, 1, testutil.ToFloat64(archiver.metrics.archivesTotal.WithLabelValues(resultFailed)), 0)
+
+		logArchive.Required = true
+		archiver = newTestArchiver(logArchive)
+		require.Error(t, archiver.Preserve(context.Background(), newTestPod()))
+		require.InDelta(t, 1, testutil.ToFloat64(archiver.metrics.archivesTotal.WithLabelValues(resultFailed)), 0)
+	})
+
+	t.Run("prunes old archives", func(t *testing.T) {
+		dir := t.TempDir()
+		old := filepath.Join(dir, "default", "old-20260101T000000Z.tar.gz")
+		require.NoError(t, os.MkdirAll(filepath.Dir(old), 0o750))
+		require.NoError(t, os.WriteFile(old, nil, 0o600))
+		require.NoError(t, os.Chtimes(old, time.Now().Add(-2*time.Hour), time.Now().Add(-2*time.Hour)))
+
+		archiver := newTestArchiver(config.LogArchiveConfig{Enabled: true, Directory: dir, Retention: time.Hour})
+		require.NoError(t, archiver.Preserve(context.Background(), newTestPod()))
+		require.NoFileExists(t, old)
+
+		archives, err := filepath.Glob(filepath.Join(dir, "default", "ci-1-*.tar.gz"))
+		require.NoError(t, err)
+		require.Len(t, archives, 1)
+	})
+}
+
+func TestReadTail(t *testing.T) {
+	data, err := readTail(strings.NewReader("0123456789"), 4)
+	require.NoError(t, err)
+	require.Equal(t, "6789", string(data))
+
+	data, err = readTail(strings.NewReader("0123456789"), 0)
+	require.NoError(t, err)
+	require.Equal(t, "0123456789", string(data))
+
+	data, err = readTail(io.LimitReader(strings.NewReader(strings.Repeat("x", 100*1024)+"end"), 100*1024+3), 3)
+	require.NoError(t, err)
+	require.Equal(t, "end", string(data))
+}
@@ -0,0 +1,18 @@
+// Package logarchive archives container logs before the watchdog terminates a pod.
+//
+// Once a failed CI pod is deleted its logs are gone, and with them any chance
+// of debugging it. The Archiver runs right before every termination, fetches
+// the tail of each container's log through the pods/log API, including the
+// previous instance of restarted containers, and writes them to a single
+// <directory>/<namespace>/<pod>-<time>.tar.gz archive:
+//
+//	main.log
+//	main.previous.log
+//	init-db.log
+//
+// The tail is bounded by a number of lines, sent to the API server, and a
+// number of bytes, kept on the watchdog side. A log that cannot be fetched is
+// replaced by the error in the archive, logged and counted; it only holds back
+// the termination when archives are required. Archives older than the
+// retention period are pruned.
+package logarchive
@@ -0,0 +1,47 @@
+package logarchive
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	resultArchived = "archived"
+	resultFailed   = "failed"
+)
+
+// metrics are the metrics of the log archiver
+type metrics struct {
+	// archivesTotal counts pod log archives by result, failed archives miss some or all logs
+	archivesTotal *prometheus.CounterVec
+	// bytesTotal counts the container log bytes archived
+	bytesTotal prometheus.Counter
+	// prunedTotal counts log archives deleted after their retention
+	prunedTotal prometheus.Counter
+}
+
+// newMetrics registers the log archive metrics, a nil registerer leaves them unregistered
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	factory := promauto.With(registerer)
+	return &metrics{
+		archivesTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "watchdog_log_archives_total",
+				Help: "Total number of pod log archives written before termination by result (archived, failed)",
+			},
+			[]string{"result"},
+		),
+		bytesTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "watchdog_log_archive_bytes_total",
+				Help: "Total number of container log bytes archived before termination",
+			},
+		),
+		prunedTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "watchdog_log_archives_pruned_total",
+				Help: "Total number of pod log archives deleted after their retention period",
+			},
+		),
+	}
+}
@@ -6,12 +6,17 @@
 //
 // The logging configuration supports:
 //   - Development and production logging modes
-//   - Configurable log levels (debug, info, warn, error, etc.)
+//   - Configurable log levels (debug, info, warn, error, etc.), changed without a
+//     restart when the configuration is reloaded
 //   - Structured logging with key-value pairs for better log analysis
 //   - ISO8601 timestamp formatting
+//   - Secrets of the configuration, such as webhook URLs resolved from secret
+//     references, scrubbed from messages and fields
 //   - Integration with the application's dependency injection system
 //
-// The package provides two main functions:
+// The package provides four main functions:
+//   - NewLevel: Creates the log level, which follows configuration reloads
+//   - NewRedactor: Creates the scrubbing of secrets, which follows configuration reloads
 //   - NewLogger: Creates a configured Zap logger instance based on the application config
 //   - NewSugaredLogger: Wraps the structured logger with a sugared logger for easier use
 //
@@ -7,8 +7,34 @@ import (
 	"github.com/isdmx/watchdog/internal/config"
 )
 
-// NewLogger creates a new zap logger based on the configuration
-func NewLogger(cfg *config.Config) (*zap.Logger, error) {
+// Level is the log level of the logger, which follows the configuration as it
+// is reloaded
+type Level struct {
+	level zap.AtomicLevel
+}
+
+// NewLevel creates the log level of the configuration
+func NewLevel(cfg *config.Config) *Level {
+	return &Level{level: zap.NewAtomicLevelAt(parseLevel(cfg.Logging.Level))}
+}
+
+// Reload switches to the log level of a new configuration
+func (l *Level) Reload(cfg *config.Config) {
+	l.level.SetLevel(parseLevel(cfg.Logging.Level))
+}
+
+// parseLevel parses the configured log level, defaulting to info
+func parseLevel(text string) zapcore.Level {
+	level, err := zapcore.ParseLevel(text)
+	if err != nil {
+		return zap.InfoLevel
+	}
+	return level
+}
+
+// NewLogger creates a new zap logger based on the configuration, logging at the
+// given level with the secrets of the configuration scrubbed
+func NewLogger(cfg *config.Config, level *Level, redactor *Redactor) (*zap.Logger, error) {
 	var loggerConfig zap.Config
 
 	if cfg.Logging.Mode == "development" {
@@ -21,15 +47,9 @@ func NewLogger(cfg *config.Config) (*zap.Logger, error) {
 	loggerConfig.DisableStacktrace = cfg.Logging.Mode == "production"
 	loggerConfig.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 
-	// Set the log level based on configuration
-	level, err := zapcore.ParseLevel(cfg.Logging.Level)
-	if err != nil {
-		// Default to info level if parsing fails
-		level = zap.InfoLevel
-	}
-	loggerConfig.Level = zap.NewAtomicLevelAt(level)
+	loggerConfig.Level = level.level
 
-	logger, err := loggerConfig.Build()
+	logger, err := loggerConfig.Build(zap.WrapCore(redactor.Wrap))
 	if err != nil {
 		return nil, err
 	}
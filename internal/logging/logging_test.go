@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 
 	"github.com/isdmx/watchdog/internal/config"
 )
@@ -17,7 +18,7 @@ func TestNewLogger(t *testing.T) {
 			},
 		}
 
-		logger, err := NewLogger(cfg)
+		logger, err := NewLogger(cfg, NewLevel(cfg), NewRedactor(cfg))
 		require.NoError(t, err)
 		require.NotNil(t, logger)
 		t.Cleanup(func() {
@@ -33,7 +34,7 @@ func TestNewLogger(t *testing.T) {
 			},
 		}
 
-		logger, err := NewLogger(cfg)
+		logger, err := NewLogger(cfg, NewLevel(cfg), NewRedactor(cfg))
 		require.NoError(t, err)
 		require.NotNil(t, logger)
 		t.Cleanup(func() {
@@ -49,7 +50,7 @@ func TestNewLogger(t *testing.T) {
 			},
 		}
 
-		logger, err := NewLogger(cfg)
+		logger, err := NewLogger(cfg, NewLevel(cfg), NewRedactor(cfg))
 		require.NoError(t, err)
 		require.NotNil(t, logger)
 		t.Cleanup(func() {
@@ -70,7 +71,7 @@ func TestNewLogger(t *testing.T) {
 			},
 		}
 
-		logger, err := NewLogger(cfg)
+		logger, err := NewLogger(cfg, NewLevel(cfg), NewRedactor(cfg))
 		require.NoError(t, err)
 		require.NotNil(t, logger)
 		t.Cleanup(func() {
@@ -87,7 +88,7 @@ func TestNewSugaredLogger(t *testing.T) {
 		},
 	}
 
-	logger, err := NewLogger(cfg)
+	logger, err := NewLogger(cfg, NewLevel(cfg), NewRedactor(cfg))
 	require.NoError(t, err)
 	require.NotNil(t, logger)
 	t.Cleanup(func() {
@@ -100,3 +101,14 @@ func TestNewSugaredLogger(t *testing.T) {
 	// Test that sugared logger works
 	sugaredLogger.Info("test message")
 }
+
+func TestLevelReload(t *testing.T) {
+	cfg := &config.Config{Logging: config.LoggingConfig{Mode: "production", Level: "info"}}
+	level := NewLevel(cfg)
+	logger, err := NewLogger(cfg, level, NewRedactor(cfg))
+	require.NoError(t, err)
+	require.False(t, logger.Core().Enabled(zap.DebugLevel))
+
+	level.Reload(&config.Config{Logging: config.LoggingConfig{Level: "debug"}})
+	require.True(t, logger.Core().Enabled(zap.DebugLevel))
+}
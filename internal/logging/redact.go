@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+// Redactor scrubs the secrets of the configuration from the log entries, such
+// as a webhook URL in a delivery error. It follows the configuration as it is
+// reloaded.
+type Redactor struct {
+	config atomic.Pointer[config.Config]
+}
+
+// NewRedactor creates the redactor of the configuration's secrets
+func NewRedactor(cfg *config.Config) *Redactor {
+	r := &Redactor{}
+	r.config.Store(cfg)
+	return r
+}
+
+// Reload scrubs the secrets of a new configuration
+func (r *Redactor) Reload(cfg *config.Config) {
+	r.config.Store(cfg)
+}
+
+// Redact scrubs the secrets from the text
+func (r *Redactor) Redact(text string) string {
+	return r.config.Load().Redact(text)
+}
+
+// Wrap scrubs the secrets from the entries written to the core
+func (r *Redactor) Wrap(core zapcore.Core) zapcore.Core {
+	return &redactingCore{Core: core, redactor: r}
+}
+
+// redactingCore scrubs the message and the text fields of the entries
+type redactingCore struct {
+	zapcore.Core
+	redactor *Redactor
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(c.redactFields(fields)), redactor: c.redactor}
+}
+
+func (c *redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.redactor.Redact(entry.Message)
+	return c.Core.Write(entry, c.redactFields(fields))
+}
+
+// redactFields scrubs the string, error and stringer fields
+func (c *redactingCore) redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		switch field.Type {
+		case zapcore.StringType:
+			field.String = c.redactor.Redact(field.String)
+		case zapcore.ErrorType:
+			if err, ok := field.Interface.(error); ok {
+				field = zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: c.redactor.Redact(err.Error())}
+			}
+		case zapcore.StringerType:
+			if stringer, ok := field.Interface.(fmt.Stringer); ok {
+				field = zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: c.redactor.Redact(stringer.String())}
+			}
+		}
+		redacted[i] = field
+	}
+	return redacted
+}
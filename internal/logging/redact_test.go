@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+// loadConfig loads a config whose webhook URL references the environment
+func loadConfig(t *testing.T, url string) *config.Config {
+	t.Helper()
+	t.Setenv("CHAT_WEBHOOK_URL", url)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("notifications:\n  webhooks:\n    - url: env:CHAT_WEBHOOK_URL\n"), 0o600))
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestRedactor(t *testing.T) {
+	redactor := NewRedactor(loadConfig(t, "https://chat.example.com/hooks/T0KEN-1234"))
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(redactor.Wrap(core)).Sugar().With("webhook", "https://chat.example.com/hooks/T0KEN-1234")
+
+	logger.Errorw("Failed to deliver to https://chat.example.com/hooks/T0KEN-1234",
+		"error", errors.New(`Post "https://chat.example.com/hooks/T0KEN-1234": EOF`), "event", "terminated")
+	entry := logs.All()[0]
+	require.Equal(t, "Failed to deliver to <redacted>", entry.Message)
+	require.Equal(t, map[string]any{
+		"webhook": "<redacted>",
+		"error":   `Post "<redacted>": EOF`,
+		"event":   "terminated",
+	}, entry.ContextMap())
+
+	// A reload scrubs the new secrets
+	redactor.Reload(loadConfig(t, "https://chat.example.com/hooks/R0TATED"))
+	logger.Infow("Rotated", "url", "https://chat.example.com/hooks/R0TATED")
+	require.Equal(t, "<redacted>", logs.All()[1].ContextMap()["url"])
+}
@@ -0,0 +1,9 @@
+// Package metrics provides the Prometheus registry of the watchdog.
+//
+// The components receive the registry as a prometheus.Registerer and register
+// their own metrics with it when they are constructed, instead of using global
+// metrics on the default registry. The HTTP server serves the registry on
+// /metrics, and the telemetry package can push it over OTLP. Tests pass a nil
+// registerer, which leaves the metrics of the component unregistered and
+// private to it.
+package metrics
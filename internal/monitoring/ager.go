@@ -1,6 +1,8 @@
 package monitoring
 
 import (
+	"fmt"
+	"math"
 	"strconv"
 	"time"
 
@@ -14,8 +16,26 @@ import (
 var _ Ager = (*CreationAger)(nil)
 var _ Ager = (*LabeledAger)(nil)
 
+const (
+	// maxKillTime is the latest kill time a TTL label can hold, 9999-12-31T23:59:59Z
+	maxKillTime = 253402300799
+
+	// ReasonMaxLifetime means the pod outlived the configured maximum lifetime
+	ReasonMaxLifetime = "MaxLifetimeExceeded"
+	// ReasonTTLLabel means the kill time in the pod's TTL label has passed
+	ReasonTTLLabel = "TTLExpired"
+)
+
+// Verdict is the outcome of evaluating a pod's age
+type Verdict struct {
+	Expired  bool
+	Reason   string
+	Deadline time.Time
+}
+
 type Ager interface {
 	IsOld(*k8type.Pod) (bool, error)
+	Evaluate(pod *k8type.Pod, now time.Time) (Verdict, error)
 }
 
 type CreationAger struct {
@@ -24,20 +44,30 @@ type CreationAger struct {
 }
 
 func (a *CreationAger) IsOld(pod *k8type.Pod) (bool, error) {
+	verdict, err := a.Evaluate(pod, time.Now())
+	return verdict.Expired, err
+}
+
+func (a *CreationAger) Evaluate(pod *k8type.Pod, now time.Time) (Verdict, error) {
 	logger := a.logger.With("pod", pod.Name, "namespace", pod.Namespace)
 
-	age := time.Since(pod.CreationTimestamp.Time)
+	age := now.Sub(pod.CreationTimestamp.Time)
 	logger.Debugf("Pod %s age: %v, max age: %v", pod.Name, age, a.maxPodLifetime)
 
+	verdict := Verdict{
+		Reason:   ReasonMaxLifetime,
+		Deadline: pod.CreationTimestamp.Add(a.maxPodLifetime),
+	}
 	if age <= a.maxPodLifetime {
-		return false, nil
+		return verdict, nil
 	}
 
 	logger.Infow("Pod exceeds maximum lifetime",
 		"age", age,
 		"maxAge", a.maxPodLifetime,
 	)
-	return true, nil
+	verdict.Expired = true
+	return verdict, nil
 }
 
 func NewCreationAger(maxPodLifetime time.Duration, logger *zap.SugaredLogger) *CreationAger {
@@ -54,32 +84,55 @@ type LabeledAger struct {
 }
 
 func (a *LabeledAger) IsOld(pod *k8type.Pod) (bool, error) {
+	verdict, err := a.Evaluate(pod, time.Now())
+	return verdict.Expired, err
+}
+
+func (a *LabeledAger) Evaluate(pod *k8type.Pod, now time.Time) (Verdict, error) {
 	logger := a.logger.With("pod", pod.Name, "namespace", pod.Namespace)
 
-	age := time.Since(pod.CreationTimestamp.Time)
+	age := now.Sub(pod.CreationTimestamp.Time)
 	logger.Debugf("Pod %s age: %v, max age: %v, labels: %v", pod.Name, age, a.maxPodLifetime, pod.Labels)
 
+	verdict := Verdict{
+		Reason:   ReasonMaxLifetime,
+		Deadline: pod.CreationTimestamp.Add(a.maxPodLifetime),
+	}
 	if a.maxPodLifetime <= age {
 		// Using pod.CreationTimestamp is not desired but good as fallback path
 		logger.Warnw("Terminating pod by creation time", "age", age)
-		return true, nil
+		verdict.Expired = true
+		return verdict, nil
 	}
 
 	killTimeRaw, exists := pod.Labels[a.labelKillTime]
 	if !exists {
 		logger.Warnw("No ttl label in pod")
-		return false, nil
+		return verdict, nil
 	}
 
 	killTime, err := strconv.ParseFloat(killTimeRaw, 64)
 	if err != nil {
-		return false, err
+		return Verdict{}, err
+	}
+	if math.IsInf(killTime, 0) || math.IsNaN(killTime) {
+		return Verdict{}, fmt.Errorf("invalid kill time %q", killTimeRaw)
+	}
+
+	// Clamped, as far off kill times overflow a conversion to nanoseconds
+	sec, frac := math.Modf(min(max(killTime, 0), maxKillTime))
+	deadline := time.Unix(int64(sec), int64(frac*1e9))
+	if deadline.Before(verdict.Deadline) {
+		verdict.Reason = ReasonTTLLabel
+		verdict.Deadline = deadline
 	}
-	if killTime <= float64(time.Now().Unix()) {
+	if killTime <= float64(now.Unix()) {
 		logger.Infow("Killing pod by TTL", "kill_time", killTime)
-		return true, nil
+		verdict.Expired = true
+		verdict.Reason = ReasonTTLLabel
+		return verdict, nil
 	}
-	return false, nil
+	return verdict, nil
 }
 
 func NewLabeledAger(labelKillTime string, maxPodLifetime time.Duration, logger *zap.SugaredLogger) *LabeledAger {
@@ -173,6 +173,90 @@ func TestLabeledAger(t *testing.T) {
 	}
 }
 
+func TestAgerEvaluate(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-time.Hour)
+	newPod := func(labels map[string]string) *k8type.Pod {
+		return &k8type.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "pod",
+				Namespace:         "default",
+				Labels:            labels,
+				CreationTimestamp: metav1.Time{Time: created},
+			},
+		}
+	}
+	ttl := func(t time.Time) map[string]string {
+		return map[string]string{"sandbox.kill_time": strconv.FormatInt(t.Unix(), 10)}
+	}
+
+	tests := []struct {
+		name     string
+		ager     Ager
+		pod      *k8type.Pod
+		expected Verdict
+	}{
+		{
+			name:     "creation alive",
+			ager:     NewCreationAger(2*time.Hour, zap.NewNop().Sugar()),
+			pod:      newPod(nil),
+			expected: Verdict{Reason: ReasonMaxLifetime, Deadline: created.Add(2 * time.Hour)},
+		},
+		{
+			name:     "creation expired",
+			ager:     NewCreationAger(30*time.Minute, zap.NewNop().Sugar()),
+			pod:      newPod(nil),
+			expected: Verdict{Expired: true, Reason: ReasonMaxLifetime, Deadline: created.Add(30 * time.Minute)},
+		},
+		{
+			name:     "label before max lifetime",
+			ager:     NewLabeledAger("sandbox.kill_time", 2*time.Hour, zap.NewNop().Sugar()),
+			pod:      newPod(ttl(now.Add(10 * time.Minute))),
+			expected: Verdict{Reason: ReasonTTLLabel, Deadline: now.Add(10 * time.Minute)},
+		},
+		{
+			name:     "label after max lifetime",
+			ager:     NewLabeledAger("sandbox.kill_time", 2*time.Hour, zap.NewNop().Sugar()),
+			pod:      newPod(ttl(now.Add(5 * time.Hour))),
+			expected: Verdict{Reason: ReasonMaxLifetime, Deadline: created.Add(2 * time.Hour)},
+		},
+		{
+			name:     "label expired",
+			ager:     NewLabeledAger("sandbox.kill_time", 2*time.Hour, zap.NewNop().Sugar()),
+			pod:      newPod(ttl(now.Add(-time.Minute))),
+			expected: Verdict{Expired: true, Reason: ReasonTTLLabel, Deadline: now.Add(-time.Minute)},
+		},
+		{
+			name:     "label beyond year 2262",
+			ager:     NewLabeledAger("sandbox.kill_time", 2*time.Hour, zap.NewNop().Sugar()),
+			pod:      newPod(map[string]string{"sandbox.kill_time": "1e20"}),
+			expected: Verdict{Reason: ReasonMaxLifetime, Deadline: created.Add(2 * time.Hour)},
+		},
+		{
+			name:     "label missing",
+			ager:     NewLabeledAger("sandbox.kill_time", 2*time.Hour, zap.NewNop().Sugar()),
+			pod:      newPod(nil),
+			expected: Verdict{Reason: ReasonMaxLifetime, Deadline: created.Add(2 * time.Hour)},
+		},
+	}
+	for _, killTime := range []string{"Inf", "-Inf", "NaN"} {
+		t.Run("label "+killTime, func(t *testing.T) {
+			ager := NewLabeledAger("sandbox.kill_time", 2*time.Hour, zap.NewNop().Sugar())
+			_, err := ager.Evaluate(newPod(map[string]string{"sandbox.kill_time": killTime}), now)
+			require.EqualError(t, err, fmt.Sprintf("invalid kill time %q", killTime))
+		})
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, err := tt.ager.Evaluate(tt.pod, now)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected.Expired, verdict.Expired)
+			require.Equal(t, tt.expected.Reason, verdict.Reason)
+			require.True(t, tt.expected.Deadline.Equal(verdict.Deadline), "deadline %v, expected %v", verdict.Deadline, tt.expected.Deadline)
+		})
+	}
+}
+
 func TestNewAgerFromConfig(t *testing.T) {
 	emptyRes := NewAgerFromConfig(&config.WatchdogConfig{}, zap.NewNop().Sugar())
 	require.IsType(t, &CreationAger{}, emptyRes)
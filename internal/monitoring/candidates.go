@@ -0,0 +1,160 @@
+package monitoring
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+// CandidateVerdict is the outcome of previewing a pod
+type CandidateVerdict string
+
+const (
+	// VerdictExpired means the pod would be terminated by the next cycle
+	VerdictExpired CandidateVerdict = "expired"
+	// VerdictActive means the pod has not reached its deadline yet
+	VerdictActive CandidateVerdict = "active"
+	// VerdictInvalid means the pod's age could not be evaluated
+	VerdictInvalid CandidateVerdict = "invalid"
+)
+
+// CandidateVerdicts lists every verdict a candidate can have
+var CandidateVerdicts = []CandidateVerdict{VerdictExpired, VerdictActive, VerdictInvalid}
+
+// Candidate is a pod matched by a policy, with what the watchdog would do about it
+type Candidate struct {
+	Namespace        string           `json:"namespace"`
+	Name             string           `json:"name"`
+	UID              string           `json:"uid"`
+	Owner            string           `json:"owner,omitempty"`
+	Policy           string           `json:"policy"`
+	CreatedAt        time.Time        `json:"createdAt"`
+	AgeSeconds       int64            `json:"ageSeconds"`
+	Deadline         time.Time        `json:"deadline,omitzero"`
+	RemainingSeconds int64            `json:"remainingSeconds"`
+	Verdict          CandidateVerdict `json:"verdict"`
+	Reason           string           `json:"reason,omitempty"`
+	Error            string           `json:"error,omitempty"`
+}
+
+// CandidateFilter narrows the candidates, empty fields match everything
+type CandidateFilter struct {
+	Namespace string
+	Policy    string
+	Verdict   CandidateVerdict
+}
+
+// Candidates evaluates every pod matched by the policy without acting on it. All
+// configured namespaces are evaluated, including those owned by other shards.
+// Candidates are sorted by time remaining, the most overdue first.
+func (pm *PodMonitor) Candidates(ctx context.Context, filter CandidateFilter, now time.Time) ([]Candidate, error) {
+	cfg := pm.Config()
+	policy := cfg.Watchdog.Policy
+	if filter.Policy != "" && filter.Policy != policy {
+		return []Candidate{}, nil
+	}
+
+	labelSelector := buildLabelSelector(cfg.Watchdog.LabelSelectors)
+	// A quiet ager keeps previews out of the logs, which the agers write at info level
+	ager := NewAgerFromConfig(&cfg.Watchdog, zap.NewNop().Sugar())
+
+	candidates := []Candidate{}
+	for _, namespace := range cfg.Watchdog.Namespaces {
+		if filter.Namespace != "" && filter.Namespace != namespace {
+			continue
+		}
+
+		var pods *v1.PodList
+		err := pm.retry(ctx, cfg, "list", func(ctx context.Context) error {
+			var err error
+			pods, err = pm.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+				LabelSelector: labelSelector,
+			})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+		}
+
+		for i := range pods.Items {
+			candidate := newCandidate(&pods.Items[i], policy, ager, now)
+			if filter.Verdict == "" || filter.Verdict == candidate.Verdict {
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+
+	sortCandidates(candidates)
+	return candidates, nil
+}
+
+// Plan evaluates pods read from manifests, such as a kubectl get pods dump, the
+// way a cycle of the configuration would at the given time, without a cluster.
+// The pods outside the configured namespaces or not matching the label selectors
+// are left out. Candidates are sorted as those of Candidates.
+func Plan(cfg *config.Config, pods []v1.Pod, now time.Time) ([]Candidate, error) {
+	selector, err := labels.Parse(buildLabelSelector(cfg.Watchdog.LabelSelectors))
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selectors: %w", err)
+	}
+	ager := NewAgerFromConfig(&cfg.Watchdog, zap.NewNop().Sugar())
+
+	candidates := []Candidate{}
+	for i := range pods {
+		pod := &pods[i]
+		if !slices.Contains(cfg.Watchdog.Namespaces, pod.Namespace) || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		candidates = append(candidates, newCandidate(pod, cfg.Watchdog.Policy, ager, now))
+	}
+
+	sortCandidates(candidates)
+	return candidates, nil
+}
+
+// sortCandidates sorts candidates by time remaining, the most overdue first
+func sortCandidates(candidates []Candidate) {
+	slices.SortStableFunc(candidates, func(a, b Candidate) int {
+		return cmp.Compare(a.RemainingSeconds, b.RemainingSeconds)
+	})
+}
+
+// newCandidate evaluates a single pod
+func newCandidate(pod *v1.Pod, policy string, ager Ager, now time.Time) Candidate {
+	candidate := Candidate{
+		Namespace:  pod.Namespace,
+		Name:       pod.Name,
+		UID:        string(pod.UID),
+		Policy:     policy,
+		CreatedAt:  pod.CreationTimestamp.UTC(),
+		AgeSeconds: int64(now.Sub(pod.CreationTimestamp.Time).Seconds()),
+	}
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		candidate.Owner = owner.Kind + "/" + owner.Name
+	}
+
+	verdict, err := ager.Evaluate(pod, now)
+	if err != nil {
+		candidate.Verdict = VerdictInvalid
+		candidate.Error = err.Error()
+		return candidate
+	}
+
+	candidate.Verdict = VerdictActive
+	if verdict.Expired {
+		candidate.Verdict = VerdictExpired
+	}
+	candidate.Reason = verdict.Reason
+	candidate.Deadline = verdict.Deadline.UTC()
+	candidate.RemainingSeconds = int64(verdict.Deadline.Sub(now).Seconds())
+	return candidate
+}
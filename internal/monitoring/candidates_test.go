@@ -0,0 +1,139 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+func TestCandidates(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	newPod := func(namespace, name string, age time.Duration, labels map[string]string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				UID:               types.UID("uid-" + name),
+				Labels:            labels,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			},
+		}
+	}
+	clientset := fake.NewSimpleClientset(
+		newPod("default", "expired", 2*time.Hour, nil),
+		newPod("default", "young", 10*time.Minute, nil),
+		newPod("default", "invalid", time.Minute, map[string]string{"sandbox.kill_time": "soon"}),
+		newPod("other", "overdue", 3*time.Hour, nil),
+		newPod("ignored", "old", 5*time.Hour, nil),
+	)
+	cfg := &config.Config{Watchdog: config.WatchdogConfig{
+		Policy:         "default",
+		Namespaces:     []string{"default", "other"},
+		MaxPodLifetime: time.Hour,
+		TtlLabel:       "sandbox.kill_time",
+	}}
+	// Candidates span every namespace, whichever shard owns it
+	pm := NewPodMonitor(clientset, cfg, &stubSharder{owned: map[string]bool{}}, nil, zap.NewNop().Sugar(), nil, nil, nil)
+
+	names := func(candidates []Candidate) []string {
+		result := make([]string, 0, len(candidates))
+		for _, candidate := range candidates {
+			result = append(result, candidate.Name)
+		}
+		return result
+	}
+
+	t.Run("all candidates, most overdue first", func(t *testing.T) {
+		candidates, err := pm.Candidates(context.Background(), CandidateFilter{}, now)
+		require.NoError(t, err)
+		require.Equal(t, []string{"overdue", "expired", "invalid", "young"}, names(candidates))
+
+		expired := candidates[1]
+		require.Equal(t, VerdictExpired, expired.Verdict)
+		require.Equal(t, ReasonMaxLifetime, expired.Reason)
+		require.Equal(t, "default", expired.Policy)
+		require.Equal(t, int64(7200), expired.AgeSeconds)
+		require.Equal(t, int64(-3600), expired.RemainingSeconds)
+		require.Equal(t, now.Add(-time.Hour), expired.Deadline)
+
+		invalid := candidates[2]
+		require.Equal(t, VerdictInvalid, invalid.Verdict)
+		require.NotEmpty(t, invalid.Error)
+
+		young := candidates[3]
+		require.Equal(t, VerdictActive, young.Verdict)
+		require.Equal(t, int64(3000), young.RemainingSeconds)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		candidates, err := pm.Candidates(context.Background(), CandidateFilter{Namespace: "default", Verdict: VerdictExpired}, now)
+		require.NoError(t, err)
+		require.Equal(t, []string{"expired"}, names(candidates))
+
+		candidates, err = pm.Candidates(context.Background(), CandidateFilter{Namespace: "ignored"}, now)
+		require.NoError(t, err)
+		require.Empty(t, candidates)
+
+		candidates, err = pm.Candidates(context.Background(), CandidateFilter{Policy: "other"}, now)
+		require.NoError(t, err)
+		require.Empty(t, candidates)
+	})
+
+	t.Run("does not act", func(t *testing.T) {
+		pods, err := clientset.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, pods.Items, 3)
+	})
+}
+
+func TestPlan(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	newPod := func(namespace, name string, age time.Duration, labels map[string]string) v1.Pod {
+		return v1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			Labels:            labels,
+			CreationTimestamp: metav1.NewTime(now.Add(-age)),
+		}}
+	}
+	sandbox := map[string]string{"app": "sandbox"}
+	pods := []v1.Pod{
+		newPod("default", "expired", 2*time.Hour, sandbox),
+		newPod("default", "young", 10*time.Minute, sandbox),
+		newPod("default", "labeled", 10*time.Minute, map[string]string{"app": "sandbox", "sandbox.kill_time": "1767355200"}),
+		newPod("default", "unmatched", 5*time.Hour, map[string]string{"app": "web"}),
+		newPod("ignored", "old", 5*time.Hour, sandbox),
+	}
+	cfg := &config.Config{Watchdog: config.WatchdogConfig{
+		Policy:         "sandboxes",
+		Namespaces:     []string{"default"},
+		LabelSelectors: sandbox,
+		MaxPodLifetime: time.Hour,
+		TtlLabel:       "sandbox.kill_time",
+	}}
+
+	candidates, err := Plan(cfg, pods, now)
+	require.NoError(t, err)
+	require.Len(t, candidates, 3, "pods outside the namespaces or the selectors are left out")
+
+	require.Equal(t, "expired", candidates[0].Name)
+	require.Equal(t, VerdictExpired, candidates[0].Verdict)
+	require.Equal(t, ReasonMaxLifetime, candidates[0].Reason)
+	require.Equal(t, "sandboxes", candidates[0].Policy)
+
+	require.Equal(t, "labeled", candidates[1].Name)
+	require.Equal(t, VerdictExpired, candidates[1].Verdict, "the TTL label applies as in a cycle")
+	require.Equal(t, ReasonTTLLabel, candidates[1].Reason)
+
+	require.Equal(t, "young", candidates[2].Name)
+	require.Equal(t, VerdictActive, candidates[2].Verdict)
+}
@@ -0,0 +1,76 @@
+package monitoring
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+// Action is what the watchdog did about a pod
+type Action string
+
+const (
+	// ActionTerminated means the pod was deleted
+	ActionTerminated Action = "terminated"
+	// ActionDryRun means the pod would have been deleted outside of dry-run mode
+	ActionDryRun Action = "dry_run"
+	// ActionFailed means deleting the pod failed
+	ActionFailed Action = "failed"
+	// ActionWarned means the pod was warned about its upcoming expiry
+	ActionWarned Action = "warned"
+	// ActionInvalid means the pod's age could not be evaluated
+	ActionInvalid Action = "invalid"
+	// ActionPaused means the pod expired but terminations are paused
+	ActionPaused Action = "paused"
+)
+
+// Decision describes what the watchdog decided about a single pod
+type Decision struct {
+	CycleID string
+	Policy  string
+	Pod     *v1.Pod
+	Action  Action
+	Verdict Verdict
+	DryRun  bool
+	Err     error
+}
+
+// Observer is notified about every decision the watchdog makes
+type Observer interface {
+	Observe(ctx context.Context, decision Decision)
+}
+
+type cycleIDKey struct{}
+
+// WithCycleID returns a context that carries the id of a monitoring cycle
+func WithCycleID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, cycleIDKey{}, id)
+}
+
+// CycleID returns the id of the monitoring cycle the context belongs to, or ""
+func CycleID(ctx context.Context) string {
+	id, _ := ctx.Value(cycleIDKey{}).(string)
+	return id
+}
+
+// newCycleID generates a unique id for a monitoring cycle
+func newCycleID() string {
+	return string(uuid.NewUUID())
+}
+
+// notify records a decision in the cycle report and passes it to every observer
+func (pm *PodMonitor) notify(ctx context.Context, c *cycle, decision Decision) {
+	decision.CycleID = CycleID(ctx)
+	decision.Policy = c.config.Watchdog.Policy
+	decision.DryRun = c.dryRun
+	c.record(decision)
+	pm.metrics.decisionsTotal.WithLabelValues(decision.Policy, string(decision.Action), decision.Verdict.Reason, pm.shardID()).Inc()
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attrAction.String(string(decision.Action)))
+	failSpan(span, decision.Err)
+	for _, observer := range pm.observers {
+		observer.Observe(ctx, decision)
+	}
+}
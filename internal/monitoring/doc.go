@@ -8,14 +8,22 @@
 // Key features of this package:
 //   - Pod lifetime monitoring based on creation timestamp
 //   - Namespace and label selector filtering for targeted monitoring
+//   - Namespace sharding across replicas through the Sharder interface
 //   - Dry-run mode for safe testing of monitoring policies
+//   - Pausing terminations through the Pauser interface, pods are still evaluated
 //   - Prometheus metrics collection for monitoring operations
+//   - OpenTelemetry spans for each cycle, namespace list, pod evaluation and deletion
+//   - Expiry verdicts with a reason and deadline for every pod
+//   - Advance expiry warnings through an annotation and an optional HTTP notice
 //   - Configurable monitoring intervals and maximum pod lifetimes
 //
 // The package includes:
 //   - PodMonitor: Main monitoring type that handles pod inspection and termination
-//   - Metrics: Prometheus metrics for tracking monitoring operations and pod states
+//   - Metrics: Prometheus metrics for tracking monitoring operations and pod states,
+//     registered with the registry given to NewPodMonitor
 //   - Label selector building for targeted pod queries
+//   - Decision and Observer: Every verdict about a pod is passed to the registered
+//     observers, such as the Kubernetes Events recorder
 //
 // The monitoring logic runs periodically based on the configured schedule interval,
 // examining pods in specified namespaces and terminating those that exceed the
@@ -0,0 +1,171 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+// AnnotationExtensions records the extensions of a pod as a JSON list
+const AnnotationExtensions = "watchdog/extensions"
+
+var (
+	// ErrExtensionsDisabled means extensions are not enabled or no TTL label is configured
+	ErrExtensionsDisabled = errors.New("pod extensions are disabled")
+	// ErrNotManaged means the pod is not matched by the policy
+	ErrNotManaged = errors.New("pod is not managed by the watchdog")
+	// ErrExtensionRefused means the extension would break a limit
+	ErrExtensionRefused = errors.New("extension refused")
+)
+
+// ExtensionRecord is a single extension stored in the pod's annotation
+type ExtensionRecord struct {
+	Time     time.Time `json:"time"`
+	By       string    `json:"by,omitempty"`
+	Duration string    `json:"duration"`
+	Deadline time.Time `json:"deadline"`
+}
+
+// Extension is the outcome of extending a pod
+type Extension struct {
+	Namespace           string    `json:"namespace"`
+	Name                string    `json:"name"`
+	Policy              string    `json:"policy"`
+	PreviousDeadline    time.Time `json:"previousDeadline"`
+	Deadline            time.Time `json:"deadline"`
+	MaxDeadline         time.Time `json:"maxDeadline"`
+	Extensions          int       `json:"extensions"`
+	RemainingExtensions int       `json:"remainingExtensions"`
+}
+
+// Extend moves the pod's deadline by the duration, rewriting its TTL label. The new
+// deadline is bounded by the maximum lifetime and the number of extensions is capped.
+// The pod is read and updated again when it changes concurrently, so parallel
+// requests cannot get past the limits.
+func (pm *PodMonitor) Extend(
+	ctx context.Context,
+	namespace, name string,
+	by time.Duration,
+	requester string,
+	now time.Time,
+) (Extension, error) {
+	current := pm.Config()
+	cfg := &current.Watchdog
+	if !cfg.Extensions.Enabled || cfg.TtlLabel == "" {
+		return Extension{}, ErrExtensionsDisabled
+	}
+	if !slices.Contains(cfg.Namespaces, namespace) {
+		return Extension{}, fmt.Errorf("%w: namespace %q is not monitored", ErrNotManaged, namespace)
+	}
+
+	var extension Extension
+	var refusal error
+	err := pm.retry(ctx, current, "extend", func(ctx context.Context) error {
+		pod, err := pm.clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		extension, refusal = extendPod(cfg, pod, by, requester, now)
+		if refusal != nil {
+			return nil
+		}
+		_, err = pm.clientset.CoreV1().Pods(namespace).Update(ctx, pod, metav1.UpdateOptions{})
+		return err
+	})
+	if err == nil {
+		err = refusal
+	}
+	if err != nil {
+		pm.logger.Infow("Pod extension not applied", "namespace", namespace, "pod", name, "by", requester, "error", err)
+		return Extension{}, err
+	}
+
+	pm.logger.Infow("Extended pod", "namespace", namespace, "pod", name, "by", requester,
+		"previousDeadline", extension.PreviousDeadline, "deadline", extension.Deadline, "extensions", extension.Extensions)
+	pm.metrics.podsExtendedTotal.WithLabelValues(namespace).Inc()
+	return extension, nil
+}
+
+// MaxExtendedLifetime returns the longest lifetime an extended pod can reach. The ager
+// terminates pods at the maximum pod lifetime whatever their TTL label says.
+func MaxExtendedLifetime(cfg *config.WatchdogConfig) time.Duration {
+	if cfg.Extensions.MaxLifetime > 0 {
+		return min(cfg.MaxPodLifetime, cfg.Extensions.MaxLifetime)
+	}
+	return cfg.MaxPodLifetime
+}
+
+// extendPod checks the limits and sets the new deadline on the pod in place
+func extendPod(cfg *config.WatchdogConfig, pod *v1.Pod, by time.Duration, requester string, now time.Time) (Extension, error) {
+	if !labels.SelectorFromSet(cfg.LabelSelectors).Matches(labels.Set(pod.Labels)) {
+		return Extension{}, fmt.Errorf("%w: labels do not match the policy", ErrNotManaged)
+	}
+
+	// A quiet ager keeps extensions out of the logs, which the agers write at info level
+	verdict, err := NewAgerFromConfig(cfg, zap.NewNop().Sugar()).Evaluate(pod, now)
+	if err != nil {
+		return Extension{}, fmt.Errorf("%w: %w", ErrExtensionRefused, err)
+	}
+	if verdict.Expired {
+		return Extension{}, fmt.Errorf("%w: pod already expired at %s", ErrExtensionRefused, formatDeadline(verdict.Deadline))
+	}
+
+	var history []ExtensionRecord
+	if raw, ok := pod.Annotations[AnnotationExtensions]; ok {
+		if err := json.Unmarshal([]byte(raw), &history); err != nil {
+			return Extension{}, fmt.Errorf("%w: invalid %s annotation: %w", ErrExtensionRefused, AnnotationExtensions, err)
+		}
+	}
+	if len(history) >= cfg.Extensions.MaxExtensions {
+		return Extension{}, fmt.Errorf("%w: pod was already extended %d times, the maximum", ErrExtensionRefused, len(history))
+	}
+
+	maxDeadline := pod.CreationTimestamp.Add(MaxExtendedLifetime(cfg)).Truncate(time.Second)
+	deadline := verdict.Deadline.Add(by).Truncate(time.Second)
+	if deadline.After(maxDeadline) {
+		return Extension{}, fmt.Errorf("%w: new deadline %s is past the maximum lifetime, the latest allowed deadline is %s",
+			ErrExtensionRefused, formatDeadline(deadline), formatDeadline(maxDeadline))
+	}
+
+	history = append(history, ExtensionRecord{
+		Time:     now.UTC(),
+		By:       requester,
+		Duration: by.String(),
+		Deadline: deadline.UTC(),
+	})
+	encoded, err := json.Marshal(history)
+	if err != nil {
+		return Extension{}, err
+	}
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Labels[cfg.TtlLabel] = strconv.FormatInt(deadline.Unix(), 10)
+	pod.Annotations[AnnotationExtensions] = string(encoded)
+
+	return Extension{
+		Namespace:           pod.Namespace,
+		Name:                pod.Name,
+		Policy:              cfg.Policy,
+		PreviousDeadline:    verdict.Deadline.UTC(),
+		Deadline:            deadline.UTC(),
+		MaxDeadline:         maxDeadline.UTC(),
+		Extensions:          len(history),
+		RemainingExtensions: cfg.Extensions.MaxExtensions - len(history),
+	}, nil
+}
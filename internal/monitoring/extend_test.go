@@ -0,0 +1,134 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/isdmx/watchdog/internal/client"
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+func TestExtend(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	newPod := func(name string, age, ttl time.Duration, labels map[string]string) *v1.Pod {
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			Labels:            map[string]string{"app": "sandbox"},
+			CreationTimestamp: metav1.NewTime(now.Add(-age)),
+		}}
+		if ttl != 0 {
+			pod.Labels["sandbox.kill_time"] = strconv.FormatInt(now.Add(ttl).Unix(), 10)
+		}
+		for key, value := range labels {
+			pod.Labels[key] = value
+		}
+		return pod
+	}
+	newConfig := func() *config.Config {
+		return &config.Config{Watchdog: config.WatchdogConfig{
+			Policy:         "default",
+			Namespaces:     []string{"default"},
+			LabelSelectors: map[string]string{"app": "sandbox"},
+			MaxPodLifetime: 8 * time.Hour,
+			TtlLabel:       "sandbox.kill_time",
+			Extensions:     config.ExtensionsConfig{Enabled: true, MaxLifetime: 4 * time.Hour, MaxExtensions: 2},
+			Retry:          config.RetryConfig{MaxAttempts: 1},
+		}}
+	}
+	ctx := context.Background()
+
+	t.Run("extends up to the maximum number of extensions", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newPod("sandbox", time.Hour, 30*time.Minute, nil))
+		pm := NewPodMonitor(clientset, newConfig(), nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+
+		extension, err := pm.Extend(ctx, "default", "sandbox", time.Hour, "alice", now)
+		require.NoError(t, err)
+		require.Equal(t, Extension{
+			Namespace:           "default",
+			Name:                "sandbox",
+			Policy:              "default",
+			PreviousDeadline:    now.Add(30 * time.Minute),
+			Deadline:            now.Add(90 * time.Minute),
+			MaxDeadline:         now.Add(3 * time.Hour),
+			Extensions:          1,
+			RemainingExtensions: 1,
+		}, extension)
+
+		pod, err := clientset.CoreV1().Pods("default").Get(ctx, "sandbox", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, strconv.FormatInt(now.Add(90*time.Minute).Unix(), 10), pod.Labels["sandbox.kill_time"])
+		var history []ExtensionRecord
+		require.NoError(t, json.Unmarshal([]byte(pod.Annotations[AnnotationExtensions]), &history))
+		require.Equal(t, []ExtensionRecord{{Time: now, By: "alice", Duration: "1h0m0s", Deadline: now.Add(90 * time.Minute)}}, history)
+
+		extension, err = pm.Extend(ctx, "default", "sandbox", 30*time.Minute, "bob", now)
+		require.NoError(t, err)
+		require.Equal(t, now.Add(2*time.Hour), extension.Deadline)
+		require.Equal(t, 0, extension.RemainingExtensions)
+
+		_, err = pm.Extend(ctx, "default", "sandbox", time.Minute, "alice", now)
+		require.ErrorIs(t, err, ErrExtensionRefused)
+		require.ErrorContains(t, err, "already extended 2 times")
+	})
+
+	t.Run("caps the total lifetime", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newPod("sandbox", time.Hour, 30*time.Minute, nil))
+		pm := NewPodMonitor(clientset, newConfig(), nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+
+		_, err := pm.Extend(ctx, "default", "sandbox", 3*time.Hour, "alice", now)
+		require.ErrorIs(t, err, ErrExtensionRefused)
+		require.ErrorContains(t, err, "latest allowed deadline is 2026-01-02T15:00:00Z")
+
+		// The maximum pod lifetime bounds extensions when it is the lower limit
+		cfg := newConfig()
+		cfg.Watchdog.Extensions.MaxLifetime = 0
+		cfg.Watchdog.MaxPodLifetime = 2 * time.Hour
+		pm = NewPodMonitor(clientset, cfg, nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+		_, err = pm.Extend(ctx, "default", "sandbox", time.Hour, "alice", now)
+		require.ErrorIs(t, err, ErrExtensionRefused)
+		extension, err := pm.Extend(ctx, "default", "sandbox", 30*time.Minute, "alice", now)
+		require.NoError(t, err)
+		require.Equal(t, now.Add(time.Hour), extension.Deadline)
+	})
+
+	t.Run("refuses pods that expired or are not managed", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(
+			newPod("expired", time.Hour, -time.Minute, nil),
+			newPod("other-app", time.Hour, time.Hour, map[string]string{"app": "web"}),
+		)
+		pm := NewPodMonitor(clientset, newConfig(), nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+
+		_, err := pm.Extend(ctx, "default", "expired", time.Hour, "alice", now)
+		require.ErrorIs(t, err, ErrExtensionRefused)
+		_, err = pm.Extend(ctx, "default", "other-app", time.Hour, "alice", now)
+		require.ErrorIs(t, err, ErrNotManaged)
+		_, err = pm.Extend(ctx, "kube-system", "sandbox", time.Hour, "alice", now)
+		require.ErrorIs(t, err, ErrNotManaged)
+		_, err = pm.Extend(ctx, "default", "missing", time.Hour, "alice", now)
+		require.Equal(t, client.ClassNotFound, client.Classify(err))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.Watchdog.Extensions.Enabled = false
+		pm := NewPodMonitor(fake.NewSimpleClientset(), cfg, nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+		_, err := pm.Extend(ctx, "default", "sandbox", time.Hour, "alice", now)
+		require.ErrorIs(t, err, ErrExtensionsDisabled)
+
+		cfg = newConfig()
+		cfg.Watchdog.TtlLabel = ""
+		pm = NewPodMonitor(fake.NewSimpleClientset(), cfg, nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+		_, err = pm.Extend(ctx, "default", "sandbox", time.Hour, "alice", now)
+		require.ErrorIs(t, err, ErrExtensionsDisabled)
+	})
+}
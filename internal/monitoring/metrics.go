@@ -1,43 +1,176 @@
 package monitoring
 
 import (
+	"cmp"
+	"slices"
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
-var (
-	// PodsTerminatedTotal counts the total number of pods terminated
-	PodsTerminatedTotal = promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "watchdog_pods_terminated_total",
-			Help: "Total number of pods terminated by the watchdog",
-		},
-		[]string{"namespace", "dry_run"},
-	)
+// metrics are the metrics of the pod monitor
+type metrics struct {
+	// podsTerminatedTotal counts the total number of pods terminated
+	podsTerminatedTotal *prometheus.CounterVec
+	// monitoringDuration tracks how long monitoring runs take
+	monitoringDuration *prometheus.HistogramVec
+	// podsExaminedTotal counts the total number of pods examined
+	podsExaminedTotal *prometheus.CounterVec
+	// podsTerminatedByAgeTotal counts pods terminated due to age
+	podsTerminatedByAgeTotal *prometheus.CounterVec
+	// podsWarnedTotal counts expiry warnings delivered to pods
+	podsWarnedTotal *prometheus.CounterVec
+	// podsExtendedTotal counts pod lifetime extensions made through the API
+	podsExtendedTotal *prometheus.CounterVec
+	// apiErrorsTotal counts failed Kubernetes API calls by error class
+	apiErrorsTotal *prometheus.CounterVec
+	// shardNamespaces tracks how many namespaces the replica's shard owns
+	shardNamespaces *prometheus.GaugeVec
+	// decisionsTotal counts the decisions about pods by policy, action and reason
+	decisionsTotal *prometheus.CounterVec
+	// candidates tracks the expired pods found in each namespace by the last cycle
+	candidates *prometheus.GaugeVec
+	// lastSuccess is the time of the last cycle that listed every namespace
+	lastSuccess *prometheus.GaugeVec
+	// podAgeAtTermination tracks how old pods were when they were terminated
+	podAgeAtTermination *prometheus.HistogramVec
+	// podExpiry tracks the time left to every pod, when enabled
+	podExpiry *prometheus.GaugeVec
+	// podExpiryDropped counts the pods left out of podExpiry by its series limit
+	podExpiryDropped *prometheus.GaugeVec
+}
 
-	// MonitoringDuration tracks how long monitoring runs take
-	MonitoringDuration = promauto.NewHistogram(
-		prometheus.HistogramOpts{
-			Name:    "watchdog_monitoring_duration_seconds",
-			Help:    "Time spent running monitoring checks",
-			Buckets: []float64{0.1, 0.5, 1, 2, 5, 10, 30},
-		},
-	)
+// newMetrics registers the monitoring metrics, a nil registerer leaves them unregistered
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	factory := promauto.With(registerer)
+	return &metrics{
+		podsTerminatedTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "watchdog_pods_terminated_total",
+				Help: "Total number of pods terminated by the watchdog",
+			},
+			[]string{"namespace", "dry_run", "shard"},
+		),
+		monitoringDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "watchdog_monitoring_duration_seconds",
+				Help:    "Time spent running monitoring checks",
+				Buckets: []float64{0.1, 0.5, 1, 2, 5, 10, 30},
+			},
+			[]string{"shard"},
+		),
+		podsExaminedTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "watchdog_pods_examined_total",
+				Help: "Total number of pods examined by the watchdog",
+			},
+			[]string{"shard"},
+		),
+		podsTerminatedByAgeTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "watchdog_pods_terminated_by_age_total",
+				Help: "Total number of pods terminated due to age limits",
+			},
+			[]string{"namespace", "shard"},
+		),
+		podsWarnedTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "watchdog_pods_warned_total",
+				Help: "Total number of expiry warnings delivered to pods",
+			},
+			[]string{"namespace", "shard"},
+		),
+		podsExtendedTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "watchdog_pods_extended_total",
+				Help: "Total number of pod lifetime extensions",
+			},
+			[]string{"namespace"},
+		),
+		apiErrorsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "watchdog_api_errors_total",
+				Help: "Total number of failed Kubernetes API calls by operation and error class",
+			},
+			[]string{"operation", "class", "shard"},
+		),
+		shardNamespaces: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "watchdog_shard_namespaces",
+				Help: "Number of configured namespaces owned by this replica's shard",
+			},
+			[]string{"shard"},
+		),
+		decisionsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "watchdog_decisions_total",
+				Help: "Total number of decisions about pods by policy, action and reason (empty for invalid pods)",
+			},
+			[]string{"policy", "action", "reason", "shard"},
+		),
+		candidates: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "watchdog_candidates",
+				Help: "Expired pods found in a namespace by the last cycle, whatever was done about them",
+			},
+			[]string{"namespace", "policy", "shard"},
+		),
+		lastSuccess: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "watchdog_last_success_timestamp_seconds",
+				Help: "Unix time at which the last cycle that listed every namespace finished",
+			},
+			[]string{"policy", "shard"},
+		),
+		podAgeAtTermination: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name: "watchdog_pod_age_at_termination_seconds",
+				Help: "Age of the pods when they were terminated by policy and reason",
+				Buckets: []float64{
+					(5 * time.Minute).Seconds(), (15 * time.Minute).Seconds(), (30 * time.Minute).Seconds(),
+					time.Hour.Seconds(), (2 * time.Hour).Seconds(), (6 * time.Hour).Seconds(), (12 * time.Hour).Seconds(),
+					(24 * time.Hour).Seconds(), (48 * time.Hour).Seconds(), (7 * 24 * time.Hour).Seconds(),
+				},
+			},
+			[]string{"policy", "reason", "shard"},
+		),
+		podExpiry: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "watchdog_pod_expiry_seconds",
+				Help: "Seconds until a pod expires, negative when overdue, for the pods expiring first",
+			},
+			[]string{"namespace", "pod", "shard"},
+		),
+		podExpiryDropped: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "watchdog_pod_expiry_dropped",
+				Help: "Pods left out of watchdog_pod_expiry_seconds by the series limit in the last cycle",
+			},
+			[]string{"shard"},
+		),
+	}
+}
 
-	// PodsExaminedTotal counts the total number of pods examined
-	PodsExaminedTotal = promauto.NewCounter(
-		prometheus.CounterOpts{
-			Name: "watchdog_pods_examined_total",
-			Help: "Total number of pods examined by the watchdog",
-		},
-	)
+// podExpiry is the time left to a pod, collected by a cycle for the per-pod gauge
+type podExpiry struct {
+	namespace string
+	name      string
+	remaining time.Duration
+}
 
-	// PodsTerminatedByAgeTotal counts pods terminated due to age
-	PodsTerminatedByAgeTotal = promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "watchdog_pods_terminated_by_age_total",
-			Help: "Total number of pods terminated due to age limits",
-		},
-		[]string{"namespace"},
-	)
-)
+// setPodExpiry replaces the per-pod series with those of the pods expiring first,
+// at most maxSeries of them, so the series of deleted pods disappear
+func (m *metrics) setPodExpiry(expiries []podExpiry, maxSeries int, shard string) {
+	slices.SortFunc(expiries, func(a, b podExpiry) int {
+		return cmp.Compare(a.remaining, b.remaining)
+	})
+	dropped := max(len(expiries)-max(maxSeries, 0), 0)
+	expiries = expiries[:len(expiries)-dropped]
+
+	m.podExpiry.Reset()
+	for _, expiry := range expiries {
+		m.podExpiry.WithLabelValues(expiry.namespace, expiry.name, shard).Set(expiry.remaining.Seconds())
+	}
+	m.podExpiryDropped.WithLabelValues(shard).Set(float64(dropped))
+}
@@ -2,30 +2,57 @@ package monitoring
 
 import (
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 )
 
 func TestMetrics(t *testing.T) {
-	t.Run("Metrics are properly initialized", func(t *testing.T) {
-		// Verify that all metrics are properly initialized
-		require.NotNil(t, PodsTerminatedTotal)
-		require.NotNil(t, MonitoringDuration)
-		require.NotNil(t, PodsExaminedTotal)
-		require.NotNil(t, PodsTerminatedByAgeTotal)
-
-		// Test that we can use the metrics without errors
-		labels := map[string]string{"namespace": "test", "dry_run": "false"}
-		PodsTerminatedTotal.With(labels).Inc()
-
-		// Observe a duration
-		MonitoringDuration.Observe(0.5) // 0.5 seconds
-
-		// Increment the counter
-		PodsExaminedTotal.Inc()
-
-		// Increment the counter with namespace label
-		ageLabels := map[string]string{"namespace": "test"}
-		PodsTerminatedByAgeTotal.With(ageLabels).Inc()
+	t.Run("Metrics are registered with the registry", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		m := newMetrics(registry)
+
+		m.podsTerminatedTotal.WithLabelValues("test", "false", "").Inc()
+		m.monitoringDuration.WithLabelValues("").Observe(0.5)
+		m.apiErrorsTotal.WithLabelValues("list", "throttled", "").Inc()
+		m.decisionsTotal.WithLabelValues("default", string(ActionTerminated), ReasonMaxLifetime, "").Inc()
+		m.lastSuccess.WithLabelValues("default", "").SetToCurrentTime()
+
+		count, err := testutil.GatherAndCount(registry,
+			"watchdog_pods_terminated_total",
+			"watchdog_monitoring_duration_seconds",
+			"watchdog_api_errors_total",
+			"watchdog_decisions_total",
+			"watchdog_last_success_timestamp_seconds",
+		)
+		require.NoError(t, err)
+		require.Equal(t, 5, count)
+	})
+
+	t.Run("Unregistered metrics stay private", func(t *testing.T) {
+		first, second := newMetrics(nil), newMetrics(nil)
+		first.podsExaminedTotal.WithLabelValues("").Inc()
+		require.InDelta(t, 0, testutil.ToFloat64(second.podsExaminedTotal.WithLabelValues("")), 0)
 	})
 }
+
+func TestSetPodExpiry(t *testing.T) {
+	m := newMetrics(nil)
+	m.setPodExpiry([]podExpiry{
+		{namespace: "a", name: "later", remaining: time.Hour},
+		{namespace: "a", name: "overdue", remaining: -time.Minute},
+		{namespace: "b", name: "soon", remaining: time.Minute},
+	}, 2, "")
+
+	require.Equal(t, 2, testutil.CollectAndCount(m.podExpiry))
+	require.InDelta(t, -60, testutil.ToFloat64(m.podExpiry.WithLabelValues("a", "overdue", "")), 0)
+	require.InDelta(t, 60, testutil.ToFloat64(m.podExpiry.WithLabelValues("b", "soon", "")), 0)
+	require.InDelta(t, 1, testutil.ToFloat64(m.podExpiryDropped.WithLabelValues("")), 0)
+
+	// Series of pods that are gone disappear on the next cycle
+	m.setPodExpiry([]podExpiry{{namespace: "a", name: "later", remaining: time.Hour}}, 2, "")
+	require.Equal(t, 1, testutil.CollectAndCount(m.podExpiry))
+	require.InDelta(t, 0, testutil.ToFloat64(m.podExpiryDropped.WithLabelValues("")), 0)
+}
@@ -3,96 +3,354 @@ package monitoring
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
 	"go.uber.org/zap"
 
+	"github.com/isdmx/watchdog/internal/client"
 	"github.com/isdmx/watchdog/internal/config"
 )
 
+// Sharder decides which namespaces belong to this replica
+type Sharder interface {
+	Owns(namespace string) bool
+	ShardID() string
+}
+
+// Pauser reports whether terminations of a policy are paused and why
+type Pauser interface {
+	Paused(policy string) (string, bool)
+}
+
 // PodMonitor handles pod monitoring and cleanup operations
 type PodMonitor struct {
-	clientset kubernetes.Interface
-	config    *config.Config
-	logger    *zap.SugaredLogger
+	clientset  kubernetes.Interface
+	config     atomic.Pointer[config.Config]
+	sharder    Sharder
+	pauser     Pauser
+	preservers []Preserver
+	observers  []Observer
+	httpClient *http.Client
+	logger     *zap.SugaredLogger
+	metrics    *metrics
+	tracer     trace.Tracer
 }
 
-// NewPodMonitor creates a new pod monitor
-func NewPodMonitor(clientset kubernetes.Interface, cfg *config.Config, logger *zap.SugaredLogger) *PodMonitor {
-	return &PodMonitor{
-		clientset: clientset,
-		config:    cfg,
-		logger:    logger.Named("PodMonitor"),
+// NewPodMonitor creates a new pod monitor, a nil sharder owns every namespace,
+// a nil pauser never pauses and a nil tracer provider traces nothing
+func NewPodMonitor(
+	clientset kubernetes.Interface,
+	cfg *config.Config,
+	sharder Sharder,
+	pauser Pauser,
+	logger *zap.SugaredLogger,
+	registerer prometheus.Registerer,
+	tracerProvider trace.TracerProvider,
+	preservers []Preserver,
+	observers ...Observer,
+) *PodMonitor {
+	if tracerProvider == nil {
+		tracerProvider = noop.NewTracerProvider()
+	}
+	pm := &PodMonitor{
+		clientset:  clientset,
+		sharder:    sharder,
+		pauser:     pauser,
+		preservers: preservers,
+		observers:  observers,
+		httpClient: &http.Client{},
+		logger:     logger.Named("PodMonitor"),
+		metrics:    newMetrics(registerer),
+		tracer:     tracerProvider.Tracer(tracerName),
 	}
+	pm.config.Store(cfg)
+	return pm
+}
+
+// Config returns the configuration in effect
+func (pm *PodMonitor) Config() *config.Config {
+	return pm.config.Load()
+}
+
+// Reload swaps in a new configuration. A running cycle keeps the configuration
+// it started with, the new one applies from the next cycle.
+func (pm *PodMonitor) Reload(cfg *config.Config) {
+	pm.config.Store(cfg)
+}
+
+// MonitorAndCleanup performs the monitoring and cleanup operation. Transient API
+// errors are retried until the context deadline, which bounds the whole cycle.
+// A cycle id is generated unless the context already carries one.
+func (pm *PodMonitor) MonitorAndCleanup(ctx context.Context) error {
+	_, err := pm.Run(ctx, RunOptions{})
+	return err
 }
 
-// MonitorAndCleanup performs the monitoring and cleanup operation
-func (pm *PodMonitor) MonitorAndCleanup() error {
-	pm.logger.Info("Starting pod monitoring and cleanup")
+// Run performs a monitoring cycle scoped by the options and reports what it did.
+// The report covers the namespaces processed before an error, if any.
+func (pm *PodMonitor) Run(ctx context.Context, opts RunOptions) (*CycleReport, error) {
+	if err := pm.CheckRunOptions(opts); err != nil {
+		return nil, err
+	}
+	if CycleID(ctx) == "" {
+		ctx = WithCycleID(ctx, newCycleID())
+	}
+	c := pm.newCycle(pm.Config(), CycleID(ctx), opts)
+
+	shard := pm.shardID()
+	pm.logger.Infow("Starting pod monitoring and cleanup", "shard", shard, "cycle", CycleID(ctx), "dryRun", c.dryRun)
+
+	ctx, span := pm.startSpan(ctx, "cycle",
+		attrCycleID.String(CycleID(ctx)),
+		attrPolicy.String(c.config.Watchdog.Policy),
+		attrDryRun.Bool(c.dryRun),
+		attrShard.String(shard),
+	)
+	defer func() {
+		span.SetAttributes(attrExamined.Int(c.report.Examined))
+		span.End()
+	}()
 
 	startTime := time.Now()
 	defer func() {
 		duration := time.Since(startTime)
-		MonitoringDuration.Observe(duration.Seconds())
+		pm.metrics.monitoringDuration.WithLabelValues(shard).Observe(duration.Seconds())
 		pm.logger.Debugf("Monitoring completed in %v", duration)
 	}()
 
 	// Create label selector from config
-	labelSelector := buildLabelSelector(pm.config.Watchdog.LabelSelectors)
-	ager := NewAgerFromConfig(&pm.config.Watchdog, pm.logger)
+	labelSelector := buildLabelSelector(c.config.Watchdog.LabelSelectors)
+	ager := NewAgerFromConfig(&c.config.Watchdog, pm.logger)
 
-	for _, namespace := range pm.config.Watchdog.Namespaces {
-		logger_namespace := pm.logger.WithLazy("namespace", namespace)
-		logger_namespace.Debugf("Processing namespace")
+	namespaces := pm.ownedNamespaces(c.config)
+	pm.metrics.shardNamespaces.WithLabelValues(shard).Set(float64(len(namespaces)))
 
-		// List pods in the namespace with the specified labels
-		pods, err := pm.clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+	for _, namespace := range namespaces {
+		if len(opts.Namespaces) > 0 && !slices.Contains(opts.Namespaces, namespace) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			pm.logger.Errorw("Monitoring cycle deadline exceeded", "remainingNamespace", namespace, "error", err)
+			err = fmt.Errorf("monitoring cycle interrupted: %w", err)
+			failSpan(span, err)
+			return c.report, err
+		}
+		pm.cleanupNamespace(ctx, c, namespace, labelSelector, ager)
+	}
+
+	if len(opts.Namespaces) == 0 {
+		pm.recordCycle(c, namespaces, shard)
+	}
+	return c.report, nil
+}
+
+// recordCycle updates the metrics describing a full cycle. The candidates of the
+// namespaces other shards took over are dropped, and the cycle only counts as a
+// success when it listed every namespace.
+func (pm *PodMonitor) recordCycle(c *cycle, namespaces []string, shard string) {
+	policy := c.config.Watchdog.Policy
+	for _, namespace := range c.config.Watchdog.Namespaces {
+		if !slices.Contains(namespaces, namespace) {
+			pm.metrics.candidates.DeleteLabelValues(namespace, policy, shard)
+		}
+	}
+	if c.expiries != nil {
+		pm.metrics.setPodExpiry(c.expiries, c.config.Metrics.PodExpiry.MaxSeries, shard)
+	}
+	if !c.listFailed {
+		pm.metrics.lastSuccess.WithLabelValues(policy, shard).SetToCurrentTime()
+	}
+}
+
+// cleanupNamespace lists the matching pods in a namespace and terminates the old ones
+func (pm *PodMonitor) cleanupNamespace(ctx context.Context, c *cycle, namespace, labelSelector string, ager Ager) {
+	shard := pm.shardID()
+	logger_namespace := pm.logger.WithLazy("namespace", namespace)
+	logger_namespace.Debugf("Processing namespace")
+
+	// List pods in the namespace with the specified labels
+	var pods *v1.PodList
+	listCtx, span := pm.startSpan(ctx, "list pods", semconv.K8SNamespaceName(namespace))
+	err := pm.retry(listCtx, c.config, "list", func(ctx context.Context) error {
+		var err error
+		pods, err = pm.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
 			LabelSelector: labelSelector,
 		})
-		if err != nil {
-			logger_namespace.Errorw("Failed to list pods", "error", err)
-			continue
+		return err
+	})
+	if err == nil {
+		span.SetAttributes(attrPods.Int(len(pods.Items)))
+	}
+	failSpan(span, err)
+	span.End()
+	if err != nil {
+		logger_namespace.Errorw("Failed to list pods", "class", client.Classify(err), "error", err)
+		c.unlisted(namespace)
+		return
+	}
+
+	logger_namespace.Debugf("Found %d pods in namespace with matching labels", len(pods.Items))
+	pm.metrics.podsExaminedTotal.WithLabelValues(shard).Add(float64(len(pods.Items)))
+	c.examined(namespace, len(pods.Items))
+
+	// Filter and terminate old pods
+	now := time.Now()
+	candidates := 0
+	for i := range pods.Items {
+		if pm.cleanupPod(ctx, c, &pods.Items[i], ager, now) {
+			candidates++
+		}
+	}
+	pm.metrics.candidates.WithLabelValues(namespace, c.config.Watchdog.Policy, shard).Set(float64(candidates))
+}
+
+// cleanupPod evaluates a single pod and terminates it when it is too old, it
+// reports whether the pod expired
+func (pm *PodMonitor) cleanupPod(ctx context.Context, c *cycle, pod *v1.Pod, ager Ager, now time.Time) bool {
+	shard := pm.shardID()
+	logger_pod := pm.logger.WithLazy("namespace", pod.Namespace, "pod", pod.Name)
+
+	// The span covers the decision about the pod, including its deletion
+	ctx, span := pm.startSpan(ctx, "evaluate pod", podAttributes(pod)...)
+	defer span.End()
+
+	verdict, err := ager.Evaluate(pod, now)
+	if err != nil {
+		logger_pod.Warnw("Unable to calculate pod age", "pod", pod.Name, "err", err)
+		pm.notify(ctx, c, Decision{Pod: pod, Action: ActionInvalid, Err: err})
+		return false
+	}
+	span.SetAttributes(attrExpired.Bool(verdict.Expired), attrReason.String(verdict.Reason))
+	if !verdict.Deadline.IsZero() {
+		span.SetAttributes(attrDeadline.String(verdict.Deadline.Format(time.RFC3339)))
+	}
+	c.expiring(pod, verdict, now)
+	if !verdict.Expired {
+		if warningDue(c.config.Watchdog.Warning, pod, verdict, now) {
+			pm.warn(ctx, c, pod, verdict, now)
 		}
+		return false
+	}
+
+	if c.dryRun {
+		logger_pod.Infow("DRY RUN: Would terminate pod", "reason", verdict.Reason, "deadline", verdict.Deadline)
+		pm.metrics.podsTerminatedTotal.WithLabelValues(pod.Namespace, "true", shard).Inc()
+		pm.notify(ctx, c, Decision{Pod: pod, Action: ActionDryRun, Verdict: verdict})
+		return true
+	}
 
-		logger_namespace.Debugf("Found %d pods in namespace with matching labels", len(pods.Items))
-		PodsExaminedTotal.Add(float64(len(pods.Items)))
-
-		// Filter and terminate old pods
-		for i := range pods.Items {
-			pod := &pods.Items[i]
-			logger_pod := logger_namespace.WithLazy("pod", pod.Name)
-
-			isOld, err := ager.IsOld(pod)
-			if err != nil {
-				logger_pod.Warnw("Unable to calculate pod age", "pod", pod.Name, "err", err)
-				continue
-			}
-			if !isOld {
-				continue
-			}
-
-			if pm.config.Watchdog.DryRun {
-				logger_pod.Infow("DRY RUN: Would terminate pod")
-				PodsTerminatedTotal.WithLabelValues(namespace, "true").Inc()
-			} else {
-				// Terminate the pod
-				err := pm.terminatePod(namespace, pod.Name)
-				if err != nil {
-					logger_pod.Errorw("Failed to terminate pod", "error", err)
-				} else {
-					logger_pod.Infow("Successfully terminated pod")
-					PodsTerminatedTotal.WithLabelValues(namespace, "false").Inc()
-					PodsTerminatedByAgeTotal.WithLabelValues(namespace).Inc()
-				}
-			}
+	if paused, ok := pm.paused(c.config.Watchdog.Policy); ok {
+		logger_pod.Infow("PAUSED: Would terminate pod", "reason", verdict.Reason, "deadline", verdict.Deadline, "pause", paused)
+		c.paused(paused)
+		pm.notify(ctx, c, Decision{Pod: pod, Action: ActionPaused, Verdict: verdict})
+		return true
+	}
+
+	if !terminationAllowed(c.config.Watchdog.Warning, pod, verdict, now) {
+		logger_pod.Infow("Deferring termination until the pod has been warned", "deadline", verdict.Deadline)
+		pm.warn(ctx, c, pod, verdict, now)
+		return true
+	}
+
+	if err := pm.preserve(ctx, pod); err != nil {
+		logger_pod.Errorw("Holding back termination", "error", err)
+		pm.notify(ctx, c, Decision{Pod: pod, Action: ActionFailed, Verdict: verdict, Err: err})
+		return true
+	}
+
+	// Terminate the pod
+	if err := pm.terminatePod(ctx, c.config, pod.Namespace, pod.Name); err != nil {
+		logger_pod.Errorw("Failed to terminate pod", "class", client.Classify(err), "error", err)
+		pm.notify(ctx, c, Decision{Pod: pod, Action: ActionFailed, Verdict: verdict, Err: err})
+		return true
+	}
+	logger_pod.Infow("Successfully terminated pod", "reason", verdict.Reason, "deadline", verdict.Deadline)
+	pm.metrics.podsTerminatedTotal.WithLabelValues(pod.Namespace, "false", shard).Inc()
+	pm.metrics.podsTerminatedByAgeTotal.WithLabelValues(pod.Namespace, shard).Inc()
+	pm.metrics.podAgeAtTermination.WithLabelValues(c.config.Watchdog.Policy, verdict.Reason, shard).
+		Observe(now.Sub(pod.CreationTimestamp.Time).Seconds())
+	pm.notify(ctx, c, Decision{Pod: pod, Action: ActionTerminated, Verdict: verdict})
+	return true
+}
+
+// warn delivers an expiry warning and reports it to the observers
+func (pm *PodMonitor) warn(ctx context.Context, c *cycle, pod *v1.Pod, verdict Verdict, now time.Time) {
+	if c.dryRun {
+		pm.logger.Infow("DRY RUN: Would warn pod about upcoming expiry",
+			"namespace", pod.Namespace, "pod", pod.Name, "deadline", verdict.Deadline)
+		return
+	}
+
+	if err := pm.warnPod(ctx, c.config, pod, verdict, now); err != nil {
+		pm.logger.Errorw("Failed to warn pod about upcoming expiry",
+			"namespace", pod.Namespace, "pod", pod.Name, "class", client.Classify(err), "error", err)
+		return
+	}
+	pm.metrics.podsWarnedTotal.WithLabelValues(pod.Namespace, pm.shardID()).Inc()
+	pm.notify(ctx, c, Decision{Pod: pod, Action: ActionWarned, Verdict: verdict})
+}
+
+// retry runs an API call with the backoff of the configuration and counts every
+// failed attempt by error class
+func (pm *PodMonitor) retry(ctx context.Context, cfg *config.Config, operation string, fn func(context.Context) error) error {
+	backoff := client.NewBackoff(cfg.Watchdog.Retry)
+	return client.Retry(ctx, backoff, fn, func(class client.ErrorClass, err error) {
+		pm.metrics.apiErrorsTotal.WithLabelValues(operation, string(class), pm.shardID()).Inc()
+		trace.SpanFromContext(ctx).AddEvent("API error", trace.WithAttributes(
+			attrAPICall.String(operation), attrClass.String(string(class)),
+		))
+		if class.Transient() {
+			pm.logger.Debugw("Transient API error", "operation", operation, "class", class, "error", err)
 		}
+	})
+}
+
+// OwnedNamespaces returns the configured namespaces that belong to this replica's shard
+func (pm *PodMonitor) OwnedNamespaces() []string {
+	return pm.ownedNamespaces(pm.Config())
+}
+
+// ownedNamespaces returns the namespaces of the configuration that belong to this replica's shard
+func (pm *PodMonitor) ownedNamespaces(cfg *config.Config) []string {
+	if pm.sharder == nil {
+		return cfg.Watchdog.Namespaces
 	}
 
-	return nil
+	namespaces := make([]string, 0, len(cfg.Watchdog.Namespaces))
+	for _, namespace := range cfg.Watchdog.Namespaces {
+		if pm.sharder.Owns(namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
+
+// paused reports whether terminations of the policy are paused
+func (pm *PodMonitor) paused(policy string) (string, bool) {
+	if pm.pauser == nil {
+		return "", false
+	}
+	return pm.pauser.Paused(policy)
+}
+
+// shardID returns the shard id used to label metrics
+func (pm *PodMonitor) shardID() string {
+	if pm.sharder == nil {
+		return ""
+	}
+	return pm.sharder.ShardID()
 }
 
 // buildLabelSelector creates a label selector string from a map
@@ -104,8 +362,24 @@ func buildLabelSelector(labels map[string]string) string {
 	return strings.Join(selectorParts, ",")
 }
 
-// terminatePod terminates a pod in the specified namespace
-func (pm *PodMonitor) terminatePod(namespace, podName string) error {
-	err := pm.clientset.CoreV1().Pods(namespace).Delete(context.TODO(), podName, metav1.DeleteOptions{})
+// terminatePod terminates a pod in the specified namespace, a pod that is already gone counts as terminated
+func (pm *PodMonitor) terminatePod(ctx context.Context, cfg *config.Config, namespace, podName string) error {
+	ctx, span := pm.startSpan(ctx, "delete pod", semconv.K8SNamespaceName(namespace), semconv.K8SPodName(podName))
+	defer span.End()
+
+	// A pod deleted in the meantime is the outcome we wanted, not an API error
+	var gone bool
+	err := pm.retry(ctx, cfg, "delete", func(ctx context.Context) error {
+		err := pm.clientset.CoreV1().Pods(namespace).Delete(ctx, podName, metav1.DeleteOptions{})
+		if client.Classify(err) == client.ClassNotFound {
+			gone = true
+			return nil
+		}
+		return err
+	})
+	if gone {
+		pm.logger.Debugw("Pod already deleted", "namespace", namespace, "pod", podName)
+	}
+	failSpan(span, err)
 	return err
 }
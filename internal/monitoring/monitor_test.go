@@ -2,13 +2,19 @@ package monitoring
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 
 	"github.com/isdmx/watchdog/internal/config"
 
@@ -26,10 +32,10 @@ func TestNewPodMonitor(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	sugaredLogger := logger.Sugar()
 
-	pm := NewPodMonitor(clientset, cfg, sugaredLogger)
+	pm := NewPodMonitor(clientset, cfg, nil, nil, sugaredLogger, nil, nil, nil)
 	require.NotNil(t, pm)
 	require.Equal(t, clientset, pm.clientset)
-	require.Equal(t, cfg, pm.config)
+	require.Equal(t, cfg, pm.Config())
 }
 
 func TestMonitorAndCleanup(t *testing.T) {
@@ -72,8 +78,8 @@ func TestMonitorAndCleanup(t *testing.T) {
 		logger, _ := zap.NewDevelopment()
 		sugaredLogger := logger.Sugar()
 
-		pm := NewPodMonitor(clientset, cfg, sugaredLogger)
-		err = pm.MonitorAndCleanup()
+		pm := NewPodMonitor(clientset, cfg, nil, nil, sugaredLogger, nil, nil, nil)
+		err = pm.MonitorAndCleanup(context.Background())
 		require.NoError(t, err)
 
 		// Check that the old pod was terminated
@@ -111,8 +117,8 @@ func TestMonitorAndCleanup(t *testing.T) {
 		logger, _ := zap.NewDevelopment()
 		sugaredLogger := logger.Sugar()
 
-		pm := NewPodMonitor(clientset, cfg, sugaredLogger)
-		err = pm.MonitorAndCleanup()
+		pm := NewPodMonitor(clientset, cfg, nil, nil, sugaredLogger, nil, nil, nil)
+		err = pm.MonitorAndCleanup(context.Background())
 		require.NoError(t, err)
 
 		// In dry run mode, the pod should still exist
@@ -134,13 +140,366 @@ func TestMonitorAndCleanup(t *testing.T) {
 		logger, _ := zap.NewDevelopment()
 		sugaredLogger := logger.Sugar()
 
-		pm := NewPodMonitor(fake.NewSimpleClientset(), cfg, sugaredLogger)
-		err := pm.MonitorAndCleanup()
+		pm := NewPodMonitor(fake.NewSimpleClientset(), cfg, nil, nil, sugaredLogger, nil, nil, nil)
+		err := pm.MonitorAndCleanup(context.Background())
 		// This should not return an error, it should just log and continue
 		require.NoError(t, err)
 	})
 }
 
+// stubSharder owns a fixed set of namespaces
+type stubSharder struct {
+	owned map[string]bool
+}
+
+func (s *stubSharder) Owns(namespace string) bool {
+	return s.owned[namespace]
+}
+
+func (*stubSharder) ShardID() string {
+	return "watchdog-0"
+}
+
+func TestMonitorAndCleanupSharded(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	for _, namespace := range []string{"team-a", "team-b"} {
+		_, err := clientset.CoreV1().Pods(namespace).Create(context.TODO(), &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "old-pod",
+				Namespace:         namespace,
+				CreationTimestamp: metav1.Time{Time: time.Now().Add(-2 * time.Hour)},
+			},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	cfg := &config.Config{
+		Watchdog: config.WatchdogConfig{
+			Namespaces:     []string{"team-a", "team-b"},
+			MaxPodLifetime: 1 * time.Hour,
+		},
+	}
+	sharder := &stubSharder{owned: map[string]bool{"team-a": true}}
+
+	pm := NewPodMonitor(clientset, cfg, sharder, nil, zap.NewNop().Sugar(), nil, nil, nil)
+	require.Equal(t, []string{"team-a"}, pm.OwnedNamespaces())
+	require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+
+	// Only the owned namespace is cleaned up
+	_, err := clientset.CoreV1().Pods("team-a").Get(context.TODO(), "old-pod", metav1.GetOptions{})
+	require.Error(t, err)
+	_, err = clientset.CoreV1().Pods("team-b").Get(context.TODO(), "old-pod", metav1.GetOptions{})
+	require.NoError(t, err)
+}
+
+func TestMonitorAndCleanupMetrics(t *testing.T) {
+	newPod := func(name string, age time.Duration) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.Time{Time: time.Now().Add(-age)},
+		}}
+	}
+	cfg := &config.Config{
+		Watchdog: config.WatchdogConfig{
+			Policy:         "sandboxes",
+			Namespaces:     []string{"default"},
+			MaxPodLifetime: time.Hour,
+		},
+		Metrics: config.MetricsConfig{PodExpiry: config.PodExpiryMetricsConfig{Enabled: true, MaxSeries: 1}},
+	}
+
+	t.Run("records the cycle", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newPod("old-pod", 2*time.Hour), newPod("young-pod", 10*time.Minute), newPod("new-pod", time.Minute))
+		pm := NewPodMonitor(clientset, cfg, nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+		require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+
+		require.InDelta(t, 1, testutil.ToFloat64(pm.metrics.decisionsTotal.WithLabelValues("sandboxes", "terminated", ReasonMaxLifetime, "")), 0)
+		require.InDelta(t, 1, testutil.ToFloat64(pm.metrics.candidates.WithLabelValues("default", "sandboxes", "")), 0)
+		require.Equal(t, 1, testutil.CollectAndCount(pm.metrics.podAgeAtTermination))
+		require.InDelta(t, float64(time.Now().Unix()), testutil.ToFloat64(pm.metrics.lastSuccess.WithLabelValues("sandboxes", "")), 5)
+
+		// Only the pod expiring first keeps a series
+		require.Equal(t, 1, testutil.CollectAndCount(pm.metrics.podExpiry))
+		require.InDelta(t, -time.Hour.Seconds(), testutil.ToFloat64(pm.metrics.podExpiry.WithLabelValues("default", "old-pod", "")), 5)
+		require.InDelta(t, 2, testutil.ToFloat64(pm.metrics.podExpiryDropped.WithLabelValues("")), 0)
+	})
+
+	t.Run("a failed list is not a success", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		clientset.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("rbac"))
+		})
+		pm := NewPodMonitor(clientset, cfg, nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+		report, err := pm.Run(context.Background(), RunOptions{})
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"default"}, report.Unlisted)
+		require.Zero(t, testutil.CollectAndCount(pm.metrics.lastSuccess))
+	})
+}
+
+func TestMonitorAndCleanupRetries(t *testing.T) {
+	newConfig := func() *config.Config {
+		return &config.Config{
+			Watchdog: config.WatchdogConfig{
+				Namespaces:     []string{"retry"},
+				MaxPodLifetime: 1 * time.Hour,
+				Retry: config.RetryConfig{
+					InitialBackoff: time.Millisecond,
+					MaxBackoff:     time.Millisecond,
+					Factor:         2,
+					MaxAttempts:    3,
+				},
+			},
+		}
+	}
+	oldPod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "old-pod",
+			Namespace:         "retry",
+			CreationTimestamp: metav1.Time{Time: time.Now().Add(-2 * time.Hour)},
+		},
+	}
+
+	t.Run("retries a throttled list", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(oldPod.DeepCopy())
+		failures := 1
+		clientset.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+			if failures > 0 {
+				failures--
+				return true, nil, apierrors.NewTooManyRequests("slow down", 0)
+			}
+			return false, nil, nil
+		})
+
+		pm := NewPodMonitor(clientset, newConfig(), nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+		require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+
+		_, err := clientset.CoreV1().Pods("retry").Get(context.TODO(), "old-pod", metav1.GetOptions{})
+		require.Error(t, err)
+		require.InDelta(t, 1, testutil.ToFloat64(pm.metrics.apiErrorsTotal.WithLabelValues("list", "throttled", "")), 0)
+	})
+
+	t.Run("does not retry a forbidden delete", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(oldPod.DeepCopy())
+		deletes := 0
+		clientset.PrependReactor("delete", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+			deletes++
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "old-pod", errors.New("rbac"))
+		})
+
+		pm := NewPodMonitor(clientset, newConfig(), nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+		require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+
+		require.Equal(t, 1, deletes)
+		require.InDelta(t, 1, testutil.ToFloat64(pm.metrics.apiErrorsTotal.WithLabelValues("delete", "forbidden", "")), 0)
+	})
+
+	t.Run("a pod deleted in the meantime is not an API error", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(oldPod.DeepCopy())
+		clientset.PrependReactor("delete", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "old-pod")
+		})
+
+		pm := NewPodMonitor(clientset, newConfig(), nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+		report, err := pm.Run(context.Background(), RunOptions{})
+		require.NoError(t, err)
+
+		require.Equal(t, map[Action]int{ActionTerminated: 1}, report.Actions)
+		require.InDelta(t, 0, testutil.ToFloat64(pm.metrics.apiErrorsTotal.WithLabelValues("delete", "not_found", "")), 0)
+	})
+
+	t.Run("stops when the cycle deadline is exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		pm := NewPodMonitor(fake.NewSimpleClientset(oldPod.DeepCopy()), newConfig(), nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+		err := pm.MonitorAndCleanup(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// recordingObserver collects every decision it observes
+type recordingObserver struct {
+	decisions []Decision
+}
+
+func (r *recordingObserver) Observe(_ context.Context, decision Decision) {
+	r.decisions = append(r.decisions, decision)
+}
+
+// stubPreserver records the pods it preserves and fails with err
+type stubPreserver struct {
+	pods []string
+	err  error
+}
+
+func (p *stubPreserver) Name() string {
+	return "stub"
+}
+
+func (p *stubPreserver) Preserve(_ context.Context, pod *v1.Pod) error {
+	p.pods = append(p.pods, pod.Name)
+	return p.err
+}
+
+func TestMonitorAndCleanupObservers(t *testing.T) {
+	newPod := func(name string, age time.Duration, labels map[string]string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				Labels:            labels,
+				CreationTimestamp: metav1.Time{Time: time.Now().Add(-age)},
+			},
+		}
+	}
+	clientset := fake.NewSimpleClientset(
+		newPod("expired", 2*time.Hour, nil),
+		newPod("young", time.Minute, nil),
+		newPod("invalid", time.Minute, map[string]string{"sandbox.kill_time": "soon"}),
+	)
+	cfg := &config.Config{
+		Watchdog: config.WatchdogConfig{
+			Namespaces:     []string{"default"},
+			MaxPodLifetime: 1 * time.Hour,
+			TtlLabel:       "sandbox.kill_time",
+		},
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		observer := &recordingObserver{}
+		dryRunConfig := *cfg
+		dryRunConfig.Watchdog.DryRun = true
+
+		pm := NewPodMonitor(clientset, &dryRunConfig, nil, nil, zap.NewNop().Sugar(), nil, nil, nil, observer)
+		require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+
+		actions := map[string]Action{}
+		for _, decision := range observer.decisions {
+			actions[decision.Pod.Name] = decision.Action
+		}
+		require.Equal(t, map[string]Action{"expired": ActionDryRun, "invalid": ActionInvalid}, actions)
+	})
+
+	t.Run("terminate", func(t *testing.T) {
+		observer := &recordingObserver{}
+		pm := NewPodMonitor(clientset, cfg, nil, nil, zap.NewNop().Sugar(), nil, nil, nil, observer)
+		require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+
+		var terminated []Decision
+		for _, decision := range observer.decisions {
+			if decision.Action == ActionTerminated {
+				terminated = append(terminated, decision)
+			}
+		}
+		require.Len(t, terminated, 1)
+		require.Equal(t, "expired", terminated[0].Pod.Name)
+		require.Equal(t, ReasonMaxLifetime, terminated[0].Verdict.Reason)
+		require.False(t, terminated[0].DryRun)
+	})
+
+	t.Run("failed termination", func(t *testing.T) {
+		failing := fake.NewSimpleClientset(newPod("expired", 2*time.Hour, nil))
+		failing.PrependReactor("delete", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("boom")
+		})
+		observer := &recordingObserver{}
+		pm := NewPodMonitor(failing, cfg, nil, nil, zap.NewNop().Sugar(), nil, nil, nil, observer)
+		require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+
+		require.Len(t, observer.decisions, 1)
+		require.Equal(t, ActionFailed, observer.decisions[0].Action)
+		require.EqualError(t, observer.decisions[0].Err, "boom")
+	})
+
+	t.Run("failing preserver holds back termination", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newPod("expired", 2*time.Hour, nil))
+		observer := &recordingObserver{}
+		preserver := &stubPreserver{err: errors.New("disk full")}
+		pm := NewPodMonitor(clientset, cfg, nil, nil, zap.NewNop().Sugar(), nil, nil, []Preserver{preserver}, observer)
+		require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+
+		require.Equal(t, []string{"expired"}, preserver.pods)
+		require.Len(t, observer.decisions, 1)
+		require.Equal(t, ActionFailed, observer.decisions[0].Action)
+		require.EqualError(t, observer.decisions[0].Err, "stub before termination failed: disk full")
+		_, err := clientset.CoreV1().Pods("default").Get(context.Background(), "expired", metav1.GetOptions{})
+		require.NoError(t, err)
+
+		preserver.err = nil
+		require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+		_, err = clientset.CoreV1().Pods("default").Get(context.Background(), "expired", metav1.GetOptions{})
+		require.True(t, apierrors.IsNotFound(err))
+	})
+
+	t.Run("cycle id and policy", func(t *testing.T) {
+		observer := &recordingObserver{}
+		policyConfig := *cfg
+		policyConfig.Watchdog.DryRun = true
+		policyConfig.Watchdog.Policy = "sandboxes"
+
+		pm := NewPodMonitor(clientset, &policyConfig, nil, nil, zap.NewNop().Sugar(), nil, nil, nil, observer)
+		require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+		require.NotEmpty(t, observer.decisions)
+		cycleID := observer.decisions[0].CycleID
+		require.NotEmpty(t, cycleID)
+		for _, decision := range observer.decisions {
+			require.Equal(t, cycleID, decision.CycleID)
+			require.Equal(t, "sandboxes", decision.Policy)
+		}
+
+		observer.decisions = nil
+		require.NoError(t, pm.MonitorAndCleanup(WithCycleID(context.Background(), "run-1")))
+		require.NotEmpty(t, observer.decisions)
+		require.Equal(t, "run-1", observer.decisions[0].CycleID)
+	})
+}
+
+// observerFunc observes decisions with a function
+type observerFunc func(decision Decision)
+
+func (f observerFunc) Observe(_ context.Context, decision Decision) {
+	f(decision)
+}
+
+func TestPodMonitorReload(t *testing.T) {
+	newPod := func(name string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.Time{Time: time.Now().Add(-2 * time.Hour)},
+		}}
+	}
+	clientset := fake.NewSimpleClientset(newPod("first"), newPod("second"))
+	dryRun := &config.Config{
+		Watchdog: config.WatchdogConfig{
+			Namespaces:     []string{"default"},
+			MaxPodLifetime: time.Hour,
+			DryRun:         true,
+		},
+	}
+	live := *dryRun
+	live.Watchdog.DryRun = false
+
+	var pm *PodMonitor
+	var actions []Action
+	pm = NewPodMonitor(clientset, dryRun, nil, nil, zap.NewNop().Sugar(), nil, nil, nil, observerFunc(func(decision Decision) {
+		actions = append(actions, decision.Action)
+		pm.Reload(&live)
+	}))
+
+	// The configuration reloaded during a cycle applies from the next one
+	require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+	require.Equal(t, []Action{ActionDryRun, ActionDryRun}, actions)
+	require.Same(t, &live, pm.Config())
+
+	actions = nil
+	require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+	require.Equal(t, []Action{ActionTerminated, ActionTerminated}, actions)
+}
+
 func TestBuildLabelSelector(t *testing.T) {
 	t.Run("creates empty selector for empty map", func(t *testing.T) {
 		labels := map[string]string{}
@@ -198,8 +557,8 @@ func TestTerminatePod(t *testing.T) {
 		logger, _ := zap.NewDevelopment()
 		sugaredLogger := logger.Sugar()
 
-		pm := NewPodMonitor(clientset, cfg, sugaredLogger)
-		err = pm.terminatePod("default", "test-pod")
+		pm := NewPodMonitor(clientset, cfg, nil, nil, sugaredLogger, nil, nil, nil)
+		err = pm.terminatePod(context.Background(), cfg, "default", "test-pod")
 		require.NoError(t, err)
 
 		// Verify pod was deleted
@@ -207,7 +566,7 @@ func TestTerminatePod(t *testing.T) {
 		require.Error(t, err)
 	})
 
-	t.Run("handles error when terminating non-existent pod", func(t *testing.T) {
+	t.Run("treats non-existent pod as terminated", func(t *testing.T) {
 		clientset := fake.NewSimpleClientset()
 
 		cfg := &config.Config{
@@ -219,9 +578,73 @@ func TestTerminatePod(t *testing.T) {
 		logger, _ := zap.NewDevelopment()
 		sugaredLogger := logger.Sugar()
 
-		pm := NewPodMonitor(clientset, cfg, sugaredLogger)
-		err := pm.terminatePod("default", "non-existent-pod")
-		// This should return an error since the pod doesn't exist
-		require.Error(t, err)
+		pm := NewPodMonitor(clientset, cfg, nil, nil, sugaredLogger, nil, nil, nil)
+		err := pm.terminatePod(context.Background(), cfg, "default", "non-existent-pod")
+		// The pod is already gone, which is what termination wants
+		require.NoError(t, err)
+	})
+}
+
+func TestRun(t *testing.T) {
+	newPod := func(namespace, name string, age time.Duration) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			CreationTimestamp: metav1.Time{Time: time.Now().Add(-age)},
+		}}
+	}
+	newClientset := func() *fake.Clientset {
+		return fake.NewSimpleClientset(
+			newPod("default", "expired", 2*time.Hour),
+			newPod("default", "young", time.Minute),
+			newPod("other", "expired", 2*time.Hour),
+		)
+	}
+	cfg := &config.Config{Watchdog: config.WatchdogConfig{
+		Policy:         "default",
+		Namespaces:     []string{"default", "other"},
+		MaxPodLifetime: time.Hour,
+	}}
+
+	t.Run("reports the cycle", func(t *testing.T) {
+		pm := NewPodMonitor(newClientset(), cfg, nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+		report, err := pm.Run(WithCycleID(context.Background(), "run-1"), RunOptions{})
+		require.NoError(t, err)
+
+		require.Equal(t, "run-1", report.CycleID)
+		require.Equal(t, "default", report.Policy)
+		require.False(t, report.DryRun)
+		require.Equal(t, []string{"default", "other"}, report.Namespaces)
+		require.Equal(t, 3, report.Examined)
+		require.Equal(t, map[Action]int{ActionTerminated: 2}, report.Actions)
+		require.Len(t, report.Results, 2)
+		require.Equal(t, Result{Namespace: "default", Name: "expired", Action: ActionTerminated, Reason: ReasonMaxLifetime}, report.Results[0])
+	})
+
+	t.Run("scoped dry run", func(t *testing.T) {
+		clientset := newClientset()
+		observer := &recordingObserver{}
+		pm := NewPodMonitor(clientset, cfg, nil, nil, zap.NewNop().Sugar(), nil, nil, nil, observer)
+
+		dryRun := true
+		report, err := pm.Run(context.Background(), RunOptions{Namespaces: []string{"other"}, Policy: "default", DryRun: &dryRun})
+		require.NoError(t, err)
+		require.True(t, report.DryRun)
+		require.Equal(t, []string{"other"}, report.Namespaces)
+		require.Equal(t, map[Action]int{ActionDryRun: 1}, report.Actions)
+		require.Len(t, observer.decisions, 1)
+		require.True(t, observer.decisions[0].DryRun)
+
+		pods, err := clientset.CoreV1().Pods("other").List(context.Background(), metav1.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, pods.Items, 1)
+	})
+
+	t.Run("rejects options outside the policy", func(t *testing.T) {
+		pm := NewPodMonitor(newClientset(), cfg, nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+		_, err := pm.Run(context.Background(), RunOptions{Namespaces: []string{"kube-system"}})
+		require.ErrorContains(t, err, "not monitored")
+		_, err = pm.Run(context.Background(), RunOptions{Policy: "strict"})
+		require.ErrorContains(t, err, "unknown policy")
 	})
 }
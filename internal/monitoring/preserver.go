@@ -0,0 +1,26 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Preserver saves something about a pod right before it is terminated. An error
+// holds back the termination until a later cycle, so preservers that should not
+// block deletion log their failures and return nil.
+type Preserver interface {
+	Name() string
+	Preserve(ctx context.Context, pod *v1.Pod) error
+}
+
+// preserve runs every preserver and stops at the first one that fails
+func (pm *PodMonitor) preserve(ctx context.Context, pod *v1.Pod) error {
+	for _, preserver := range pm.preservers {
+		if err := preserver.Preserve(ctx, pod); err != nil {
+			return fmt.Errorf("%s before termination failed: %w", preserver.Name(), err)
+		}
+	}
+	return nil
+}
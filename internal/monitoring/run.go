@@ -0,0 +1,135 @@
+package monitoring
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+// RunOptions scopes a single monitoring cycle, the zero value runs the configured policy as is
+type RunOptions struct {
+	// Namespaces restricts the cycle to some of the configured namespaces
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Policy must name the configured policy when set
+	Policy string `json:"policy,omitempty"`
+	// DryRun overrides the configured dry-run mode
+	DryRun *bool `json:"dryRun,omitempty"`
+}
+
+// CycleReport summarizes what a monitoring cycle did
+type CycleReport struct {
+	CycleID    string   `json:"cycleId"`
+	Policy     string   `json:"policy"`
+	DryRun     bool     `json:"dryRun"`
+	Paused     string   `json:"paused,omitempty"`
+	Namespaces []string `json:"namespaces"`
+	// Unlisted are the namespaces whose pods could not be listed
+	Unlisted []string       `json:"unlisted,omitempty"`
+	Examined int            `json:"examined"`
+	Actions  map[Action]int `json:"actions"`
+	Results  []Result       `json:"results"`
+}
+
+// Result is the decision made about a single pod during a cycle
+type Result struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Action    Action `json:"action"`
+	Reason    string `json:"reason,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// CheckRunOptions reports whether the options fit the configured policy
+func (pm *PodMonitor) CheckRunOptions(opts RunOptions) error {
+	cfg := pm.Config()
+	if opts.Policy != "" && opts.Policy != cfg.Watchdog.Policy {
+		return fmt.Errorf("unknown policy %q", opts.Policy)
+	}
+	for _, namespace := range opts.Namespaces {
+		if !slices.Contains(cfg.Watchdog.Namespaces, namespace) {
+			return fmt.Errorf("namespace %q is not monitored", namespace)
+		}
+	}
+	return nil
+}
+
+// cycle is the state of a running monitoring cycle
+type cycle struct {
+	// config is the configuration the cycle started with, reloads do not change it
+	config     *config.Config
+	dryRun     bool
+	report     *CycleReport
+	listFailed bool
+	// expiries collects the time left to every pod when the per-pod metric is enabled
+	expiries []podExpiry
+}
+
+// newCycle creates the state of a cycle with the given configuration and options
+func (pm *PodMonitor) newCycle(cfg *config.Config, id string, opts RunOptions) *cycle {
+	dryRun := cfg.Watchdog.DryRun
+	if opts.DryRun != nil {
+		dryRun = *opts.DryRun
+	}
+	var expiries []podExpiry
+	if cfg.Metrics.PodExpiry.Enabled {
+		expiries = []podExpiry{}
+	}
+	return &cycle{
+		config:   cfg,
+		dryRun:   dryRun,
+		expiries: expiries,
+		report: &CycleReport{
+			CycleID:    id,
+			Policy:     cfg.Watchdog.Policy,
+			DryRun:     dryRun,
+			Namespaces: []string{},
+			Actions:    map[Action]int{},
+			Results:    []Result{},
+		},
+	}
+}
+
+// examined counts the pods listed in a namespace
+func (c *cycle) examined(namespace string, pods int) {
+	c.report.Namespaces = append(c.report.Namespaces, namespace)
+	c.report.Examined += pods
+}
+
+// unlisted notes a namespace whose pods could not be listed
+func (c *cycle) unlisted(namespace string) {
+	c.listFailed = true
+	c.report.Unlisted = append(c.report.Unlisted, namespace)
+}
+
+// expiring collects the time left to the pod when the per-pod metric is enabled
+func (c *cycle) expiring(pod *v1.Pod, verdict Verdict, now time.Time) {
+	if c.expiries == nil {
+		return
+	}
+	c.expiries = append(c.expiries, podExpiry{namespace: pod.Namespace, name: pod.Name, remaining: verdict.Deadline.Sub(now)})
+}
+
+// paused notes the pause that held back terminations
+func (c *cycle) paused(description string) {
+	c.report.Paused = description
+}
+
+// record adds a decision to the report
+func (c *cycle) record(decision Decision) {
+	result := Result{
+		Namespace: decision.Pod.Namespace,
+		Name:      decision.Pod.Name,
+		Action:    decision.Action,
+		Reason:    decision.Verdict.Reason,
+	}
+	if decision.Err != nil {
+		result.Error = decision.Err.Error()
+	}
+
+	c.report.Actions[decision.Action]++
+	c.report.Results = append(c.report.Results, result)
+}
@@ -0,0 +1,53 @@
+package monitoring
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+	v1 "k8s.io/api/core/v1"
+)
+
+// tracerName is the instrumentation scope of the cycle spans
+const tracerName = "github.com/isdmx/watchdog/internal/monitoring"
+
+// Attributes of the cycle spans, besides the standard Kubernetes ones
+const (
+	attrCycleID  = attribute.Key("watchdog.cycle.id")
+	attrPolicy   = attribute.Key("watchdog.policy")
+	attrDryRun   = attribute.Key("watchdog.dry_run")
+	attrShard    = attribute.Key("watchdog.shard")
+	attrPods     = attribute.Key("watchdog.pods")
+	attrExamined = attribute.Key("watchdog.examined")
+	attrExpired  = attribute.Key("watchdog.expired")
+	attrReason   = attribute.Key("watchdog.reason")
+	attrDeadline = attribute.Key("watchdog.deadline")
+	attrAction   = attribute.Key("watchdog.action")
+	attrClass    = attribute.Key("watchdog.error.class")
+	attrAPICall  = attribute.Key("watchdog.operation")
+)
+
+// podAttributes identifies the pod of a span
+func podAttributes(pod *v1.Pod) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.K8SNamespaceName(pod.Namespace),
+		semconv.K8SPodName(pod.Name),
+		semconv.K8SPodUID(string(pod.UID)),
+	}
+}
+
+// startSpan starts a span of the cycle in the context
+func (pm *PodMonitor) startSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return pm.tracer.Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// failSpan marks the span failed with the error, if any
+func failSpan(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,131 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/isdmx/watchdog/internal/config"
+
+	"go.uber.org/zap"
+)
+
+// spanAttributes returns the attributes of a span by key
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	return attributes
+}
+
+// spansNamed returns the ended spans of the name
+func spansNamed(recorder *tracetest.SpanRecorder, name string) []sdktrace.ReadOnlySpan {
+	var spans []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+func TestTracing(t *testing.T) {
+	newPod := func(name string, age time.Duration) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			UID:               types.UID("uid-" + name),
+			CreationTimestamp: metav1.Time{Time: time.Now().Add(-age)},
+		}}
+	}
+	cfg := &config.Config{
+		Watchdog: config.WatchdogConfig{
+			Policy:         "sandboxes",
+			Namespaces:     []string{"default", "other"},
+			MaxPodLifetime: time.Hour,
+			Retry:          config.RetryConfig{MaxAttempts: 1},
+		},
+	}
+	newMonitor := func(clientset *fake.Clientset) (*PodMonitor, *tracetest.SpanRecorder) {
+		recorder := tracetest.NewSpanRecorder()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		return NewPodMonitor(clientset, cfg, nil, nil, zap.NewNop().Sugar(), nil, provider, nil), recorder
+	}
+
+	t.Run("traces the cycle", func(t *testing.T) {
+		pm, recorder := newMonitor(fake.NewSimpleClientset(newPod("old-pod", 2*time.Hour), newPod("young-pod", time.Minute)))
+		require.NoError(t, pm.MonitorAndCleanup(WithCycleID(context.Background(), "cycle-1")))
+
+		cycles := spansNamed(recorder, "cycle")
+		require.Len(t, cycles, 1)
+		cycle := cycles[0]
+		require.Equal(t, "cycle-1", spanAttributes(cycle)[attrCycleID].AsString())
+		require.Equal(t, "sandboxes", spanAttributes(cycle)[attrPolicy].AsString())
+		require.Equal(t, int64(2), spanAttributes(cycle)[attrExamined].AsInt64())
+
+		lists := spansNamed(recorder, "list pods")
+		require.Len(t, lists, 2, "one per namespace")
+		for _, list := range lists {
+			require.Equal(t, cycle.SpanContext().SpanID(), list.Parent().SpanID())
+		}
+		require.Equal(t, "default", spanAttributes(lists[0])["k8s.namespace.name"].AsString())
+		require.Equal(t, int64(2), spanAttributes(lists[0])[attrPods].AsInt64())
+
+		evaluations := map[string]sdktrace.ReadOnlySpan{}
+		for _, span := range spansNamed(recorder, "evaluate pod") {
+			require.Equal(t, cycle.SpanContext().SpanID(), span.Parent().SpanID())
+			evaluations[spanAttributes(span)["k8s.pod.name"].AsString()] = span
+		}
+		require.Len(t, evaluations, 2)
+		old := spanAttributes(evaluations["old-pod"])
+		require.Equal(t, "uid-old-pod", old["k8s.pod.uid"].AsString())
+		require.True(t, old[attrExpired].AsBool())
+		require.Equal(t, ReasonMaxLifetime, old[attrReason].AsString())
+		require.Equal(t, string(ActionTerminated), old[attrAction].AsString())
+		young := spanAttributes(evaluations["young-pod"])
+		require.False(t, young[attrExpired].AsBool())
+		require.NotContains(t, young, attrAction)
+
+		deletes := spansNamed(recorder, "delete pod")
+		require.Len(t, deletes, 1)
+		require.Equal(t, evaluations["old-pod"].SpanContext().SpanID(), deletes[0].Parent().SpanID())
+		require.Equal(t, "old-pod", spanAttributes(deletes[0])["k8s.pod.name"].AsString())
+		require.Equal(t, codes.Unset, deletes[0].Status().Code)
+	})
+
+	t.Run("marks failed calls", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newPod("old-pod", 2*time.Hour))
+		clientset.PrependReactor("delete", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "old-pod", errors.New("rbac"))
+		})
+		pm, recorder := newMonitor(clientset)
+		require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+
+		deletes := spansNamed(recorder, "delete pod")
+		require.Len(t, deletes, 1)
+		require.Equal(t, codes.Error, deletes[0].Status().Code)
+		require.Len(t, deletes[0].Events(), 2, "the API error and the recorded error")
+		require.Equal(t, "API error", deletes[0].Events()[0].Name)
+
+		evaluations := spansNamed(recorder, "evaluate pod")
+		require.Len(t, evaluations, 1)
+		require.Equal(t, codes.Error, evaluations[0].Status().Code)
+		require.Equal(t, string(ActionFailed), spanAttributes(evaluations[0])[attrAction].AsString())
+	})
+}
@@ -0,0 +1,126 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+// AnnotationExpiresAt records the deadline a pod was warned about
+const AnnotationExpiresAt = "watchdog/expires-at"
+
+// Warning is the JSON notice posted to a pod before it expires
+type Warning struct {
+	Pod              string    `json:"pod"`
+	Namespace        string    `json:"namespace"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	Reason           string    `json:"reason"`
+	SecondsRemaining int64     `json:"secondsRemaining"`
+}
+
+// warningDue reports whether the pod is within the lead time of a deadline it was not warned about yet
+func warningDue(cfg config.WarningConfig, pod *v1.Pod, verdict Verdict, now time.Time) bool {
+	if !cfg.Enabled || verdict.Deadline.Sub(now) > cfg.LeadTime {
+		return false
+	}
+	return pod.Annotations[AnnotationExpiresAt] != formatDeadline(verdict.Deadline)
+}
+
+// terminationAllowed reports whether an expired pod was warned about its current
+// deadline, or its lead time has passed since the deadline without a warning
+// getting through. A warning about an earlier deadline, before an extension, a
+// TTL label change or a reload, does not count.
+func terminationAllowed(cfg config.WarningConfig, pod *v1.Pod, verdict Verdict, now time.Time) bool {
+	if !cfg.Enabled {
+		return true
+	}
+	if pod.Annotations[AnnotationExpiresAt] == formatDeadline(verdict.Deadline) {
+		return true
+	}
+	return !now.Before(verdict.Deadline.Add(cfg.LeadTime))
+}
+
+// warnPod annotates the pod with its deadline and, when enabled, posts a notice to the pod.
+// The warning counts as delivered once the annotation is written.
+func (pm *PodMonitor) warnPod(ctx context.Context, cfg *config.Config, pod *v1.Pod, verdict Verdict, now time.Time) error {
+	logger := pm.logger.With("namespace", pod.Namespace, "pod", pod.Name, "deadline", verdict.Deadline)
+
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{AnnotationExpiresAt: formatDeadline(verdict.Deadline)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = pm.retry(ctx, cfg, "patch", func(ctx context.Context) error {
+		_, err := pm.clientset.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	logger.Infow("Warned pod about upcoming expiry", "reason", verdict.Reason)
+
+	if cfg.Watchdog.Warning.HTTP.Enabled {
+		if err := pm.postWarning(ctx, cfg.Watchdog.Warning.HTTP, pod, verdict, now); err != nil {
+			logger.Warnw("Failed to post expiry notice to pod", "error", err)
+		}
+	}
+	return nil
+}
+
+// postWarning sends the expiry notice to the HTTP endpoint inside the pod
+func (pm *PodMonitor) postWarning(ctx context.Context, cfg config.WarningHTTPConfig, pod *v1.Pod, verdict Verdict, now time.Time) error {
+	if pod.Status.PodIP == "" {
+		return fmt.Errorf("pod has no IP address")
+	}
+
+	body, err := json.Marshal(Warning{
+		Pod:              pod.Name,
+		Namespace:        pod.Namespace,
+		ExpiresAt:        verdict.Deadline.UTC(),
+		Reason:           verdict.Reason,
+		SecondsRemaining: int64(max(verdict.Deadline.Sub(now), 0).Seconds()),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	url := "http://" + net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(cfg.Port)) + cfg.Path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := pm.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("pod answered %s", resp.Status)
+	}
+	return nil
+}
+
+func formatDeadline(deadline time.Time) string {
+	return deadline.UTC().Format(time.RFC3339)
+}
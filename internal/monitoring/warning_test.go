@@ -0,0 +1,199 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+func newWarningConfig() *config.Config {
+	return &config.Config{
+		Watchdog: config.WatchdogConfig{
+			Namespaces:     []string{"default"},
+			MaxPodLifetime: time.Hour,
+			Warning: config.WarningConfig{
+				Enabled:  true,
+				LeadTime: 15 * time.Minute,
+			},
+		},
+	}
+}
+
+func newAgedPod(age time.Duration, annotations map[string]string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "sandbox",
+			Namespace:         "default",
+			Annotations:       annotations,
+			CreationTimestamp: metav1.Time{Time: time.Now().Add(-age)},
+		},
+		Status: v1.PodStatus{PodIP: "127.0.0.1"},
+	}
+}
+
+func TestWarningDue(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := newWarningConfig().Watchdog.Warning
+	pod := &v1.Pod{}
+
+	require.False(t, warningDue(cfg, pod, Verdict{Deadline: now.Add(time.Hour)}, now))
+	require.True(t, warningDue(cfg, pod, Verdict{Deadline: now.Add(10 * time.Minute)}, now))
+
+	// Already warned about this deadline
+	pod.Annotations = map[string]string{AnnotationExpiresAt: formatDeadline(now.Add(10 * time.Minute))}
+	require.False(t, warningDue(cfg, pod, Verdict{Deadline: now.Add(10 * time.Minute)}, now))
+
+	// The deadline moved, warn again
+	require.True(t, warningDue(cfg, pod, Verdict{Deadline: now.Add(5 * time.Minute)}, now))
+
+	cfg.Enabled = false
+	require.False(t, warningDue(cfg, &v1.Pod{}, Verdict{Deadline: now.Add(time.Minute)}, now))
+}
+
+func TestTerminationAllowed(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := newWarningConfig().Watchdog.Warning
+
+	require.False(t, terminationAllowed(cfg, &v1.Pod{}, Verdict{Deadline: now.Add(-time.Minute)}, now))
+	require.True(t, terminationAllowed(cfg, &v1.Pod{}, Verdict{Deadline: now.Add(-15 * time.Minute)}, now))
+
+	warned := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{AnnotationExpiresAt: "2026-01-01T11:59:00Z"}}}
+	require.True(t, terminationAllowed(cfg, warned, Verdict{Deadline: now.Add(-time.Minute)}, now))
+
+	// Warned about an older deadline, such as one before an extension
+	stale := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{AnnotationExpiresAt: "2026-01-01T10:00:00Z"}}}
+	require.False(t, terminationAllowed(cfg, stale, Verdict{Deadline: now.Add(-time.Minute)}, now))
+	require.True(t, terminationAllowed(cfg, stale, Verdict{Deadline: now.Add(-15 * time.Minute)}, now), "the lead time passed")
+
+	cfg.Enabled = false
+	require.True(t, terminationAllowed(cfg, &v1.Pod{}, Verdict{Deadline: now.Add(-time.Minute)}, now))
+}
+
+func TestMonitorAndCleanupWarnings(t *testing.T) {
+	t.Run("warns a pod within the lead time", func(t *testing.T) {
+		received := make(chan Warning, 1)
+		pod := newAgedPod(50*time.Minute, nil)
+		podServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Equal(t, "/watchdog/expiry", r.URL.Path)
+			var warning Warning
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&warning))
+			received <- warning
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer podServer.Close()
+
+		_, port, err := net.SplitHostPort(podServer.Listener.Addr().String())
+		require.NoError(t, err)
+		cfg := newWarningConfig()
+		cfg.Watchdog.Warning.HTTP = config.WarningHTTPConfig{Enabled: true, Path: "/watchdog/expiry", Timeout: time.Second}
+		cfg.Watchdog.Warning.HTTP.Port, err = strconv.Atoi(port)
+		require.NoError(t, err)
+
+		clientset := fake.NewSimpleClientset(pod)
+		observer := &recordingObserver{}
+		pm := NewPodMonitor(clientset, cfg, nil, nil, zap.NewNop().Sugar(), nil, nil, nil, observer)
+		require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+
+		updated, err := clientset.CoreV1().Pods("default").Get(context.TODO(), "sandbox", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, formatDeadline(pod.CreationTimestamp.Add(time.Hour)), updated.Annotations[AnnotationExpiresAt])
+
+		require.Len(t, observer.decisions, 1)
+		require.Equal(t, ActionWarned, observer.decisions[0].Action)
+
+		warning := <-received
+		require.Equal(t, "sandbox", warning.Pod)
+		require.Equal(t, "default", warning.Namespace)
+		require.Equal(t, ReasonMaxLifetime, warning.Reason)
+		require.InDelta(t, 600, warning.SecondsRemaining, 5)
+	})
+
+	t.Run("defers termination of an unwarned pod", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newAgedPod(65*time.Minute, nil))
+		pm := NewPodMonitor(clientset, newWarningConfig(), nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+
+		// First cycle only warns
+		require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+		warned, err := clientset.CoreV1().Pods("default").Get(context.TODO(), "sandbox", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Contains(t, warned.Annotations, AnnotationExpiresAt)
+
+		// Second cycle terminates the warned pod
+		require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+		_, err = clientset.CoreV1().Pods("default").Get(context.TODO(), "sandbox", metav1.GetOptions{})
+		require.Error(t, err)
+	})
+
+	t.Run("warns again about a moved deadline", func(t *testing.T) {
+		pod := newAgedPod(65*time.Minute, map[string]string{AnnotationExpiresAt: "2026-01-01T10:00:00Z"})
+		clientset := fake.NewSimpleClientset(pod)
+		pm := NewPodMonitor(clientset, newWarningConfig(), nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+
+		require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+		warned, err := clientset.CoreV1().Pods("default").Get(context.TODO(), "sandbox", metav1.GetOptions{})
+		require.NoError(t, err, "a warning about an old deadline does not allow the termination")
+		require.Equal(t, formatDeadline(pod.CreationTimestamp.Add(time.Hour)), warned.Annotations[AnnotationExpiresAt])
+	})
+
+	t.Run("terminates once the lead time passed without a warning", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newAgedPod(80*time.Minute, nil))
+		pm := NewPodMonitor(clientset, newWarningConfig(), nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+
+		require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+		_, err := clientset.CoreV1().Pods("default").Get(context.TODO(), "sandbox", metav1.GetOptions{})
+		require.Error(t, err)
+	})
+
+	t.Run("does not annotate in dry run mode", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newAgedPod(50*time.Minute, nil))
+		cfg := newWarningConfig()
+		cfg.Watchdog.DryRun = true
+		pm := NewPodMonitor(clientset, cfg, nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+
+		require.NoError(t, pm.MonitorAndCleanup(context.Background()))
+		pod, err := clientset.CoreV1().Pods("default").Get(context.TODO(), "sandbox", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.NotContains(t, pod.Annotations, AnnotationExpiresAt)
+	})
+}
+
+func TestPostWarning(t *testing.T) {
+	t.Run("fails without a pod IP", func(t *testing.T) {
+		cfg := newWarningConfig()
+		pm := NewPodMonitor(fake.NewSimpleClientset(), cfg, nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+		err := pm.postWarning(context.Background(), cfg.Watchdog.Warning.HTTP, &v1.Pod{}, Verdict{}, time.Now())
+		require.Error(t, err)
+	})
+
+	t.Run("fails on an error status", func(t *testing.T) {
+		podServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer podServer.Close()
+
+		_, port, err := net.SplitHostPort(podServer.Listener.Addr().String())
+		require.NoError(t, err)
+		cfg := newWarningConfig()
+		cfg.Watchdog.Warning.HTTP = config.WarningHTTPConfig{Enabled: true, Path: "/", Timeout: time.Second}
+		cfg.Watchdog.Warning.HTTP.Port, err = strconv.Atoi(port)
+		require.NoError(t, err)
+
+		pm := NewPodMonitor(fake.NewSimpleClientset(), cfg, nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+		err = pm.postWarning(context.Background(), cfg.Watchdog.Warning.HTTP, newAgedPod(time.Minute, nil), Verdict{Deadline: time.Now()}, time.Now())
+		require.ErrorContains(t, err, "500")
+	})
+}
@@ -0,0 +1,21 @@
+// Package notify sends watchdog decisions to outbound HTTP webhooks.
+//
+// The Notifier observes the decisions made by the monitoring package and turns
+// them into notifications for chat and ticketing tools:
+//   - warned: a pod was warned about its upcoming expiry
+//   - expired: a pod expired but was left running because of dry-run mode
+//   - terminated: an expired pod was deleted
+//   - failed: deleting an expired pod failed
+//
+// Each webhook subscribes to a subset of events and renders its payload with a
+// text/template, so it can match Slack, Teams or any custom schema. Templates
+// receive a Notification and can use the json and rfc3339 helpers; without a
+// template the Notification itself is posted as JSON. When a secret is set the
+// body is signed with HMAC-SHA256 in the X-Watchdog-Signature header.
+//
+// Rendered notifications are queued in a bounded channel and delivered by a
+// small pool of workers with per-webhook timeouts and retries. When the queue
+// is full notifications are dropped and counted rather than blocking the
+// monitoring cycle. The webhooks follow config reloads, so rotated URLs and
+// secrets apply without a restart.
+package notify
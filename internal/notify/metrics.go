@@ -0,0 +1,32 @@
+package notify
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	resultSent    = "sent"
+	resultFailed  = "failed"
+	resultDropped = "dropped"
+)
+
+// metrics are the metrics of the notifier
+type metrics struct {
+	// notificationsTotal counts notifications by webhook, event and delivery result
+	notificationsTotal *prometheus.CounterVec
+}
+
+// newMetrics registers the notifier metrics, a nil registerer leaves them unregistered
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	factory := promauto.With(registerer)
+	return &metrics{
+		notificationsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "watchdog_notifications_total",
+				Help: "Total number of webhook notifications by webhook, event and result (sent, failed, dropped)",
+			},
+			[]string{"webhook", "event", "result"},
+		),
+	}
+}
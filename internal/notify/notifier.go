@@ -0,0 +1,247 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+// Event is the kind of notification sent to webhooks
+type Event string
+
+const (
+	// EventWarned is sent when a pod is warned about its upcoming expiry
+	EventWarned Event = "warned"
+	// EventExpired is sent when a pod expired but was left running because of dry-run mode
+	// or a pause
+	EventExpired Event = "expired"
+	// EventTerminated is sent when an expired pod was deleted
+	EventTerminated Event = "terminated"
+	// EventFailed is sent when deleting an expired pod failed
+	EventFailed Event = "failed"
+)
+
+// Events lists every event a webhook can subscribe to
+var Events = []Event{EventWarned, EventExpired, EventTerminated, EventFailed}
+
+// Notification is the data passed to the payload templates
+type Notification struct {
+	Event    Event     `json:"event"`
+	Time     time.Time `json:"time"`
+	Pod      Pod       `json:"pod"`
+	Reason   string    `json:"reason"`
+	Deadline time.Time `json:"deadline"`
+	DryRun   bool      `json:"dryRun"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Pod identifies the pod a notification is about
+type Pod struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	UID       string `json:"uid"`
+	Owner     string `json:"owner,omitempty"`
+}
+
+// delivery is a rendered notification waiting in the queue
+type delivery struct {
+	webhook *Webhook
+	event   Event
+	payload []byte
+}
+
+var _ monitoring.Observer = (*Notifier)(nil)
+
+// Notifier posts watchdog decisions to webhooks from a bounded queue, so
+// slow endpoints never hold up a monitoring cycle
+type Notifier struct {
+	webhooks []*Webhook
+	workers  int
+	queue    chan delivery
+	wg       sync.WaitGroup
+	logger   *zap.SugaredLogger
+	metrics  *metrics
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewNotifier creates a new notifier for the configured webhooks
+func NewNotifier(lc fx.Lifecycle, cfg *config.Config, logger *zap.SugaredLogger, registerer prometheus.Registerer) (*Notifier, error) {
+	webhooks, err := newWebhooks(cfg.Notify.Webhooks)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Notifier{
+		webhooks: webhooks,
+		workers:  max(cfg.Notify.Workers, 1),
+		queue:    make(chan delivery, max(cfg.Notify.QueueSize, 1)),
+		logger:   logger.Named("Notifier"),
+		metrics:  newMetrics(registerer),
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: n.Start,
+		OnStop:  n.Shutdown,
+	})
+
+	return n, nil
+}
+
+// newWebhooks creates the webhooks of the configuration
+func newWebhooks(configs []config.WebhookConfig) ([]*Webhook, error) {
+	webhooks := make([]*Webhook, 0, len(configs))
+	for _, webhookConfig := range configs {
+		webhook, err := NewWebhook(webhookConfig)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, nil
+}
+
+// Start launches the delivery workers, even without webhooks as a reload may
+// add some
+func (n *Notifier) Start(_ context.Context) error {
+	n.logger.Infow("Starting notifier", "webhooks", len(n.webhooks), "workers", n.workers)
+	for range n.workers {
+		n.wg.Add(1)
+		go n.work()
+	}
+	return nil
+}
+
+// Shutdown stops accepting notifications and waits for the queue to drain
+func (n *Notifier) Shutdown(ctx context.Context) error {
+	n.mu.Lock()
+	n.closed = true
+	close(n.queue)
+	n.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		n.logger.Warnw("Notifier stopped before the queue drained", "pending", len(n.queue))
+		return ctx.Err()
+	}
+}
+
+// Reload swaps in the webhooks of a new configuration, so rotated URLs, secrets
+// and headers apply to the next notifications. Queued ones are delivered with the
+// webhooks they were rendered for. The queue size and workers need a restart.
+func (n *Notifier) Reload(cfg *config.Config) {
+	webhooks, err := newWebhooks(cfg.Notify.Webhooks)
+	if err != nil {
+		n.logger.Errorw("Failed to reload the webhooks, keeping the running ones", "error", err)
+		return
+	}
+
+	n.mu.Lock()
+	n.webhooks = webhooks
+	n.mu.Unlock()
+}
+
+// Observe renders the decision for every subscribed webhook and queues it without blocking
+func (n *Notifier) Observe(_ context.Context, decision monitoring.Decision) {
+	event, ok := eventFor(decision.Action)
+	if !ok {
+		return
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.closed {
+		return
+	}
+
+	notification := newNotification(event, decision)
+	for _, webhook := range n.webhooks {
+		if !webhook.Wants(event) {
+			continue
+		}
+
+		payload, err := webhook.Render(notification)
+		if err != nil {
+			n.logger.Errorw("Failed to render notification", "webhook", webhook.Name(), "event", event, "error", err)
+			n.metrics.notificationsTotal.WithLabelValues(webhook.Name(), string(event), resultFailed).Inc()
+			continue
+		}
+
+		select {
+		case n.queue <- delivery{webhook: webhook, event: event, payload: payload}:
+		default:
+			n.logger.Warnw("Notification queue is full, dropping notification", "webhook", webhook.Name(), "event", event)
+			n.metrics.notificationsTotal.WithLabelValues(webhook.Name(), string(event), resultDropped).Inc()
+		}
+	}
+}
+
+// work delivers queued notifications until the queue is closed
+func (n *Notifier) work() {
+	defer n.wg.Done()
+
+	for d := range n.queue {
+		if err := d.webhook.Send(context.Background(), d.event, d.payload); err != nil {
+			n.logger.Errorw("Failed to deliver notification", "webhook", d.webhook.Name(), "event", d.event, "error", err)
+			n.metrics.notificationsTotal.WithLabelValues(d.webhook.Name(), string(d.event), resultFailed).Inc()
+			continue
+		}
+		n.metrics.notificationsTotal.WithLabelValues(d.webhook.Name(), string(d.event), resultSent).Inc()
+	}
+}
+
+// eventFor maps a monitoring action to the notification event
+func eventFor(action monitoring.Action) (Event, bool) {
+	switch action {
+	case monitoring.ActionWarned:
+		return EventWarned, true
+	case monitoring.ActionDryRun, monitoring.ActionPaused:
+		return EventExpired, true
+	case monitoring.ActionTerminated:
+		return EventTerminated, true
+	case monitoring.ActionFailed:
+		return EventFailed, true
+	default:
+		return "", false
+	}
+}
+
+func newNotification(event Event, decision monitoring.Decision) Notification {
+	pod := decision.Pod
+	notification := Notification{
+		Event:    event,
+		Time:     time.Now().UTC(),
+		Reason:   decision.Verdict.Reason,
+		Deadline: decision.Verdict.Deadline.UTC(),
+		DryRun:   decision.DryRun,
+		Pod: Pod{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			UID:       string(pod.UID),
+		},
+	}
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		notification.Pod.Owner = owner.Kind + "/" + owner.Name
+	}
+	if decision.Err != nil {
+		notification.Error = decision.Err.Error()
+	}
+	return notification
+}
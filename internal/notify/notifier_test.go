@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+func newTestDecision(action monitoring.Action) monitoring.Decision {
+	controller := true
+	return monitoring.Decision{
+		Pod: &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "sandbox-1",
+				Namespace: "default",
+				UID:       "uid-1",
+				OwnerReferences: []metav1.OwnerReference{{
+					Kind:       "Job",
+					Name:       "sandbox",
+					Controller: &controller,
+				}},
+			},
+		},
+		Action: action,
+		Verdict: monitoring.Verdict{
+			Expired:  true,
+			Reason:   monitoring.ReasonTTLLabel,
+			Deadline: time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+func TestNewNotifier(t *testing.T) {
+	cfg := &config.Config{Notify: config.NotifyConfig{
+		Webhooks: []config.WebhookConfig{{Name: "broken"}},
+	}}
+	_, err := NewNotifier(fxtest.NewLifecycle(t), cfg, zap.NewNop().Sugar(), nil)
+	require.Error(t, err)
+}
+
+func TestNotifierDelivers(t *testing.T) {
+	received := make(chan Notification, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var notification Notification
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&notification))
+		received <- notification
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Notify: config.NotifyConfig{
+		QueueSize: 10,
+		Workers:   2,
+		Webhooks: []config.WebhookConfig{
+			{Name: "terminations", URL: server.URL, Events: []string{"terminated", "failed"}},
+		},
+	}}
+	notifier, err := NewNotifier(fxtest.NewLifecycle(t), cfg, zap.NewNop().Sugar(), nil)
+	require.NoError(t, err)
+	require.NoError(t, notifier.Start(context.Background()))
+
+	failed := newTestDecision(monitoring.ActionFailed)
+	failed.Err = errors.New("forbidden")
+
+	ctx := context.Background()
+	notifier.Observe(ctx, newTestDecision(monitoring.ActionTerminated))
+	notifier.Observe(ctx, newTestDecision(monitoring.ActionDryRun))  // not subscribed
+	notifier.Observe(ctx, newTestDecision(monitoring.ActionInvalid)) // never notified
+	notifier.Observe(ctx, failed)
+
+	require.NoError(t, notifier.Shutdown(ctx))
+	close(received)
+
+	byEvent := map[Event]Notification{}
+	for notification := range received {
+		byEvent[notification.Event] = notification
+	}
+	require.Len(t, byEvent, 2)
+	require.Equal(t, Pod{Name: "sandbox-1", Namespace: "default", UID: "uid-1", Owner: "Job/sandbox"}, byEvent[EventTerminated].Pod)
+	require.Equal(t, "TTLExpired", byEvent[EventTerminated].Reason)
+	require.Equal(t, "forbidden", byEvent[EventFailed].Error)
+}
+
+func TestNotifierReload(t *testing.T) {
+	received := make(chan bool, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received <- r.Header.Get(SignatureHeader) == Sign("new", body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Started without webhooks, a reload adds one
+	notifier, err := NewNotifier(fxtest.NewLifecycle(t), &config.Config{Notify: config.NotifyConfig{QueueSize: 10}}, zap.NewNop().Sugar(), nil)
+	require.NoError(t, err)
+	require.NoError(t, notifier.Start(context.Background()))
+
+	ctx := context.Background()
+	notifier.Reload(&config.Config{Notify: config.NotifyConfig{
+		Webhooks: []config.WebhookConfig{{Name: "rotated", URL: server.URL, Secret: "new"}},
+	}})
+	notifier.Observe(ctx, newTestDecision(monitoring.ActionTerminated))
+	require.True(t, <-received, "signed with the reloaded secret")
+
+	// An invalid configuration keeps the running webhooks
+	notifier.Reload(&config.Config{Notify: config.NotifyConfig{
+		Webhooks: []config.WebhookConfig{{Name: "broken"}},
+	}})
+	notifier.Observe(ctx, newTestDecision(monitoring.ActionTerminated))
+	require.True(t, <-received)
+
+	require.NoError(t, notifier.Shutdown(ctx))
+}
+
+func TestNotifierDropsWhenFull(t *testing.T) {
+	cfg := &config.Config{Notify: config.NotifyConfig{
+		QueueSize: 1,
+		Webhooks:  []config.WebhookConfig{{Name: "full", URL: "http://127.0.0.1:1"}},
+	}}
+	notifier, err := NewNotifier(fxtest.NewLifecycle(t), cfg, zap.NewNop().Sugar(), nil)
+	require.NoError(t, err)
+
+	// Workers are not started, so the second notification finds the queue full
+	notifier.Observe(context.Background(), newTestDecision(monitoring.ActionTerminated))
+	notifier.Observe(context.Background(), newTestDecision(monitoring.ActionTerminated))
+	require.InDelta(t, 1, testutil.ToFloat64(notifier.metrics.notificationsTotal.WithLabelValues("full", "terminated", resultDropped)), 0)
+}
+
+func TestNotifierClosed(t *testing.T) {
+	cfg := &config.Config{Notify: config.NotifyConfig{
+		Webhooks: []config.WebhookConfig{{Name: "closed", URL: "http://127.0.0.1:1"}},
+	}}
+	notifier, err := NewNotifier(fxtest.NewLifecycle(t), cfg, zap.NewNop().Sugar(), nil)
+	require.NoError(t, err)
+	require.NoError(t, notifier.Shutdown(context.Background()))
+
+	// Decisions arriving after shutdown are ignored
+	require.NotPanics(t, func() {
+		notifier.Observe(context.Background(), newTestDecision(monitoring.ActionTerminated))
+	})
+}
+
+func TestEventFor(t *testing.T) {
+	tests := map[monitoring.Action]Event{
+		monitoring.ActionWarned:     EventWarned,
+		monitoring.ActionDryRun:     EventExpired,
+		monitoring.ActionPaused:     EventExpired,
+		monitoring.ActionTerminated: EventTerminated,
+		monitoring.ActionFailed:     EventFailed,
+	}
+	for action, expected := range tests {
+		event, ok := eventFor(action)
+		require.True(t, ok)
+		require.Equal(t, expected, event)
+	}
+
+	_, ok := eventFor(monitoring.ActionInvalid)
+	require.False(t, ok)
+}
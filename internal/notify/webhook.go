@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"text/template"
+	"time"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+const (
+	// SignatureHeader carries the hex HMAC-SHA256 of the request body, prefixed with "sha256="
+	SignatureHeader = "X-Watchdog-Signature"
+	// EventHeader carries the notification event
+	EventHeader = "X-Watchdog-Event"
+
+	defaultTimeout     = 5 * time.Second
+	defaultBackoff     = 500 * time.Millisecond
+	defaultMaxAttempts = 3
+	defaultContentType = "application/json"
+)
+
+// defaultTemplate renders the whole notification as JSON
+const defaultTemplate = `{{ json . }}`
+
+// Webhook renders notifications and delivers them to one HTTP endpoint
+type Webhook struct {
+	config   config.WebhookConfig
+	template *template.Template
+	client   *http.Client
+}
+
+// NewWebhook parses the webhook's payload template
+func NewWebhook(cfg config.WebhookConfig) (*Webhook, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("webhook %q: url is required", cfg.Name)
+	}
+	for _, event := range cfg.Events {
+		if !slices.Contains(Events, Event(event)) {
+			return nil, fmt.Errorf("webhook %q: unknown event %q", cfg.Name, event)
+		}
+	}
+
+	text := cfg.Template
+	if text == "" {
+		text = defaultTemplate
+	}
+	tmpl, err := template.New(cfg.Name).Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("webhook %q: %w", cfg.Name, err)
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = defaultBackoff
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.ContentType == "" {
+		cfg.ContentType = defaultContentType
+	}
+
+	return &Webhook{
+		config:   cfg,
+		template: tmpl,
+		client:   &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+// Name returns the configured name of the webhook
+func (w *Webhook) Name() string {
+	return w.config.Name
+}
+
+// Wants reports whether the webhook subscribed to the event, no subscriptions means every event
+func (w *Webhook) Wants(event Event) bool {
+	return len(w.config.Events) == 0 || slices.Contains(w.config.Events, string(event))
+}
+
+// Render executes the payload template for a notification
+func (w *Webhook) Render(notification Notification) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := w.template.Execute(&buf, notification); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Send delivers a rendered payload, retrying network errors, 429 and 5xx responses
+func (w *Webhook) Send(ctx context.Context, event Event, payload []byte) error {
+	delay := w.config.Backoff
+
+	var err error
+	for attempt := 1; attempt <= w.config.MaxAttempts; attempt++ {
+		var retryable bool
+		retryable, err = w.post(ctx, event, payload)
+		if err == nil || !retryable || attempt == w.config.MaxAttempts {
+			break
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+	return err
+}
+
+// post makes one delivery attempt and reports whether a failure is worth retrying
+func (w *Webhook) post(ctx context.Context, event Event, payload []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", w.config.ContentType)
+	req.Header.Set(EventHeader, string(event))
+	for key, value := range w.config.Headers {
+		req.Header.Set(key, value)
+	}
+	if w.config.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.config.Secret, payload))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		return retryable, fmt.Errorf("webhook %q answered %s", w.config.Name, resp.Status)
+	}
+	return false, nil
+}
+
+// Sign returns the signature header value for a payload
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+var templateFuncs = template.FuncMap{
+	// json encodes a value, so strings can be embedded in JSON payloads safely
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	// rfc3339 formats a time in UTC
+	"rfc3339": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+}
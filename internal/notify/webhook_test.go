@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+func newTestNotification() Notification {
+	return Notification{
+		Event:    EventTerminated,
+		Time:     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Pod:      Pod{Name: "sandbox-1", Namespace: "default", UID: "uid-1", Owner: "Job/sandbox"},
+		Reason:   "TTLExpired",
+		Deadline: time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestNewWebhook(t *testing.T) {
+	t.Run("requires a url", func(t *testing.T) {
+		_, err := NewWebhook(config.WebhookConfig{Name: "chat"})
+		require.ErrorContains(t, err, "url is required")
+	})
+
+	t.Run("rejects unknown events", func(t *testing.T) {
+		_, err := NewWebhook(config.WebhookConfig{Name: "chat", URL: "http://example.com", Events: []string{"exploded"}})
+		require.ErrorContains(t, err, `unknown event "exploded"`)
+	})
+
+	t.Run("rejects invalid templates", func(t *testing.T) {
+		_, err := NewWebhook(config.WebhookConfig{Name: "chat", URL: "http://example.com", Template: "{{ .Pod.Name "})
+		require.Error(t, err)
+	})
+
+	t.Run("applies defaults", func(t *testing.T) {
+		webhook, err := NewWebhook(config.WebhookConfig{Name: "chat", URL: "http://example.com"})
+		require.NoError(t, err)
+		require.Equal(t, defaultTimeout, webhook.config.Timeout)
+		require.Equal(t, defaultBackoff, webhook.config.Backoff)
+		require.Equal(t, defaultMaxAttempts, webhook.config.MaxAttempts)
+		require.Equal(t, defaultContentType, webhook.config.ContentType)
+	})
+}
+
+func TestWebhookWants(t *testing.T) {
+	all, err := NewWebhook(config.WebhookConfig{Name: "all", URL: "http://example.com"})
+	require.NoError(t, err)
+	require.True(t, all.Wants(EventFailed))
+
+	some, err := NewWebhook(config.WebhookConfig{Name: "some", URL: "http://example.com", Events: []string{"terminated"}})
+	require.NoError(t, err)
+	require.True(t, some.Wants(EventTerminated))
+	require.False(t, some.Wants(EventFailed))
+}
+
+func TestWebhookRender(t *testing.T) {
+	t.Run("default template posts the notification as json", func(t *testing.T) {
+		webhook, err := NewWebhook(config.WebhookConfig{Name: "raw", URL: "http://example.com"})
+		require.NoError(t, err)
+
+		payload, err := webhook.Render(newTestNotification())
+		require.NoError(t, err)
+
+		var decoded Notification
+		require.NoError(t, json.Unmarshal(payload, &decoded))
+		require.Equal(t, newTestNotification(), decoded)
+	})
+
+	t.Run("custom template", func(t *testing.T) {
+		webhook, err := NewWebhook(config.WebhookConfig{
+			Name:     "slack",
+			URL:      "http://example.com",
+			Template: `{"text": {{ json (printf "%s/%s %s (%s, deadline %s)" .Pod.Namespace .Pod.Name .Event .Reason (rfc3339 .Deadline)) }}}`,
+		})
+		require.NoError(t, err)
+
+		payload, err := webhook.Render(newTestNotification())
+		require.NoError(t, err)
+		require.JSONEq(t, `{"text": "default/sandbox-1 terminated (TTLExpired, deadline 2026-01-02T03:00:00Z)"}`, string(payload))
+	})
+
+	t.Run("missing keys fail", func(t *testing.T) {
+		webhook, err := NewWebhook(config.WebhookConfig{Name: "bad", URL: "http://example.com", Template: "{{ .Nope }}"})
+		require.NoError(t, err)
+
+		_, err = webhook.Render(newTestNotification())
+		require.Error(t, err)
+	})
+}
+
+func TestWebhookSend(t *testing.T) {
+	t.Run("posts signed payload with headers", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.Equal(t, `{"ok":true}`, string(body))
+			require.Equal(t, Sign("s3cr3t", body), r.Header.Get(SignatureHeader))
+			require.Equal(t, "terminated", r.Header.Get(EventHeader))
+			require.Equal(t, "sandbox", r.Header.Get("X-Team"))
+			require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		webhook, err := NewWebhook(config.WebhookConfig{
+			Name:    "signed",
+			URL:     server.URL,
+			Secret:  "s3cr3t",
+			Headers: map[string]string{"x-team": "sandbox"},
+		})
+		require.NoError(t, err)
+		require.NoError(t, webhook.Send(context.Background(), EventTerminated, []byte(`{"ok":true}`)))
+	})
+
+	t.Run("retries server errors", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		webhook, err := NewWebhook(config.WebhookConfig{Name: "flaky", URL: server.URL, Backoff: time.Millisecond})
+		require.NoError(t, err)
+		require.NoError(t, webhook.Send(context.Background(), EventFailed, []byte("{}")))
+		require.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		webhook, err := NewWebhook(config.WebhookConfig{Name: "strict", URL: server.URL, Backoff: time.Millisecond})
+		require.NoError(t, err)
+		require.ErrorContains(t, webhook.Send(context.Background(), EventFailed, []byte("{}")), "400")
+		require.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestSign(t *testing.T) {
+	// Known HMAC-SHA256 test vector (RFC 4231 test case 2)
+	require.Equal(t,
+		"sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		Sign("Jefe", []byte("what do ya want for nothing?")))
+}
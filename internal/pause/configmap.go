@@ -0,0 +1,119 @@
+package pause
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Keys of the pause ConfigMap
+const (
+	// KeyPaused pauses every policy when "true"
+	KeyPaused = "paused"
+	// KeyPolicies lists the paused policies, separated by commas
+	KeyPolicies = "policies"
+	// KeyBy records who paused terminations
+	KeyBy = "by"
+	// KeyReason records why terminations were paused
+	KeyReason = "reason"
+	// KeyUntil is the RFC 3339 time at which terminations resume on their own
+	KeyUntil = "until"
+)
+
+// watchConfigMap keeps the ConfigMap pauses in sync with the ConfigMap. A failed
+// first sync is only logged, terminations stay paused until it succeeds.
+func (s *Switch) watchConfigMap(ctx context.Context) error {
+	namespace, name := s.config.ConfigMap.Namespace, s.config.ConfigMap.Name
+
+	factory := informers.NewSharedInformerFactoryWithOptions(s.clientset, 0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}),
+	)
+	informer := factory.Core().V1().ConfigMaps().Informer()
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { s.apply(obj) },
+		UpdateFunc: func(_, obj any) { s.apply(obj) },
+		DeleteFunc: func(any) { s.replace(SourceConfigMap, nil) },
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.synced = informer.HasSynced
+	s.mu.Unlock()
+
+	s.logger.Infow("Watching pause ConfigMap", "namespace", namespace, "name", name)
+	factory.Start(s.stopChannel)
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		s.logger.Errorw("Pause ConfigMap not synced yet, terminations stay paused", "namespace", namespace, "name", name)
+	}
+	return nil
+}
+
+// apply replaces the ConfigMap pauses with the content of the ConfigMap
+func (s *Switch) apply(obj any) {
+	configMap, ok := obj.(*v1.ConfigMap)
+	if !ok {
+		return
+	}
+
+	pauses, err := parseConfigMap(configMap.Data, s.now())
+	if err != nil {
+		// Keep the previous state, an edit with a typo must not resume terminations
+		s.logger.Errorw("Ignoring invalid pause ConfigMap", "namespace", configMap.Namespace, "name", configMap.Name, "error", err)
+		return
+	}
+	s.replace(SourceConfigMap, pauses)
+}
+
+// parseConfigMap returns the pauses described by the ConfigMap data, dropping those already over
+func parseConfigMap(data map[string]string, now time.Time) ([]Pause, error) {
+	template := Pause{
+		Source: SourceConfigMap,
+		By:     data[KeyBy],
+		Reason: data[KeyReason],
+		Since:  now,
+	}
+	if until := strings.TrimSpace(data[KeyUntil]); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", KeyUntil, err)
+		}
+		if !t.After(now) {
+			return nil, nil
+		}
+		template.Until = t
+	}
+
+	var pauses []Pause
+	if paused := strings.TrimSpace(data[KeyPaused]); paused != "" {
+		global, err := strconv.ParseBool(paused)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", KeyPaused, err)
+		}
+		if global {
+			p := template
+			p.Policy = AllPolicies
+			pauses = append(pauses, p)
+		}
+	}
+	for policy := range strings.SplitSeq(data[KeyPolicies], ",") {
+		if policy = strings.TrimSpace(policy); policy != "" {
+			p := template
+			p.Policy = policy
+			pauses = append(pauses, p)
+		}
+	}
+	return pauses, nil
+}
@@ -0,0 +1,143 @@
+package pause
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+func TestParseConfigMap(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("global and policy pauses", func(t *testing.T) {
+		pauses, err := parseConfigMap(map[string]string{
+			KeyPaused:   "true",
+			KeyPolicies: "sandboxes, previews,",
+			KeyBy:       "alice",
+			KeyReason:   "INC-42",
+			KeyUntil:    "2026-01-02T04:00:00Z",
+		}, now)
+		require.NoError(t, err)
+
+		until := time.Date(2026, 1, 2, 4, 0, 0, 0, time.UTC)
+		template := Pause{Source: SourceConfigMap, By: "alice", Reason: "INC-42", Since: now, Until: until}
+		global, sandboxes, previews := template, template, template
+		global.Policy, sandboxes.Policy, previews.Policy = AllPolicies, "sandboxes", "previews"
+		require.Equal(t, []Pause{global, sandboxes, previews}, pauses)
+	})
+
+	t.Run("not paused", func(t *testing.T) {
+		pauses, err := parseConfigMap(map[string]string{KeyPaused: "false"}, now)
+		require.NoError(t, err)
+		require.Empty(t, pauses)
+
+		pauses, err = parseConfigMap(nil, now)
+		require.NoError(t, err)
+		require.Empty(t, pauses)
+	})
+
+	t.Run("pause already over", func(t *testing.T) {
+		pauses, err := parseConfigMap(map[string]string{KeyPaused: "true", KeyUntil: "2026-01-02T03:00:00Z"}, now)
+		require.NoError(t, err)
+		require.Empty(t, pauses)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		_, err := parseConfigMap(map[string]string{KeyPaused: "yes please"}, now)
+		require.ErrorContains(t, err, "invalid paused")
+		_, err = parseConfigMap(map[string]string{KeyPaused: "true", KeyUntil: "tomorrow"}, now)
+		require.ErrorContains(t, err, "invalid until")
+	})
+}
+
+func TestWatchConfigMap(t *testing.T) {
+	cfg := &config.Config{Pause: config.PauseConfig{
+		ConfigMap: config.PauseConfigMapConfig{Enabled: true, Namespace: "watchdog", Name: "watchdog-pause"},
+	}}
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "watchdog-pause", Namespace: "watchdog"},
+		Data:       map[string]string{KeyPaused: "true", KeyBy: "alice"},
+	}
+	clientset := fake.NewSimpleClientset(configMap)
+	s := NewSwitch(fxtest.NewLifecycle(t), clientset, cfg, zap.NewNop().Sugar(), nil)
+
+	ctx := context.Background()
+	require.NoError(t, s.Start(ctx))
+	t.Cleanup(func() { _ = s.Shutdown(ctx) })
+
+	description, paused := s.Paused("default")
+	require.True(t, paused)
+	require.Equal(t, "paused via configmap by alice", description)
+
+	// Only the pause ConfigMap is read, as the optional Role limits it by name
+	lists := 0
+	for _, action := range clientset.Actions() {
+		if list, ok := action.(k8stesting.ListAction); ok {
+			lists++
+			require.Equal(t, "watchdog", list.GetNamespace())
+			require.Equal(t, "metadata.name=watchdog-pause", list.GetListRestrictions().Fields.String())
+		}
+	}
+	require.Positive(t, lists)
+
+	// A typo keeps the previous state
+	configMap.Data[KeyPaused] = "nope"
+	_, err := clientset.CoreV1().ConfigMaps("watchdog").Update(ctx, configMap, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	_, paused = s.Paused("default")
+	require.True(t, paused)
+
+	configMap.Data[KeyPaused] = "false"
+	_, err = clientset.CoreV1().ConfigMaps("watchdog").Update(ctx, configMap, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		_, paused := s.Paused("default")
+		return !paused
+	}, 2*time.Second, 10*time.Millisecond)
+
+	configMap.Data = map[string]string{KeyPolicies: "default"}
+	_, err = clientset.CoreV1().ConfigMaps("watchdog").Update(ctx, configMap, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		_, paused := s.Paused("default")
+		return paused
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, clientset.CoreV1().ConfigMaps("watchdog").Delete(ctx, "watchdog-pause", metav1.DeleteOptions{}))
+	require.Eventually(t, func() bool {
+		return len(s.Status().Pauses) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestUnsyncedConfigMapPauses(t *testing.T) {
+	s := &Switch{pauses: map[key]Pause{}, now: time.Now, synced: func() bool { return false }}
+
+	description, paused := s.Paused("default")
+	require.True(t, paused)
+	require.Equal(t, "paused via configmap: waiting for the pause ConfigMap to sync", description)
+}
+
+func TestConfigMapHealthCheck(t *testing.T) {
+	s := &Switch{pauses: map[key]Pause{}, now: time.Now}
+	require.Empty(t, s.HealthChecks())
+
+	s.config.ConfigMap.Enabled = true
+	checks := s.HealthChecks()
+	require.Len(t, checks, 1)
+	require.Equal(t, "pause-configmap", checks[0].Name)
+	require.ErrorContains(t, checks[0].Run(context.Background()), "pause ConfigMap not synced yet")
+
+	s.synced = func() bool { return true }
+	require.NoError(t, checks[0].Run(context.Background()))
+}
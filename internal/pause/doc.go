@@ -0,0 +1,13 @@
+// Package pause provides the kill switch that stops all pod terminations.
+//
+// During incidents deletions must stop at once, without editing the config file
+// or redeploying. The Switch holds global pauses and pauses of a single policy,
+// each recording who set it, why, and an optional time at which terminations
+// resume on their own. Pauses are set from two sources:
+//   - the HTTP API (POST /api/v1/pause and /api/v1/resume)
+//   - a watched ConfigMap, with the keys paused, policies, by, reason and until
+//
+// Terminations resume once neither source holds a pause. While paused the
+// monitoring cycles keep evaluating pods and report expired ones as paused.
+// The pause state is exported as metrics and shown on /readyz?verbose.
+package pause
@@ -0,0 +1,35 @@
+package pause
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// metrics are the metrics of the pause switch
+type metrics struct {
+	// paused is 1 for every pause in effect, policy "*" is a global pause
+	paused *prometheus.GaugeVec
+	// resumeTime is the Unix time at which a pause lifts on its own
+	resumeTime *prometheus.GaugeVec
+}
+
+// newMetrics registers the pause metrics, a nil registerer leaves them unregistered
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	factory := promauto.With(registerer)
+	return &metrics{
+		paused: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "watchdog_paused",
+				Help: "Pauses in effect by source (api, configmap), policy (* for all) and who set them",
+			},
+			[]string{"source", "policy", "by"},
+		),
+		resumeTime: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "watchdog_pause_resume_time_seconds",
+				Help: "Unix time at which a pause is lifted automatically",
+			},
+			[]string{"source", "policy"},
+		),
+	}
+}
@@ -0,0 +1,338 @@
+package pause
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/health"
+)
+
+const (
+	// SourceAPI marks pauses set through the HTTP API
+	SourceAPI = "api"
+	// SourceConfigMap marks pauses set through the watched ConfigMap
+	SourceConfigMap = "configmap"
+
+	// AllPolicies is the policy of a global pause
+	AllPolicies = "*"
+)
+
+// Pause stops terminations globally or for a single policy
+type Pause struct {
+	Source string    `json:"source"`
+	Policy string    `json:"policy"`
+	By     string    `json:"by,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+	Since  time.Time `json:"since"`
+	Until  time.Time `json:"until,omitzero"`
+}
+
+// Global reports whether the pause applies to every policy
+func (p Pause) Global() bool {
+	return p.Policy == AllPolicies
+}
+
+// String describes the pause for logs and reports
+func (p Pause) String() string {
+	var b strings.Builder
+	if p.Global() {
+		b.WriteString("paused")
+	} else {
+		fmt.Fprintf(&b, "policy %s paused", p.Policy)
+	}
+	fmt.Fprintf(&b, " via %s", p.Source)
+	if p.By != "" {
+		fmt.Fprintf(&b, " by %s", p.By)
+	}
+	if p.Reason != "" {
+		fmt.Fprintf(&b, ": %s", p.Reason)
+	}
+	if !p.Until.IsZero() {
+		fmt.Fprintf(&b, " (until %s)", p.Until.UTC().Format(time.RFC3339))
+	}
+	return b.String()
+}
+
+// active reports whether the pause still holds at the given time
+func (p Pause) active(now time.Time) bool {
+	return p.Until.IsZero() || now.Before(p.Until)
+}
+
+// Status is the pause state of the watchdog
+type Status struct {
+	Paused bool    `json:"paused"`
+	Pauses []Pause `json:"pauses"`
+}
+
+type key struct {
+	source string
+	policy string
+}
+
+// Switch is the kill switch that pauses terminations. Pauses come from the API
+// and from the watched ConfigMap, and terminations resume once neither holds.
+type Switch struct {
+	clientset kubernetes.Interface
+	config    config.PauseConfig
+	logger    *zap.SugaredLogger
+	metrics   *metrics
+	now       func() time.Time
+
+	mu     sync.Mutex
+	pauses map[key]Pause
+	timer  *time.Timer
+	synced func() bool
+
+	stopChannel chan struct{}
+}
+
+// NewSwitch creates a new pause switch
+func NewSwitch(
+	lc fx.Lifecycle,
+	clientset kubernetes.Interface,
+	cfg *config.Config,
+	logger *zap.SugaredLogger,
+	registerer prometheus.Registerer,
+) *Switch {
+	s := &Switch{
+		clientset:   clientset,
+		config:      cfg.Pause,
+		logger:      logger.Named("PauseSwitch"),
+		metrics:     newMetrics(registerer),
+		now:         time.Now,
+		pauses:      map[key]Pause{},
+		stopChannel: make(chan struct{}),
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: s.Start,
+		OnStop:  s.Shutdown,
+	})
+
+	return s
+}
+
+// Start watches the pause ConfigMap when enabled
+func (s *Switch) Start(ctx context.Context) error {
+	if !s.config.ConfigMap.Enabled {
+		return nil
+	}
+	return s.watchConfigMap(ctx)
+}
+
+// Shutdown stops watching the ConfigMap and the auto-resume timer
+func (s *Switch) Shutdown(_ context.Context) error {
+	close(s.stopChannel)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	return nil
+}
+
+// Pause pauses terminations through the API, replacing an earlier API pause of the same policy
+func (s *Switch) Pause(p Pause) Pause {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p.Source = SourceAPI
+	if p.Policy == "" {
+		p.Policy = AllPolicies
+	}
+	p.Since = s.now()
+	s.pauses[key{SourceAPI, p.Policy}] = p
+
+	s.logger.Warnw("Terminations paused", "policy", p.Policy, "by", p.By, "reason", p.Reason, "until", p.Until)
+	s.refresh()
+	return p
+}
+
+// Resume lifts the API pause of a policy, reporting whether there was one
+func (s *Switch) Resume(policy, by string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if policy == "" {
+		policy = AllPolicies
+	}
+	k := key{SourceAPI, policy}
+	if _, ok := s.pauses[k]; !ok {
+		return false
+	}
+	delete(s.pauses, k)
+
+	s.logger.Warnw("Terminations resumed", "policy", policy, "by", by)
+	s.refresh()
+	return true
+}
+
+// Paused reports whether terminations of the policy are paused and describes why.
+// Until the ConfigMap is first synced terminations stay paused, so a pause set
+// while the watchdog was down is never missed.
+func (s *Switch) Paused(policy string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.active() {
+		if p.Global() || p.Policy == policy {
+			return p.String(), true
+		}
+	}
+	return "", false
+}
+
+// Status returns the pauses in effect
+func (s *Switch) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pauses := s.active()
+	status := Status{Pauses: pauses}
+	for _, p := range pauses {
+		if p.Global() {
+			status.Paused = true
+		}
+	}
+	return status
+}
+
+// Detail summarizes the pause state for the readiness endpoint
+func (s *Switch) Detail() (string, string) {
+	status := s.Status()
+	if len(status.Pauses) == 0 {
+		return "pause", "not paused"
+	}
+
+	descriptions := make([]string, 0, len(status.Pauses))
+	for _, p := range status.Pauses {
+		descriptions = append(descriptions, p.String())
+	}
+	return "pause", strings.Join(descriptions, "; ")
+}
+
+// HealthChecks makes the pods unready until the pause ConfigMap, when watched,
+// has been synced
+func (s *Switch) HealthChecks() []health.Check {
+	if !s.config.ConfigMap.Enabled {
+		return nil
+	}
+	return []health.Check{{Name: "pause-configmap", Kind: health.Readiness, Run: s.checkSynced}}
+}
+
+// checkSynced fails until the ConfigMap informer has synced
+func (s *Switch) checkSynced(context.Context) error {
+	s.mu.Lock()
+	synced := s.synced
+	s.mu.Unlock()
+
+	if synced == nil || !synced() {
+		return errors.New("pause ConfigMap not synced yet, terminations stay paused")
+	}
+	return nil
+}
+
+// active returns the unexpired pauses ordered by source and policy
+func (s *Switch) active() []Pause {
+	now := s.now()
+	pauses := make([]Pause, 0, len(s.pauses)+1)
+	if s.synced != nil && !s.synced() {
+		pauses = append(pauses, Pause{
+			Source: SourceConfigMap,
+			Policy: AllPolicies,
+			Reason: "waiting for the pause ConfigMap to sync",
+		})
+	}
+	for _, p := range s.pauses {
+		if p.active(now) {
+			pauses = append(pauses, p)
+		}
+	}
+	slices.SortFunc(pauses, func(a, b Pause) int {
+		return strings.Compare(a.Source+"/"+a.Policy, b.Source+"/"+b.Policy)
+	})
+	return pauses
+}
+
+// replace swaps all pauses of a source, keeping the start time of those that continue
+func (s *Switch) replace(source string, pauses []Pause) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := map[key]Pause{}
+	for k, p := range s.pauses {
+		if k.source == source {
+			previous[k] = p
+			delete(s.pauses, k)
+		}
+	}
+	for _, p := range pauses {
+		k := key{source, p.Policy}
+		if old, ok := previous[k]; ok {
+			p.Since = old.Since
+		} else {
+			s.logger.Warnw("Terminations paused", "source", source, "policy", p.Policy, "by", p.By,
+				"reason", p.Reason, "until", p.Until)
+		}
+		p.Source = source
+		s.pauses[k] = p
+		delete(previous, k)
+	}
+	for k := range previous {
+		s.logger.Warnw("Terminations resumed", "source", source, "policy", k.policy)
+	}
+	s.refresh()
+}
+
+// expire drops the pauses whose auto-resume time has passed
+func (s *Switch) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for k, p := range s.pauses {
+		if !p.active(now) {
+			s.logger.Warnw("Terminations resumed automatically", "source", k.source, "policy", k.policy, "until", p.Until)
+			delete(s.pauses, k)
+		}
+	}
+	s.refresh()
+}
+
+// refresh updates the metrics and schedules the next auto-resume, the lock must be held
+func (s *Switch) refresh() {
+	s.metrics.paused.Reset()
+	s.metrics.resumeTime.Reset()
+
+	var next time.Time
+	for _, p := range s.pauses {
+		s.metrics.paused.WithLabelValues(p.Source, p.Policy, p.By).Set(1)
+		if p.Until.IsZero() {
+			continue
+		}
+		s.metrics.resumeTime.WithLabelValues(p.Source, p.Policy).Set(float64(p.Until.Unix()))
+		if next.IsZero() || p.Until.Before(next) {
+			next = p.Until
+		}
+	}
+
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if !next.IsZero() {
+		s.timer = time.AfterFunc(next.Sub(s.now()), s.expire)
+	}
+}
@@ -0,0 +1,102 @@
+package pause
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+func newTestSwitch(t *testing.T, cfg *config.Config) *Switch {
+	t.Helper()
+	s := NewSwitch(fxtest.NewLifecycle(t), fake.NewSimpleClientset(), cfg, zap.NewNop().Sugar(), nil)
+	t.Cleanup(func() { _ = s.Shutdown(context.Background()) })
+	return s
+}
+
+func TestSwitchPause(t *testing.T) {
+	s := newTestSwitch(t, &config.Config{})
+
+	_, paused := s.Paused("default")
+	require.False(t, paused)
+	name, detail := s.Detail()
+	require.Equal(t, "pause", name)
+	require.Equal(t, "not paused", detail)
+
+	t.Run("global pause covers every policy", func(t *testing.T) {
+		s.Pause(Pause{By: "alice", Reason: "INC-42"})
+
+		description, paused := s.Paused("default")
+		require.True(t, paused)
+		require.Equal(t, "paused via api by alice: INC-42", description)
+		require.True(t, s.Status().Paused)
+		require.InDelta(t, 1, testutil.ToFloat64(s.metrics.paused.WithLabelValues(SourceAPI, AllPolicies, "alice")), 0)
+
+		require.True(t, s.Resume("", "alice"))
+		_, paused = s.Paused("default")
+		require.False(t, paused)
+		require.Equal(t, 0, testutil.CollectAndCount(s.metrics.paused))
+	})
+
+	t.Run("policy pause covers only that policy", func(t *testing.T) {
+		s.Pause(Pause{Policy: "sandboxes", By: "bob"})
+		t.Cleanup(func() { s.Resume("sandboxes", "bob") })
+
+		_, paused := s.Paused("default")
+		require.False(t, paused)
+		description, paused := s.Paused("sandboxes")
+		require.True(t, paused)
+		require.Equal(t, "policy sandboxes paused via api by bob", description)
+		require.False(t, s.Status().Paused)
+	})
+
+	t.Run("resume without a pause", func(t *testing.T) {
+		require.False(t, s.Resume("other", "bob"))
+	})
+}
+
+func TestSwitchAutoResume(t *testing.T) {
+	s := newTestSwitch(t, &config.Config{})
+
+	until := time.Now().Add(50 * time.Millisecond)
+	s.Pause(Pause{By: "alice", Until: until})
+	_, detail := s.Detail()
+	require.Equal(t, "paused via api by alice (until "+until.UTC().Format(time.RFC3339)+")", detail)
+	require.InDelta(t, float64(until.Unix()), testutil.ToFloat64(s.metrics.resumeTime.WithLabelValues(SourceAPI, AllPolicies)), 0)
+
+	// Expired pauses stop applying at once and are dropped by the timer
+	require.Eventually(t, func() bool {
+		_, paused := s.Paused("default")
+		return !paused && testutil.CollectAndCount(s.metrics.paused) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Empty(t, s.Status().Pauses)
+}
+
+func TestSwitchReplace(t *testing.T) {
+	s := newTestSwitch(t, &config.Config{})
+	since := time.Now().Add(-time.Hour)
+
+	s.replace(SourceConfigMap, []Pause{{Policy: AllPolicies, Since: since}})
+	s.replace(SourceConfigMap, []Pause{{Policy: AllPolicies, By: "carol", Since: time.Now()}})
+
+	status := s.Status()
+	require.Len(t, status.Pauses, 1)
+	require.Equal(t, SourceConfigMap, status.Pauses[0].Source)
+	require.Equal(t, "carol", status.Pauses[0].By)
+	require.Equal(t, since, status.Pauses[0].Since, "a continued pause keeps its start time")
+
+	// API pauses are independent of the ConfigMap
+	s.Pause(Pause{Policy: "default"})
+	s.replace(SourceConfigMap, nil)
+	status = s.Status()
+	require.False(t, status.Paused)
+	require.Len(t, status.Pauses, 1)
+	require.Equal(t, SourceAPI, status.Pauses[0].Source)
+}
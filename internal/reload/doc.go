@@ -0,0 +1,18 @@
+// Package reload applies changes of the config file without a restart.
+//
+// The Watcher checks the files the configuration was composed of at the
+// reload.interval: the config file, the files it includes and the files of its
+// conf.d directory, so a file added to conf.d is picked up too, and so is a
+// ConfigMap update swapping the mounted files. A change is parsed from scratch and validated; an invalid one is
+// logged and rejected, keeping the running configuration. A valid one is handed
+// to every Reloadable, which swaps it in atomically:
+//   - the pod monitor, from its next cycle on, a running cycle is not interrupted
+//   - the watchdog server, which reschedules the cycles on a new interval
+//   - the log level
+//   - the notification webhooks, with their rotated secrets
+//
+// Sections read once at startup, such as the HTTP listeners or sharding, only
+// change with a restart, and a reload changing them logs a warning naming them.
+// The outcome of each reload is exported as watchdog_config_reloads_total and
+// watchdog_config_last_reload_successful.
+package reload
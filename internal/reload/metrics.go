@@ -0,0 +1,47 @@
+package reload
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Results of watchdog_config_reloads_total
+const (
+	ResultReloaded = "reloaded"
+	ResultRejected = "rejected"
+)
+
+// metrics are the metrics of the config reloads
+type metrics struct {
+	// reloadsTotal counts the reloads of a changed config file
+	reloadsTotal *prometheus.CounterVec
+	// lastReloadSuccessful is 1 when the last changed config file was applied
+	lastReloadSuccessful prometheus.Gauge
+	// lastReloadSuccess is the Unix time of the last applied config file
+	lastReloadSuccess prometheus.Gauge
+}
+
+// newMetrics registers the reload metrics, a nil registerer leaves them unregistered
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	factory := promauto.With(registerer)
+	m := &metrics{
+		reloadsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "watchdog_config_reloads_total",
+				Help: "Reloads of the changed config file by result (reloaded, rejected)",
+			},
+			[]string{"result"},
+		),
+		lastReloadSuccessful: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "watchdog_config_last_reload_successful",
+			Help: "Whether the last change of the config file was applied (1) or rejected (0)",
+		}),
+		lastReloadSuccess: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "watchdog_config_last_reload_success_timestamp_seconds",
+			Help: "Unix time at which the configuration was last loaded successfully",
+		}),
+	}
+	m.reloadsTotal.WithLabelValues(ResultReloaded)
+	m.reloadsTotal.WithLabelValues(ResultRejected)
+	return m
+}
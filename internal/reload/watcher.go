@@ -0,0 +1,194 @@
+package reload
+
+import (
+	"context"
+	"crypto/sha256"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+// Reloadable is a component that follows the configuration as it is reloaded.
+// Reload must swap in the new configuration without blocking on running work.
+type Reloadable interface {
+	Reload(cfg *config.Config)
+}
+
+// Watcher reloads the config file when its content changes and hands the new
+// configuration to the reloadables once it is valid
+type Watcher struct {
+	path        string
+	source      config.Source
+	config      config.ReloadConfig
+	reloadables []Reloadable
+	logger      *zap.SugaredLogger
+	metrics     *metrics
+	now         func() time.Time
+
+	mu      sync.Mutex
+	current *config.Config
+	digest  [sha256.Size]byte
+
+	stopChannel chan struct{}
+	done        chan struct{}
+}
+
+// NewWatcher creates a new config file watcher
+func NewWatcher(
+	lc fx.Lifecycle,
+	cfg *config.Config,
+	logger *zap.SugaredLogger,
+	registerer prometheus.Registerer,
+	reloadables ...Reloadable,
+) *Watcher {
+	w := &Watcher{
+		path:        cfg.Path(),
+		source:      cfg.Source(),
+		config:      cfg.Reload,
+		reloadables: reloadables,
+		logger:      logger.Named("ConfigWatcher"),
+		metrics:     newMetrics(registerer),
+		now:         time.Now,
+		current:     cfg,
+		stopChannel: make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	// An unreadable file leaves the digest empty, so the first check reloads it
+	if digest, err := w.source.Digest(); err == nil {
+		w.digest = digest
+	}
+	w.metrics.lastReloadSuccessful.Set(1)
+	w.metrics.lastReloadSuccess.Set(float64(w.now().Unix()))
+
+	lc.Append(fx.Hook{
+		OnStart: w.Start,
+		OnStop:  w.Shutdown,
+	})
+
+	return w
+}
+
+// Config returns the configuration last applied
+func (w *Watcher) Config() *config.Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Start checks the config file for changes at the reload interval
+func (w *Watcher) Start(_ context.Context) error {
+	if !w.config.Enabled || w.path == "" {
+		close(w.done)
+		return nil
+	}
+
+	w.logger.Infow("Watching the config file for changes", "path", w.path, "interval", w.config.Interval)
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(w.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				w.Check()
+			case <-w.stopChannel:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown stops watching the config file
+func (w *Watcher) Shutdown(_ context.Context) error {
+	if w.config.Enabled && w.path != "" {
+		close(w.stopChannel)
+	}
+	<-w.done
+	return nil
+}
+
+// Check reloads the configuration when the config file, a file it includes or
+// the files of its conf.d directory changed since the last check. A change that
+// fails to parse or validate is rejected until the files change again.
+func (w *Watcher) Check() {
+	digest, err := w.source.Digest()
+	if err != nil {
+		// A ConfigMap update swaps the file, it may briefly be missing
+		w.logger.Warnw("Failed to read the config file", "path", w.path, "error", err)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if digest == w.digest {
+		return
+	}
+	w.digest = digest
+
+	// The flags and environment variables still override the changed file
+	cfg, err := w.source.Load()
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		w.logger.Errorw("Rejected the changed config file, keeping the running configuration", "path", w.path, "error", err)
+		w.metrics.reloadsTotal.WithLabelValues(ResultRejected).Inc()
+		w.metrics.lastReloadSuccessful.Set(0)
+		return
+	}
+
+	if sections := restartRequired(w.current, cfg); len(sections) > 0 {
+		w.logger.Warnw("Changed settings only apply after a restart", "sections", sections)
+	}
+	for _, reloadable := range w.reloadables {
+		reloadable.Reload(cfg)
+	}
+	w.current = cfg
+
+	w.logger.Infow("Reloaded the config file", "path", w.path, "files", cfg.Files())
+	w.metrics.reloadsTotal.WithLabelValues(ResultReloaded).Inc()
+	w.metrics.lastReloadSuccessful.Set(1)
+	w.metrics.lastReloadSuccess.Set(float64(w.now().Unix()))
+}
+
+// restartRequired returns the changed settings that are read once at startup
+func restartRequired(previous, next *config.Config) []string {
+	sections := []struct {
+		key            string
+		previous, next any
+	}{
+		{"http", previous.HTTP, next.HTTP},
+		{"sharding", previous.Sharding, next.Sharding},
+		{"notifications.queueSize", previous.Notify.QueueSize, next.Notify.QueueSize},
+		{"notifications.workers", previous.Notify.Workers, next.Notify.Workers},
+		{"audit", previous.Audit, next.Audit},
+		{"snapshots", previous.Snapshots, next.Snapshots},
+		{"logArchive", previous.LogArchive, next.LogArchive},
+		{"pause", previous.Pause, next.Pause},
+		{"telemetry", previous.Telemetry, next.Telemetry},
+		{"reload", previous.Reload, next.Reload},
+		{"metrics.prometheus", previous.Metrics.Prometheus, next.Metrics.Prometheus},
+		{"health.checkTimeout", previous.Health.CheckTimeout, next.Health.CheckTimeout},
+		{"logging.mode", previous.Logging.Mode, next.Logging.Mode},
+		{"watchdog.events", previous.Watchdog.Events, next.Watchdog.Events},
+	}
+
+	var changed []string
+	for _, section := range sections {
+		if !reflect.DeepEqual(section.previous, section.next) {
+			changed = append(changed, section.key)
+		}
+	}
+	return changed
+}
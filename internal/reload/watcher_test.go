@@ -0,0 +1,169 @@
+package reload
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+// recorder records the configurations it is handed
+type recorder struct {
+	mu      sync.Mutex
+	configs []*config.Config
+}
+
+func (r *recorder) Reload(cfg *config.Config) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.configs = append(r.configs, cfg)
+}
+
+func (r *recorder) reloaded() []*config.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*config.Config(nil), r.configs...)
+}
+
+// writeConfig writes the config file and loads it
+func writeConfig(t *testing.T, path, content string) *config.Config {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestWatcherCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := writeConfig(t, path, "watchdog:\n  namespaces: [default]\n  scheduleInterval: 1m\n")
+	core, logs := observer.New(zapcore.InfoLevel)
+	r := &recorder{}
+	w := NewWatcher(fxtest.NewLifecycle(t), cfg, zap.New(core).Sugar(), nil, r)
+
+	// An unchanged file is not reloaded
+	w.Check()
+	require.Empty(t, r.reloaded())
+
+	require.NoError(t, os.WriteFile(path, []byte("watchdog:\n  namespaces: [default]\n  scheduleInterval: 2m\nlogging:\n  level: debug\n"), 0o600))
+	w.Check()
+	require.Len(t, r.reloaded(), 1)
+	require.Equal(t, 2*time.Minute, r.reloaded()[0].Watchdog.ScheduleInterval)
+	require.Equal(t, "debug", r.reloaded()[0].Logging.Level)
+	require.Same(t, r.reloaded()[0], w.Config())
+	require.InDelta(t, 1, testutil.ToFloat64(w.metrics.reloadsTotal.WithLabelValues(ResultReloaded)), 0)
+	require.Zero(t, logs.FilterMessage("Changed settings only apply after a restart").Len())
+
+	// An invalid file keeps the running configuration
+	require.NoError(t, os.WriteFile(path, []byte("watchdog:\n  namespaces: [default]\n  scheduleInterval: 0s\n"), 0o600))
+	w.Check()
+	require.Len(t, r.reloaded(), 1)
+	require.Equal(t, 2*time.Minute, w.Config().Watchdog.ScheduleInterval)
+	require.InDelta(t, 1, testutil.ToFloat64(w.metrics.reloadsTotal.WithLabelValues(ResultRejected)), 0)
+	require.InDelta(t, 0, testutil.ToFloat64(w.metrics.lastReloadSuccessful), 0)
+	rejected := logs.FilterMessage("Rejected the changed config file, keeping the running configuration").All()
+	require.Len(t, rejected, 1)
+	require.Contains(t, rejected[0].ContextMap()["error"], "watchdog.scheduleInterval: must be positive")
+
+	// Rejected once, it is not checked again until it changes
+	w.Check()
+	require.InDelta(t, 1, testutil.ToFloat64(w.metrics.reloadsTotal.WithLabelValues(ResultRejected)), 0)
+
+	// So is a file that fails to parse
+	require.NoError(t, os.WriteFile(path, []byte("watchdog: [\n"), 0o600))
+	w.Check()
+	require.InDelta(t, 2, testutil.ToFloat64(w.metrics.reloadsTotal.WithLabelValues(ResultRejected)), 0)
+
+	// Settings read at startup are swapped in, with a warning that they need a restart
+	require.NoError(t, os.WriteFile(path, []byte("watchdog:\n  namespaces: [default]\n  scheduleInterval: 3m\nhttp:\n  addr: :9090\n"), 0o600))
+	w.Check()
+	require.Len(t, r.reloaded(), 2)
+	require.InDelta(t, 1, testutil.ToFloat64(w.metrics.lastReloadSuccessful), 0)
+	restart := logs.FilterMessage("Changed settings only apply after a restart").All()
+	require.Len(t, restart, 1)
+	require.Equal(t, []any{"http"}, restart[0].ContextMap()["sections"])
+}
+
+func TestWatcherStart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := writeConfig(t, path, "reload:\n  interval: 10ms\n")
+	r := &recorder{}
+	lc := fxtest.NewLifecycle(t)
+	NewWatcher(lc, cfg, zap.NewNop().Sugar(), nil, r)
+	lc.RequireStart()
+
+	require.NoError(t, os.WriteFile(path, []byte("reload:\n  interval: 10ms\nwatchdog:\n  namespaces: [default]\n  dryRun: true\n"), 0o600))
+	require.Eventually(t, func() bool {
+		reloaded := r.reloaded()
+		return len(reloaded) == 1 && reloaded[0].Watchdog.DryRun
+	}, 5*time.Second, 10*time.Millisecond)
+
+	lc.RequireStop()
+}
+
+func TestWatcherKeepsOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("watchdog:\n  namespaces: [default]\n"), 0o600))
+	cfg, err := config.Source{Path: path, Flags: map[string]string{"watchdog.dryRun": "true"}}.Load()
+	require.NoError(t, err)
+	r := &recorder{}
+	w := NewWatcher(fxtest.NewLifecycle(t), cfg, zap.NewNop().Sugar(), nil, r)
+
+	require.NoError(t, os.WriteFile(path, []byte("watchdog:\n  namespaces: [default]\n  dryRun: false\n  scheduleInterval: 2m\n"), 0o600))
+	w.Check()
+	require.Len(t, r.reloaded(), 1)
+	require.Equal(t, 2*time.Minute, r.reloaded()[0].Watchdog.ScheduleInterval)
+	require.True(t, r.reloaded()[0].Watchdog.DryRun, "the flag still overrides the file")
+}
+
+func TestWatcherConfDir(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	cfg := writeConfig(t, path, "watchdog:\n  namespaces: [default]\n")
+	r := &recorder{}
+	w := NewWatcher(fxtest.NewLifecycle(t), cfg, zap.NewNop().Sugar(), nil, r)
+
+	// A file added to conf.d is merged in
+	require.NoError(t, os.Mkdir(filepath.Join(dir, config.ConfDir), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, config.ConfDir, "10-team.yaml"), []byte("watchdog:\n  namespaces: [team]\n"), 0o600))
+	w.Check()
+	require.Len(t, r.reloaded(), 1)
+	require.Equal(t, []string{"default", "team"}, r.reloaded()[0].Watchdog.Namespaces)
+}
+
+func TestWatcherDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := writeConfig(t, path, "reload:\n  enabled: false\n")
+	w := NewWatcher(fxtest.NewLifecycle(t), cfg, zap.NewNop().Sugar(), nil)
+	require.NoError(t, w.Start(context.Background()))
+	require.NoError(t, w.Shutdown(context.Background()))
+}
+
+func TestRestartRequired(t *testing.T) {
+	previous := &config.Config{
+		Watchdog: config.WatchdogConfig{ScheduleInterval: time.Minute},
+		Logging:  config.LoggingConfig{Mode: "production", Level: "info"},
+	}
+	next := *previous
+	next.Watchdog.ScheduleInterval = time.Hour
+	next.Logging.Level = "debug"
+	next.Notify.Webhooks = []config.WebhookConfig{{Name: "chat", URL: "https://chat.example.com/hook"}}
+	require.Empty(t, restartRequired(previous, &next), "applied without a restart")
+
+	next.Logging.Mode = "development"
+	next.Sharding.Enabled = true
+	next.Notify.Workers = 4
+	next.Watchdog.Events.Enabled = true
+	require.Equal(t, []string{"sharding", "notifications.workers", "logging.mode", "watchdog.events"}, restartRequired(previous, &next))
+}
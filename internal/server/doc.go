@@ -8,12 +8,23 @@
 //
 // The HTTP server supports:
 //   - Configurable address and timeouts
-//   - Health and readiness check endpoints for container orchestration platforms
-//   - Prometheus metrics endpoint for monitoring and alerting
+//   - TLS with certificates reloaded from disk when they change, and optional
+//     client certificate verification
+//   - Separate listeners for the probes and the metrics, each with its own
+//     address, timeouts and TLS settings
+//   - Liveness and readiness probes running the checks of the health registry,
+//     with readiness failing as soon as shutdown starts
+//   - Prometheus metrics endpoint serving the registry the components register with
+//   - Additional routes, such as the JSON API, registered through the Routes interface
+//   - Verbose readiness output listing state reported through the Detail interface
+//   - Middlewares wrapping every request, such as authentication
 //   - Lifecycle management via the fx framework
 //
 // The Watchdog server provides:
 //   - Periodic pod monitoring based on configuration schedule
+//   - Health checks failing liveness when the loop stalls and readiness when
+//     no full cycle listed every namespace for too long
+//   - On-demand runs, queued on the same loop so they never overlap scheduled ones
 //   - Integration with the monitoring package for pod termination
 //   - Graceful startup and shutdown handling
 //   - Lifecycle management to ensure proper cleanup
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/isdmx/watchdog/internal/health"
+)
+
+// loopState tracks the progress of the monitoring loop for the health checks
+type loopState struct {
+	started   time.Time
+	progress  time.Time
+	succeeded time.Time
+	running   bool
+}
+
+// progress records that the loop moved on, at the current time
+func (wd *WatchdogServer) progress(fn func(loop *loopState)) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.loop.progress = wd.now()
+	fn(&wd.loop)
+}
+
+// HealthChecks reports a stalled loop to the liveness probe, so the pod is restarted,
+// and a long time without a successful cycle to the readiness probe
+func (wd *WatchdogServer) HealthChecks() []health.Check {
+	return []health.Check{
+		{Name: "cycle-loop", Kind: health.Liveness, Run: wd.checkLoop},
+		{Name: "last-cycle", Kind: health.Readiness, Run: wd.checkLastCycle},
+	}
+}
+
+// checkLoop fails when a cycle runs, or no cycle started, for longer than the stall timeout
+func (wd *WatchdogServer) checkLoop(context.Context) error {
+	wd.mu.Lock()
+	loop := wd.loop
+	wd.mu.Unlock()
+
+	if loop.started.IsZero() {
+		return nil
+	}
+	idle := wd.now().Sub(loop.progress)
+	if idle <= wd.stallTimeout() {
+		return nil
+	}
+	if loop.running {
+		return fmt.Errorf("cycle running for %s, longer than the stall timeout of %s", idle.Round(time.Second), wd.stallTimeout())
+	}
+	return fmt.Errorf("no cycle started for %s, longer than the stall timeout of %s", idle.Round(time.Second), wd.stallTimeout())
+}
+
+// checkLastCycle fails when the last successful cycle, or the start when there was
+// none, is older than the maximum cycle age
+func (wd *WatchdogServer) checkLastCycle(context.Context) error {
+	wd.mu.Lock()
+	loop := wd.loop
+	wd.mu.Unlock()
+
+	if loop.started.IsZero() {
+		return nil
+	}
+	if loop.succeeded.IsZero() {
+		if age := wd.now().Sub(loop.started); age > wd.maxCycleAge() {
+			return fmt.Errorf("no successful cycle since the start %s ago, expected within %s", age.Round(time.Second), wd.maxCycleAge())
+		}
+		return nil
+	}
+	if age := wd.now().Sub(loop.succeeded); age > wd.maxCycleAge() {
+		return fmt.Errorf("no successful cycle for %s, expected within %s", age.Round(time.Second), wd.maxCycleAge())
+	}
+	return nil
+}
+
+// stallTimeout returns the configured stall timeout, defaulting to the cycle timeout
+// plus two schedule intervals
+func (wd *WatchdogServer) stallTimeout() time.Duration {
+	cfg := wd.Config()
+	if cfg.Health.StallTimeout > 0 {
+		return cfg.Health.StallTimeout
+	}
+	return wd.cycleTimeout() + 2*cfg.Watchdog.ScheduleInterval
+}
+
+// maxCycleAge returns the configured maximum cycle age, defaulting to three schedule
+// intervals plus the cycle timeout
+func (wd *WatchdogServer) maxCycleAge() time.Duration {
+	cfg := wd.Config()
+	if cfg.Health.MaxCycleAge > 0 {
+		return cfg.Health.MaxCycleAge
+	}
+	return 3*cfg.Watchdog.ScheduleInterval + wd.cycleTimeout()
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+func TestWatchdogServerHealthChecks(t *testing.T) {
+	now := time.Now()
+	wd := &WatchdogServer{now: func() time.Time { return now }}
+	wd.config.Store(&config.Config{Watchdog: config.WatchdogConfig{ScheduleInterval: time.Minute, CycleTimeout: time.Minute}})
+	ctx := context.Background()
+
+	// Nothing fails before the loop starts
+	require.NoError(t, wd.checkLoop(ctx))
+	require.NoError(t, wd.checkLastCycle(ctx))
+
+	wd.progress(func(l *loopState) { l.started = l.progress })
+	now = now.Add(4 * time.Minute)
+	require.ErrorContains(t, wd.checkLoop(ctx), "no cycle started for 4m0s, longer than the stall timeout of 3m0s")
+	require.NoError(t, wd.checkLastCycle(ctx))
+
+	// A cycle keeps the loop alive until it runs past the stall timeout
+	wd.progress(func(l *loopState) { l.running = true })
+	require.NoError(t, wd.checkLoop(ctx))
+	now = now.Add(4 * time.Minute)
+	require.ErrorContains(t, wd.checkLoop(ctx), "cycle running for 4m0s")
+	require.ErrorContains(t, wd.checkLastCycle(ctx), "no successful cycle since the start 8m0s ago, expected within 4m0s")
+
+	wd.progress(func(l *loopState) { l.running, l.succeeded = false, l.progress })
+	require.NoError(t, wd.checkLoop(ctx))
+	require.NoError(t, wd.checkLastCycle(ctx))
+
+	// Failed cycles keep the loop alive but the pod turns unready
+	now = now.Add(5 * time.Minute)
+	wd.progress(func(l *loopState) {})
+	require.NoError(t, wd.checkLoop(ctx))
+	require.ErrorContains(t, wd.checkLastCycle(ctx), "no successful cycle for 5m0s, expected within 4m0s")
+
+	wd.Config().Health.MaxCycleAge = 10 * time.Minute
+	require.NoError(t, wd.checkLastCycle(ctx))
+}
+
+func TestLastCycleNeedsFullCycles(t *testing.T) {
+	cfg := &config.Config{Watchdog: config.WatchdogConfig{
+		Namespaces:       []string{"default", "restricted"},
+		ScheduleInterval: time.Minute,
+		CycleTimeout:     time.Minute,
+		MaxPodLifetime:   time.Hour,
+	}}
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "restricted" {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("rbac"))
+		}
+		return false, nil, nil
+	})
+	pm := monitoring.NewPodMonitor(clientset, cfg, nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+	wd := NewWatchdogServer(fxtest.NewLifecycle(t), pm, zap.NewNop().Sugar(), cfg)
+	now := time.Now()
+	wd.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	wd.progress(func(l *loopState) { l.started = l.progress })
+	now = now.Add(5 * time.Minute)
+
+	// A namespace that cannot be listed keeps the pod unready
+	wd.runCycle(&Run{ID: "scheduled", Trigger: TriggerSchedule})
+	require.ErrorContains(t, wd.checkLastCycle(ctx), "no successful cycle since the start")
+
+	// So does a triggered run limited to the namespaces that can be listed
+	wd.runCycle(&Run{ID: "triggered", Trigger: TriggerAPI, Options: monitoring.RunOptions{Namespaces: []string{"default"}}})
+	require.ErrorContains(t, wd.checkLastCycle(ctx), "no successful cycle since the start")
+
+	cfg.Watchdog.Namespaces = []string{"default"}
+	wd.runCycle(&Run{ID: "full", Trigger: TriggerSchedule})
+	require.NoError(t, wd.checkLastCycle(ctx))
+}
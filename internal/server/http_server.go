@@ -1,97 +1,285 @@
 package server
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 
 	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/health"
 )
 
 var _ Server = (*HTTPServer)(nil)
 
+// Routes registers additional endpoints on the HTTP server
+type Routes interface {
+	RegisterRoutes(mux *http.ServeMux)
+}
+
+// Detail reports a named line of state shown by /readyz?verbose
+type Detail interface {
+	Detail() (name, detail string)
+}
+
+// Middleware wraps every request of the HTTP server, for example to authenticate it
+type Middleware interface {
+	Wrap(next http.Handler) http.Handler
+}
+
 // HTTPServer manages health check endpoints
 type HTTPServer struct {
-	server *http.Server
-	logger *zap.SugaredLogger
+	listeners  []*listener
+	gatherer   prometheus.Gatherer
+	tlsMetrics *metrics
+	health     *health.Registry
+	routes     []Routes
+	details    []Detail
+	config     config.HTTPConfig
+	logger     *zap.SugaredLogger
+	// serveMetrics is off when the metrics are only exported over OTLP
+	serveMetrics bool
+
+	shuttingDown atomic.Bool
 }
 
-// NewHTTPServer creates a new health handler
-func NewHTTPServer(lc fx.Lifecycle, logger *zap.SugaredLogger, cfg *config.Config) *HTTPServer {
-	mux := http.NewServeMux()
+// listener is one address of the HTTP server, the main one serves the API and,
+// unless they have their own addresses, the probes and metrics
+type listener struct {
+	name   string
+	server *http.Server
+	tls    bool
+}
 
+// NewHTTPServer creates a new health handler serving the metrics of the registry,
+// a nil registry serves only the HTTP server's own metrics
+func NewHTTPServer(
+	lc fx.Lifecycle,
+	logger *zap.SugaredLogger,
+	cfg *config.Config,
+	registry *prometheus.Registry,
+	checks *health.Registry,
+	details []Detail,
+	middlewares []Middleware,
+	routes ...Routes,
+) (*HTTPServer, error) {
+	if registry == nil {
+		registry = prometheus.NewRegistry()
+	}
 	server := &HTTPServer{
-		logger: logger.Named("HTTPServer"),
-		server: &http.Server{
-			Handler:      mux,
-			Addr:         cfg.HTTP.Addr,
-			ReadTimeout:  cfg.HTTP.ReadTimeout,
-			WriteTimeout: cfg.HTTP.WriteTimeout,
-		},
+		gatherer:   registry,
+		tlsMetrics: newMetrics(registry),
+		health:     checks,
+		routes:     routes,
+		details:    details,
+		config:     cfg.HTTP,
+		logger:     logger.Named("HTTPServer"),
+
+		serveMetrics: cfg.Metrics.Prometheus.Enabled,
 	}
 
+	mux := http.NewServeMux()
 	server.RegisterRoutes(mux)
+	main := config.ListenerConfig{
+		Addr:         cfg.HTTP.Addr,
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+		TLS:          cfg.HTTP.TLS,
+	}
+	if err := server.addListener("main", main, mux, middlewares); err != nil {
+		return nil, err
+	}
+
+	if cfg.HTTP.Probes.Addr != "" {
+		mux := http.NewServeMux()
+		server.registerProbes(mux)
+		if err := server.addListener("probes", cfg.HTTP.Probes, mux, middlewares); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.HTTP.Metrics.Addr != "" && server.serveMetrics {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/metrics", server.metrics)
+		if err := server.addListener("metrics", cfg.HTTP.Metrics, mux, middlewares); err != nil {
+			return nil, err
+		}
+	}
+
 	lc.Append(fx.Hook{
 		OnStart: server.Start,
 		OnStop:  server.Shutdown,
 	})
-	return server
+	return server, nil
 }
 
-// RegisterRoutes registers health check routes
+// addListener creates a listener serving the mux through the middlewares, its
+// timeouts default to those of the main listener
+func (h *HTTPServer) addListener(name string, cfg config.ListenerConfig, mux *http.ServeMux, middlewares []Middleware) error {
+	for _, l := range h.listeners {
+		if l.server.Addr == cfg.Addr {
+			return fmt.Errorf("the %s and %s listeners both use address %q", l.name, name, cfg.Addr)
+		}
+	}
+
+	var handler http.Handler = mux
+	for _, middleware := range middlewares {
+		handler = middleware.Wrap(handler)
+	}
+	l := &listener{
+		name: name,
+		server: &http.Server{
+			Handler:      handler,
+			Addr:         cfg.Addr,
+			ReadTimeout:  cmp.Or(cfg.ReadTimeout, h.config.ReadTimeout),
+			WriteTimeout: cmp.Or(cfg.WriteTimeout, h.config.WriteTimeout),
+		},
+	}
+	if cfg.TLS.Enabled() {
+		certs, err := newCertReloader(name, cfg.TLS, h.logger, h.tlsMetrics)
+		if err != nil {
+			return err
+		}
+		l.server.TLSConfig = certs.tlsConfig()
+		l.tls = true
+	}
+	h.listeners = append(h.listeners, l)
+	return nil
+}
+
+// RegisterRoutes registers the routes of the main listener: the additional routes,
+// and the health checks and metrics unless they have their own listeners or
+// metrics.prometheus is disabled
 func (h *HTTPServer) RegisterRoutes(mux *http.ServeMux) {
+	if h.config.Probes.Addr == "" {
+		h.registerProbes(mux)
+	}
+	if h.config.Metrics.Addr == "" && h.serveMetrics {
+		mux.HandleFunc("/metrics", h.metrics)
+	}
+
+	for _, routes := range h.routes {
+		routes.RegisterRoutes(mux)
+	}
+}
+
+// registerProbes registers the health check routes
+func (h *HTTPServer) registerProbes(mux *http.ServeMux) {
 	mux.HandleFunc("/healthz", h.healthz)
 	mux.HandleFunc("/readyz", h.readyz)
-	mux.HandleFunc("/metrics", h.metrics)
 }
 
-// healthz endpoint - checks if the service is healthy
-func (h *HTTPServer) healthz(w http.ResponseWriter, _ *http.Request) {
-	// In a real implementation, you might check connectivity to external services
-	// For now, we'll just return OK
-	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", "text/plain")
-	_, err := w.Write([]byte("OK"))
-	if err != nil {
-		h.logger.Errorw("Failed to write healthz response", "error", err)
+// healthz endpoint - fails when a liveness check fails, such as a stalled cycle loop
+func (h *HTTPServer) healthz(w http.ResponseWriter, r *http.Request) {
+	h.writeProbe(w, r, h.health.Run(r.Context(), health.Liveness), nil)
+}
+
+// readyz endpoint - fails when a readiness check fails or the server shuts down,
+// with ?verbose it also lists the details
+func (h *HTTPServer) readyz(w http.ResponseWriter, r *http.Request) {
+	report := h.health.Run(r.Context(), health.Readiness)
+	if h.shuttingDown.Load() {
+		report.Healthy = false
+		report.Results = append(report.Results, health.Result{Name: "shutdown", Error: "shutting down"})
+	}
+
+	var details []string
+	if r.URL.Query().Has("verbose") {
+		for _, d := range h.details {
+			name, detail := d.Detail()
+			details = append(details, name+": "+detail)
+		}
 	}
+	h.writeProbe(w, r, report, details)
 }
 
-// readyz endpoint - checks if the service is ready to serve requests
-func (h *HTTPServer) readyz(w http.ResponseWriter, _ *http.Request) {
-	// In a real implementation, you might check if other dependencies are ready
-	// For now, we'll just return OK
-	w.WriteHeader(http.StatusOK)
+// writeProbe answers a probe with OK or 503 FAILED, listing the checks with
+// ?verbose or when one failed
+func (h *HTTPServer) writeProbe(w http.ResponseWriter, r *http.Request, report health.Report, details []string) {
+	status, lines := http.StatusOK, []string{"OK"}
+	if !report.Healthy {
+		status, lines = http.StatusServiceUnavailable, []string{"FAILED"}
+	}
+	if (r.URL.Query().Has("verbose") || !report.Healthy) && len(report.Results) > 0 {
+		lines = append(lines, report.String())
+	}
+	lines = append(lines, details...)
+
 	w.Header().Set("Content-Type", "text/plain")
-	_, err := w.Write([]byte("OK"))
-	if err != nil {
-		h.logger.Errorw("Failed to write readyz response", "error", err)
+	w.WriteHeader(status)
+	if _, err := w.Write([]byte(strings.Join(lines, "\n"))); err != nil {
+		h.logger.Errorw("Failed to write probe response", "path", r.URL.Path, "error", err)
 	}
 }
 
 // metrics endpoint - returns Prometheus metrics
-func (*HTTPServer) metrics(w http.ResponseWriter, r *http.Request) {
-	promhttp.Handler().ServeHTTP(w, r)
+func (h *HTTPServer) metrics(w http.ResponseWriter, r *http.Request) {
+	promhttp.HandlerFor(h.gatherer, promhttp.HandlerOpts{}).ServeHTTP(w, r)
 }
 
+// Start listens on every address, so a busy port fails the startup, and serves
+// in the background
 func (h *HTTPServer) Start(ctx context.Context) error {
 	h.logger.Info("Starting HTTP server")
 
-	go func(srv *http.Server) {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			ctx.Done()
-			h.logger.Errorw("Failed to start HTTP server", "error", err)
+	var lc net.ListenConfig
+	listeners := make([]net.Listener, 0, len(h.listeners))
+	for _, l := range h.listeners {
+		ln, err := lc.Listen(ctx, "tcp", l.server.Addr)
+		if err != nil {
+			for _, ln := range listeners {
+				_ = ln.Close()
+			}
+			return fmt.Errorf("%s listener: %w", l.name, err)
 		}
-	}(h.server)
+		listeners = append(listeners, ln)
+	}
 
+	for i, l := range h.listeners {
+		h.logger.Infow("Listening", "listener", l.name, "addr", listeners[i].Addr().String(), "tls", l.tls)
+		go h.serve(l, listeners[i])
+	}
 	return nil
 }
 
-// Shutdown gracefully shuts down the HTTP server
+// serve serves the listener until it is shut down
+func (h *HTTPServer) serve(l *listener, ln net.Listener) {
+	var err error
+	if l.tls {
+		err = l.server.ServeTLS(ln, "", "")
+	} else {
+		err = l.server.Serve(ln)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		h.logger.Errorw("HTTP server failed", "listener", l.name, "error", err)
+	}
+}
+
+// Shutdown fails the readiness probe at once, keeps serving for the shutdown delay
+// so the pod leaves the Service endpoints, then gracefully shuts down every listener
 func (h *HTTPServer) Shutdown(ctx context.Context) error {
+	h.shuttingDown.Store(true)
+	if h.config.ShutdownDelay > 0 {
+		h.logger.Infow("Failing readiness before shutting down", "delay", h.config.ShutdownDelay)
+		select {
+		case <-time.After(h.config.ShutdownDelay):
+		case <-ctx.Done():
+		}
+	}
+
 	h.logger.Info("Shutting down HTTP server")
-	return h.server.Shutdown(ctx)
+	errs := make([]error, 0, len(h.listeners))
+	for _, l := range h.listeners {
+		errs = append(errs, l.server.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
 }
@@ -2,18 +2,23 @@ package server
 
 import (
 	"context"
+	"errors"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/fx/fxtest"
 	"go.uber.org/zap"
 
 	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/health"
 )
 
 func TestNewHttpServer(t *testing.T) {
@@ -30,13 +35,15 @@ func TestNewHttpServer(t *testing.T) {
 		}
 
 		lc := fxtest.NewLifecycle(t)
-		server := NewHTTPServer(lc, sugaredLogger, cfg)
+		server, err := NewHTTPServer(lc, sugaredLogger, cfg, nil, nil, nil, nil)
+		require.NoError(t, err)
 
 		require.NotNil(t, server)
-		require.NotNil(t, server.server)
-		require.Equal(t, cfg.HTTP.Addr, server.server.Addr)
-		require.Equal(t, cfg.HTTP.ReadTimeout, server.server.ReadTimeout)
-		require.Equal(t, cfg.HTTP.WriteTimeout, server.server.WriteTimeout)
+		require.Len(t, server.listeners, 1)
+		main := server.listeners[0].server
+		require.Equal(t, cfg.HTTP.Addr, main.Addr)
+		require.Equal(t, cfg.HTTP.ReadTimeout, main.ReadTimeout)
+		require.Equal(t, cfg.HTTP.WriteTimeout, main.WriteTimeout)
 	})
 }
 
@@ -50,10 +57,12 @@ func TestRegisterRoutes(t *testing.T) {
 			ReadTimeout:  5 * time.Second,
 			WriteTimeout: 10 * time.Second,
 		},
+		Metrics: config.MetricsConfig{Prometheus: config.PrometheusMetricsConfig{Enabled: true}},
 	}
 
 	lc := fxtest.NewLifecycle(t)
-	server := NewHTTPServer(lc, sugaredLogger, cfg)
+	server, err := NewHTTPServer(lc, sugaredLogger, cfg, nil, nil, nil, nil)
+	require.NoError(t, err)
 
 	mux := http.NewServeMux()
 	server.RegisterRoutes(mux)
@@ -104,6 +113,132 @@ func TestRegisterRoutes(t *testing.T) {
 	})
 }
 
+// helloRoutes registers a single extra route
+type helloRoutes struct{}
+
+func (helloRoutes) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /hello", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	})
+}
+
+// staticDetail reports a fixed readiness detail
+type staticDetail struct {
+	name, detail string
+}
+
+func (d staticDetail) Detail() (string, string) {
+	return d.name, d.detail
+}
+
+func TestRegisterAdditionalRoutes(t *testing.T) {
+	cfg := &config.Config{HTTP: config.HTTPConfig{Addr: ":8080"}}
+	server, err := NewHTTPServer(fxtest.NewLifecycle(t), zap.NewNop().Sugar(), cfg, nil, nil, nil, nil, helloRoutes{})
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/hello", http.NoBody))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "hello", w.Body.String())
+}
+
+// headerMiddleware marks every response with a header
+type headerMiddleware string
+
+func (m headerMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("X-Middleware", string(m))
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestMiddlewares(t *testing.T) {
+	cfg := &config.Config{HTTP: config.HTTPConfig{Addr: ":8080"}}
+	server, err := NewHTTPServer(fxtest.NewLifecycle(t), zap.NewNop().Sugar(), cfg, nil, nil, nil,
+		[]Middleware{headerMiddleware("inner"), headerMiddleware("outer")}, helloRoutes{})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	server.listeners[0].server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/hello", http.NoBody))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, []string{"outer", "inner"}, w.Header().Values("X-Middleware"))
+}
+
+func TestListeners(t *testing.T) {
+	serve := func(l *listener, path string) int {
+		w := httptest.NewRecorder()
+		l.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+		return w.Code
+	}
+
+	t.Run("probes and metrics get their own listeners", func(t *testing.T) {
+		cfg := &config.Config{HTTP: config.HTTPConfig{
+			Addr:         ":8080",
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 10 * time.Second,
+			Probes:       config.ListenerConfig{Addr: ":8081"},
+			Metrics:      config.ListenerConfig{Addr: ":9090", ReadTimeout: time.Second},
+		}, Metrics: config.MetricsConfig{Prometheus: config.PrometheusMetricsConfig{Enabled: true}}}
+		server, err := NewHTTPServer(fxtest.NewLifecycle(t), zap.NewNop().Sugar(), cfg, nil, nil, nil, nil, helloRoutes{})
+		require.NoError(t, err)
+		require.Len(t, server.listeners, 3)
+		main, probes, metrics := server.listeners[0], server.listeners[1], server.listeners[2]
+
+		require.Equal(t, http.StatusOK, serve(main, "/hello"))
+		require.Equal(t, http.StatusNotFound, serve(main, "/healthz"))
+		require.Equal(t, http.StatusNotFound, serve(main, "/metrics"))
+
+		require.Equal(t, ":8081", probes.server.Addr)
+		require.Equal(t, http.StatusOK, serve(probes, "/healthz"))
+		require.Equal(t, http.StatusOK, serve(probes, "/readyz"))
+		require.Equal(t, http.StatusNotFound, serve(probes, "/hello"))
+		require.Equal(t, 5*time.Second, probes.server.ReadTimeout, "defaults to the main timeouts")
+
+		require.Equal(t, ":9090", metrics.server.Addr)
+		require.Equal(t, http.StatusOK, serve(metrics, "/metrics"))
+		require.Equal(t, http.StatusNotFound, serve(metrics, "/healthz"))
+		require.Equal(t, time.Second, metrics.server.ReadTimeout)
+		require.Equal(t, 10*time.Second, metrics.server.WriteTimeout)
+	})
+
+	t.Run("metrics may be left to OTLP", func(t *testing.T) {
+		cfg := &config.Config{HTTP: config.HTTPConfig{Addr: ":8080", Metrics: config.ListenerConfig{Addr: ":9090"}}}
+		server, err := NewHTTPServer(fxtest.NewLifecycle(t), zap.NewNop().Sugar(), cfg, nil, nil, nil, nil)
+		require.NoError(t, err)
+		require.Len(t, server.listeners, 1, "no metrics listener")
+		require.Equal(t, http.StatusNotFound, serve(server.listeners[0], "/metrics"))
+
+		cfg.HTTP.Metrics.Addr = ""
+		server, err = NewHTTPServer(fxtest.NewLifecycle(t), zap.NewNop().Sugar(), cfg, nil, nil, nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, serve(server.listeners[0], "/metrics"))
+		require.Equal(t, http.StatusOK, serve(server.listeners[0], "/healthz"))
+	})
+
+	t.Run("rejects shared addresses", func(t *testing.T) {
+		cfg := &config.Config{
+			HTTP:    config.HTTPConfig{Addr: ":8080", Metrics: config.ListenerConfig{Addr: ":8080"}},
+			Metrics: config.MetricsConfig{Prometheus: config.PrometheusMetricsConfig{Enabled: true}},
+		}
+		_, err := NewHTTPServer(fxtest.NewLifecycle(t), zap.NewNop().Sugar(), cfg, nil, nil, nil, nil)
+		require.ErrorContains(t, err, `the main and metrics listeners both use address ":8080"`)
+	})
+
+	t.Run("fails to start on a busy port", func(t *testing.T) {
+		busy, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer busy.Close()
+
+		cfg := &config.Config{HTTP: config.HTTPConfig{Addr: "127.0.0.1:0", Probes: config.ListenerConfig{Addr: busy.Addr().String()}}}
+		server, err := NewHTTPServer(fxtest.NewLifecycle(t), zap.NewNop().Sugar(), cfg, nil, nil, nil, nil)
+		require.NoError(t, err)
+		require.ErrorContains(t, server.Start(context.Background()), "probes listener")
+	})
+}
+
 func TestHttpServerMethods(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	sugaredLogger := logger.Sugar()
@@ -117,7 +252,8 @@ func TestHttpServerMethods(t *testing.T) {
 	}
 
 	lc := fxtest.NewLifecycle(t)
-	server := NewHTTPServer(lc, sugaredLogger, cfg)
+	server, err := NewHTTPServer(lc, sugaredLogger, cfg, nil, nil, nil, nil)
+	require.NoError(t, err)
 
 	t.Run("start and shutdown server", func(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -167,7 +303,59 @@ func TestHttpHandlerFunctions(t *testing.T) {
 		require.Equal(t, "OK", w.Body.String())
 	})
 
+	t.Run("verbose readyz handler", func(t *testing.T) {
+		server := &HTTPServer{logger: sugaredLogger, details: []Detail{staticDetail{"pause", "not paused"}}}
+		req := httptest.NewRequest("GET", "/readyz?verbose", http.NoBody)
+		w := httptest.NewRecorder()
+
+		server.readyz(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "OK\npause: not paused", w.Body.String())
+	})
+
+	t.Run("failing probe lists the checks", func(t *testing.T) {
+		registry := health.NewRegistry(&config.Config{}, nil)
+		registry.Register(
+			health.Check{Name: "cycle-loop", Kind: health.Liveness, Run: func(context.Context) error { return nil }},
+			health.Check{Name: "apiserver", Kind: health.Readiness, Run: func(context.Context) error { return errors.New("connection refused") }},
+		)
+		server := &HTTPServer{logger: sugaredLogger, health: registry}
+
+		w := httptest.NewRecorder()
+		server.healthz(w, httptest.NewRequest("GET", "/healthz", http.NoBody))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "OK", w.Body.String())
+
+		w = httptest.NewRecorder()
+		server.healthz(w, httptest.NewRequest("GET", "/healthz?verbose", http.NoBody))
+		require.Equal(t, "OK\n[+]cycle-loop ok", w.Body.String())
+
+		w = httptest.NewRecorder()
+		server.readyz(w, httptest.NewRequest("GET", "/readyz", http.NoBody))
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.Equal(t, "FAILED\n[-]apiserver failed: connection refused", w.Body.String())
+	})
+
+	t.Run("readyz fails once shutting down", func(t *testing.T) {
+		server, err := NewHTTPServer(fxtest.NewLifecycle(t), sugaredLogger, &config.Config{HTTP: config.HTTPConfig{Addr: "127.0.0.1:0"}}, nil, nil, nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, server.Shutdown(context.Background()))
+
+		w := httptest.NewRecorder()
+		server.readyz(w, httptest.NewRequest("GET", "/readyz", http.NoBody))
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.Equal(t, "FAILED\n[-]shutdown failed: shutting down", w.Body.String())
+
+		w = httptest.NewRecorder()
+		server.healthz(w, httptest.NewRequest("GET", "/healthz", http.NoBody))
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
 	t.Run("metrics handler", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		promauto.With(registry).NewCounter(prometheus.CounterOpts{Name: "watchdog_test_total", Help: "Test counter"}).Inc()
+		server := &HTTPServer{logger: sugaredLogger, gatherer: registry}
 		req := httptest.NewRequest("GET", "/metrics", http.NoBody)
 		w := httptest.NewRecorder()
 
@@ -176,5 +364,8 @@ func TestHttpHandlerFunctions(t *testing.T) {
 		require.Equal(t, http.StatusOK, w.Code)
 		// The content type should be text/plain for prometheus metrics
 		require.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+		// Only the metrics of the injected registry are served
+		require.Contains(t, w.Body.String(), "watchdog_test_total 1")
+		require.NotContains(t, w.Body.String(), "go_goroutines")
 	})
 }
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Results of watchdog_tls_reloads_total
+const (
+	ResultReloaded = "reloaded"
+	ResultFailed   = "failed"
+)
+
+// metrics are the metrics of the HTTP server
+type metrics struct {
+	// tlsCertificateExpiry is the Unix time at which the served certificate expires
+	tlsCertificateExpiry *prometheus.GaugeVec
+	// tlsReloadsTotal counts certificate reloads after the files changed
+	tlsReloadsTotal *prometheus.CounterVec
+}
+
+// newMetrics registers the HTTP server metrics, a nil registerer leaves them unregistered
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	factory := promauto.With(registerer)
+	return &metrics{
+		tlsCertificateExpiry: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "watchdog_tls_certificate_expiry_time_seconds",
+				Help: "Unix time at which the certificate served by a listener expires",
+			},
+			[]string{"listener"},
+		),
+		tlsReloadsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "watchdog_tls_reloads_total",
+				Help: "TLS certificate reloads of a listener by result (reloaded, failed)",
+			},
+			[]string{"listener", "result"},
+		),
+	}
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+// maxRunHistory is the number of runs kept for GET /api/v1/runs
+const maxRunHistory = 100
+
+// RunStatus is the state of a monitoring run
+type RunStatus string
+
+const (
+	// RunQueued means the run waits for the current cycle to finish
+	RunQueued RunStatus = "queued"
+	// RunRunning means the run is in progress
+	RunRunning RunStatus = "running"
+	// RunSucceeded means the run went through every namespace
+	RunSucceeded RunStatus = "succeeded"
+	// RunFailed means the run was rejected or interrupted
+	RunFailed RunStatus = "failed"
+)
+
+// Run triggers
+const (
+	TriggerSchedule = "schedule"
+	TriggerAPI      = "api"
+)
+
+// ErrRunQueued is returned when a triggered run is already waiting
+var ErrRunQueued = errors.New("a triggered run is already queued")
+
+// Run is a single monitoring cycle, scheduled or triggered on demand
+type Run struct {
+	ID         string                  `json:"id"`
+	Trigger    string                  `json:"trigger"`
+	Status     RunStatus               `json:"status"`
+	Options    monitoring.RunOptions   `json:"options"`
+	QueuedAt   time.Time               `json:"queuedAt"`
+	StartedAt  time.Time               `json:"startedAt,omitzero"`
+	FinishedAt time.Time               `json:"finishedAt,omitzero"`
+	Report     *monitoring.CycleReport `json:"report,omitempty"`
+	Error      string                  `json:"error,omitempty"`
+}
+
+// Trigger queues a run that starts as soon as the current cycle, if any, is done.
+// Only one triggered run can wait at a time.
+func (wd *WatchdogServer) Trigger(opts monitoring.RunOptions) (Run, error) {
+	if err := wd.pm.CheckRunOptions(opts); err != nil {
+		return Run{}, err
+	}
+
+	run := wd.newRun(TriggerAPI, opts)
+	select {
+	case wd.runs <- run:
+	default:
+		return Run{}, ErrRunQueued
+	}
+
+	wd.logger.Infow("Queued triggered monitoring run", "run", run.ID, "namespaces", opts.Namespaces)
+	return wd.track(run), nil
+}
+
+// Run returns a copy of a recent run
+func (wd *WatchdogServer) Run(id string) (Run, bool) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+
+	run, ok := wd.history[id]
+	if !ok {
+		return Run{}, false
+	}
+	return *run, true
+}
+
+// Runs returns copies of the recent runs, newest first
+func (wd *WatchdogServer) Runs() []Run {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+
+	runs := make([]Run, 0, len(wd.order))
+	for _, id := range slices.Backward(wd.order) {
+		runs = append(runs, *wd.history[id])
+	}
+	return runs
+}
+
+// newRun creates a queued run
+func (*WatchdogServer) newRun(trigger string, opts monitoring.RunOptions) *Run {
+	return &Run{
+		ID:       string(uuid.NewUUID()),
+		Trigger:  trigger,
+		Status:   RunQueued,
+		Options:  opts,
+		QueuedAt: time.Now().UTC(),
+	}
+}
+
+// track adds a run to the history, dropping the oldest runs beyond maxRunHistory
+func (wd *WatchdogServer) track(run *Run) Run {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+
+	if _, ok := wd.history[run.ID]; !ok {
+		wd.history[run.ID] = run
+		wd.order = append(wd.order, run.ID)
+	}
+	for len(wd.order) > maxRunHistory {
+		delete(wd.history, wd.order[0])
+		wd.order = slices.Delete(wd.order, 0, 1)
+	}
+	return *run
+}
+
+// update changes a tracked run under the lock
+func (wd *WatchdogServer) update(run *Run, change func(*Run)) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	change(run)
+}
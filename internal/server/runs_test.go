@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+func newRunsTestServer(t *testing.T) *WatchdogServer {
+	t.Helper()
+	clientset := fake.NewSimpleClientset(&v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:              "expired",
+		Namespace:         "default",
+		CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+	}})
+	cfg := &config.Config{Watchdog: config.WatchdogConfig{
+		Policy:           "default",
+		Namespaces:       []string{"default"},
+		ScheduleInterval: time.Hour,
+		MaxPodLifetime:   time.Hour,
+	}}
+	pm := monitoring.NewPodMonitor(clientset, cfg, nil, nil, zap.NewNop().Sugar(), nil, nil, nil)
+	return NewWatchdogServer(fxtest.NewLifecycle(t), pm, zap.NewNop().Sugar(), cfg)
+}
+
+func TestWatchdogServerTrigger(t *testing.T) {
+	t.Run("runs a triggered cycle", func(t *testing.T) {
+		wdServer := newRunsTestServer(t)
+		require.NoError(t, wdServer.Start(context.Background()))
+		t.Cleanup(func() { _ = wdServer.Shutdown(context.Background()) })
+
+		dryRun := true
+		run, err := wdServer.Trigger(monitoring.RunOptions{DryRun: &dryRun})
+		require.NoError(t, err)
+		require.NotEmpty(t, run.ID)
+		require.Equal(t, TriggerAPI, run.Trigger)
+
+		require.Eventually(t, func() bool {
+			run, ok := wdServer.Run(run.ID)
+			return ok && run.Status == RunSucceeded
+		}, 2*time.Second, 10*time.Millisecond)
+
+		run, _ = wdServer.Run(run.ID)
+		require.Equal(t, run.ID, run.Report.CycleID)
+		require.True(t, run.Report.DryRun)
+		require.Equal(t, map[monitoring.Action]int{monitoring.ActionDryRun: 1}, run.Report.Actions)
+		require.False(t, run.StartedAt.IsZero())
+		require.False(t, run.FinishedAt.IsZero())
+	})
+
+	t.Run("only one run waits at a time", func(t *testing.T) {
+		// Without Start nothing consumes the queue
+		wdServer := newRunsTestServer(t)
+		run, err := wdServer.Trigger(monitoring.RunOptions{})
+		require.NoError(t, err)
+		require.Equal(t, RunQueued, run.Status)
+
+		_, err = wdServer.Trigger(monitoring.RunOptions{})
+		require.ErrorIs(t, err, ErrRunQueued)
+	})
+
+	t.Run("rejects invalid options", func(t *testing.T) {
+		wdServer := newRunsTestServer(t)
+		_, err := wdServer.Trigger(monitoring.RunOptions{Namespaces: []string{"kube-system"}})
+		require.Error(t, err)
+	})
+
+	t.Run("unknown run", func(t *testing.T) {
+		_, ok := newRunsTestServer(t).Run("missing")
+		require.False(t, ok)
+	})
+}
+
+func TestWatchdogServerRunHistory(t *testing.T) {
+	wdServer := newRunsTestServer(t)
+	var first, last string
+	for i := range maxRunHistory + 5 {
+		run := wdServer.track(wdServer.newRun(TriggerSchedule, monitoring.RunOptions{}))
+		if i == 0 {
+			first = run.ID
+		}
+		last = run.ID
+	}
+	require.Len(t, wdServer.history, maxRunHistory)
+	_, ok := wdServer.Run(first)
+	require.False(t, ok)
+
+	runs := wdServer.Runs()
+	require.Len(t, runs, maxRunHistory)
+	require.Equal(t, last, runs[0].ID, "newest first")
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+// Client certificate modes of TLSConfig.ClientAuth
+const (
+	ClientAuthRequire  = "require"
+	ClientAuthOptional = "optional"
+)
+
+// tlsReloadInterval is how often the certificate files are checked for changes
+const tlsReloadInterval = 10 * time.Second
+
+// certReloader serves the certificate and client CAs of a listener from disk, and
+// reloads them when the files change, such as a renewed certificate in a mounted
+// Secret. A failed reload keeps the previous certificate.
+type certReloader struct {
+	listener string
+	config   config.TLSConfig
+	logger   *zap.SugaredLogger
+	metrics  *metrics
+	now      func() time.Time
+
+	mu       sync.Mutex
+	current  *tls.Config
+	modTimes []time.Time
+	checked  time.Time
+}
+
+// newCertReloader loads the certificate, failing when it cannot be used
+func newCertReloader(listener string, cfg config.TLSConfig, logger *zap.SugaredLogger, metrics *metrics) (*certReloader, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, fmt.Errorf("TLS of the %s listener needs both certFile and keyFile", listener)
+	}
+	switch cfg.ClientAuth {
+	case "", ClientAuthRequire, ClientAuthOptional:
+	default:
+		return nil, fmt.Errorf("unknown clientAuth %q of the %s listener, expected %q or %q",
+			cfg.ClientAuth, listener, ClientAuthRequire, ClientAuthOptional)
+	}
+
+	r := &certReloader{
+		listener: listener,
+		config:   cfg,
+		logger:   logger,
+		metrics:  metrics,
+		now:      time.Now,
+	}
+	if err := r.load(); err != nil {
+		return nil, fmt.Errorf("TLS of the %s listener: %w", listener, err)
+	}
+	r.checked = r.now()
+	return r, nil
+}
+
+// tlsConfig returns the server's TLS config, each handshake picks up the current files
+func (r *certReloader) tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		GetConfigForClient: r.configForClient,
+	}
+}
+
+// configForClient returns the current TLS config, reloading the files first when
+// they changed since the last check
+func (r *certReloader) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now := r.now(); now.Sub(r.checked) >= tlsReloadInterval {
+		r.checked = now
+		r.reloadIfChanged()
+	}
+	return r.current, nil
+}
+
+// reloadIfChanged reloads the files when their modification times changed
+func (r *certReloader) reloadIfChanged() {
+	modTimes, err := r.stat()
+	if err != nil {
+		r.logger.Warnw("Failed to check TLS files, keeping the previous certificate", "listener", r.listener, "error", err)
+		r.metrics.tlsReloadsTotal.WithLabelValues(r.listener, ResultFailed).Inc()
+		return
+	}
+	if slices.Equal(modTimes, r.modTimes) {
+		return
+	}
+
+	if err := r.load(); err != nil {
+		r.logger.Errorw("Failed to reload TLS files, keeping the previous certificate", "listener", r.listener, "error", err)
+		r.metrics.tlsReloadsTotal.WithLabelValues(r.listener, ResultFailed).Inc()
+		return
+	}
+	r.logger.Infow("Reloaded TLS certificate", "listener", r.listener)
+	r.metrics.tlsReloadsTotal.WithLabelValues(r.listener, ResultReloaded).Inc()
+}
+
+// load reads the certificate, key and client CAs. The modification times are taken
+// first, so a file changing during the load is read again on the next check.
+func (r *certReloader) load() error {
+	modTimes, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return err
+	}
+	current := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if r.config.ClientCAFile != "" {
+		data, err := os.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return errors.New("no certificates found in " + r.config.ClientCAFile)
+		}
+		current.ClientCAs = pool
+		current.ClientAuth = tls.RequireAndVerifyClientCert
+		if r.config.ClientAuth == ClientAuthOptional {
+			current.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	r.current = current
+	r.modTimes = modTimes
+	if cert.Leaf != nil {
+		r.metrics.tlsCertificateExpiry.WithLabelValues(r.listener).Set(float64(cert.Leaf.NotAfter.Unix()))
+	}
+	return nil
+}
+
+// stat returns the modification times of the files
+func (r *certReloader) stat() ([]time.Time, error) {
+	files := []string{r.config.CertFile, r.config.KeyFile}
+	if r.config.ClientCAFile != "" {
+		files = append(files, r.config.ClientCAFile)
+	}
+
+	modTimes := make([]time.Time, 0, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		modTimes = append(modTimes, info.ModTime())
+	}
+	return modTimes, nil
+}
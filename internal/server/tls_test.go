@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+// testCA issues certificates for the tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "watchdog test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key for 127.0.0.1 with the serial number
+func (ca *testCA) issue(t *testing.T, serial int64) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "watchdog"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeFiles writes the files and moves their modification time forward, so
+// reloads see them change within the test
+func writeFiles(t *testing.T, files map[string][]byte, modTime time.Time) {
+	t.Helper()
+	for file, data := range files {
+		require.NoError(t, os.WriteFile(file, data, 0o600))
+		require.NoError(t, os.Chtimes(file, modTime, modTime))
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	tlsCfg := config.TLSConfig{CertFile: filepath.Join(dir, "tls.crt"), KeyFile: filepath.Join(dir, "tls.key")}
+	cert, key := ca.issue(t, 1)
+	modTime := time.Now()
+	writeFiles(t, map[string][]byte{tlsCfg.CertFile: cert, tlsCfg.KeyFile: key}, modTime)
+
+	reloader, err := newCertReloader("main", tlsCfg, zap.NewNop().Sugar(), newMetrics(nil))
+	require.NoError(t, err)
+	now := time.Now()
+	reloader.now = func() time.Time { return now }
+	serial := func() int64 {
+		current, err := reloader.configForClient(nil)
+		require.NoError(t, err)
+		return current.Certificates[0].Leaf.SerialNumber.Int64()
+	}
+	require.Equal(t, int64(1), serial())
+
+	// Renewed files are picked up at the next check
+	cert, key = ca.issue(t, 2)
+	modTime = modTime.Add(time.Minute)
+	writeFiles(t, map[string][]byte{tlsCfg.CertFile: cert, tlsCfg.KeyFile: key}, modTime)
+	require.Equal(t, int64(1), serial())
+	now = now.Add(tlsReloadInterval)
+	require.Equal(t, int64(2), serial())
+	require.InDelta(t, 1, testutil.ToFloat64(reloader.metrics.tlsReloadsTotal.WithLabelValues("main", ResultReloaded)), 0)
+
+	// A broken key keeps the previous certificate
+	modTime = modTime.Add(time.Minute)
+	writeFiles(t, map[string][]byte{tlsCfg.KeyFile: []byte("garbage")}, modTime)
+	now = now.Add(tlsReloadInterval)
+	require.Equal(t, int64(2), serial())
+	require.InDelta(t, 1, testutil.ToFloat64(reloader.metrics.tlsReloadsTotal.WithLabelValues("main", ResultFailed)), 0)
+}
+
+func TestNewCertReloaderErrors(t *testing.T) {
+	dir := t.TempDir()
+	_, err := newCertReloader("main", config.TLSConfig{CertFile: filepath.Join(dir, "tls.crt")}, zap.NewNop().Sugar(), newMetrics(nil))
+	require.ErrorContains(t, err, "needs both certFile and keyFile")
+
+	_, err = newCertReloader("main", config.TLSConfig{CertFile: "a", KeyFile: "b", ClientAuth: "sometimes"}, zap.NewNop().Sugar(), newMetrics(nil))
+	require.ErrorContains(t, err, `unknown clientAuth "sometimes"`)
+
+	_, err = newCertReloader("main", config.TLSConfig{CertFile: filepath.Join(dir, "tls.crt"), KeyFile: filepath.Join(dir, "tls.key")}, zap.NewNop().Sugar(), newMetrics(nil))
+	require.ErrorContains(t, err, "TLS of the main listener")
+}
+
+func TestClientCertificates(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	cert, key := ca.issue(t, 1)
+	caFile := filepath.Join(dir, "ca.crt")
+	writeFiles(t, map[string][]byte{
+		filepath.Join(dir, "tls.crt"): cert,
+		filepath.Join(dir, "tls.key"): key,
+		caFile:                        ca.pem,
+	}, time.Now())
+	clientCert, clientKey := ca.issue(t, 2)
+	clientPair, err := tls.X509KeyPair(clientCert, clientKey)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	start := func(clientAuth string) string {
+		cfg := &config.Config{HTTP: config.HTTPConfig{
+			Addr: "127.0.0.1:0",
+			TLS: config.TLSConfig{
+				CertFile:     filepath.Join(dir, "tls.crt"),
+				KeyFile:      filepath.Join(dir, "tls.key"),
+				ClientCAFile: caFile,
+				ClientAuth:   clientAuth,
+			},
+		}}
+		server, err := NewHTTPServer(fxtest.NewLifecycle(t), zap.NewNop().Sugar(), cfg, nil, nil, nil, nil)
+		require.NoError(t, err)
+
+		// Listen on a known port by handing the server an open listener
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		go server.serve(server.listeners[0], ln)
+		t.Cleanup(func() { _ = server.Shutdown(context.Background()) })
+		return "https://" + ln.Addr().String() + "/healthz"
+	}
+	get := func(url string, certs ...tls.Certificate) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs}}}
+		resp, err := client.Get(url)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, "OK", string(body))
+		return nil
+	}
+
+	url := start("")
+	require.NoError(t, get(url, clientPair))
+	require.Error(t, get(url), "a client certificate is required")
+
+	url = start(ClientAuthOptional)
+	require.NoError(t, get(url, clientPair))
+	require.NoError(t, get(url))
+}
@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/fx"
@@ -13,12 +15,21 @@ import (
 
 var _ Server = (*WatchdogServer)(nil)
 
-// WatchdogServer represents the watchdog server
+// WatchdogServer represents the watchdog server. Scheduled and triggered runs
+// go through the same loop, so they never overlap.
 type WatchdogServer struct {
 	pm          *monitoring.PodMonitor
 	logger      *zap.SugaredLogger
-	config      *config.Config
+	config      atomic.Pointer[config.Config]
+	reschedule  chan struct{}
 	stopChannel chan struct{}
+	runs        chan *Run
+	now         func() time.Time
+
+	mu      sync.Mutex
+	history map[string]*Run
+	order   []string
+	loop    loopState
 }
 
 // NewWatchdogServer creates a new watchdog server
@@ -26,9 +37,13 @@ func NewWatchdogServer(lc fx.Lifecycle, pm *monitoring.PodMonitor, logger *zap.S
 	wd := &WatchdogServer{
 		pm:          pm,
 		logger:      logger.Named("WatchdogServer"),
-		config:      cfg,
+		reschedule:  make(chan struct{}, 1),
 		stopChannel: make(chan struct{}),
+		runs:        make(chan *Run, 1),
+		history:     map[string]*Run{},
+		now:         time.Now,
 	}
+	wd.config.Store(cfg)
 
 	lc.Append(fx.Hook{
 		OnStart: wd.Start,
@@ -40,19 +55,27 @@ func NewWatchdogServer(lc fx.Lifecycle, pm *monitoring.PodMonitor, logger *zap.S
 
 // Start starts the monitoring process
 func (wd *WatchdogServer) Start(_ context.Context) error {
-	wd.logger.Infow("Starting periodic monitoring", "interval", wd.config.Watchdog.ScheduleInterval)
+	interval := wd.Config().Watchdog.ScheduleInterval
+	wd.logger.Infow("Starting periodic monitoring", "interval", interval)
+	wd.progress(func(loop *loopState) { loop.started = loop.progress })
 
 	go func() {
 		// Start periodic monitoring
-		ticker := time.NewTicker(wd.config.Watchdog.ScheduleInterval)
+		ticker := time.NewTicker(interval)
 
 		for {
 			select {
 			case <-ticker.C:
 				wd.logger.Info("Starting scheduled monitoring check")
-				err := wd.pm.MonitorAndCleanup()
-				if err != nil {
-					wd.logger.Errorw("Scheduled monitoring run failed", "error", err)
+				wd.runCycle(wd.newRun(TriggerSchedule, monitoring.RunOptions{}))
+			case run := <-wd.runs:
+				wd.logger.Infow("Starting triggered monitoring run", "run", run.ID)
+				wd.runCycle(run)
+			case <-wd.reschedule:
+				if next := wd.Config().Watchdog.ScheduleInterval; next != interval {
+					wd.logger.Infow("Rescheduling periodic monitoring", "interval", next, "previous", interval)
+					interval = next
+					ticker.Reset(interval)
 				}
 			case <-wd.stopChannel:
 				wd.logger.Info("Stopping monitoring")
@@ -65,6 +88,67 @@ func (wd *WatchdogServer) Start(_ context.Context) error {
 	return nil
 }
 
+// Config returns the current configuration
+func (wd *WatchdogServer) Config() *config.Config {
+	return wd.config.Load()
+}
+
+// Reload swaps in a new configuration. A changed schedule interval restarts the
+// ticker once the running cycle, if any, is done.
+func (wd *WatchdogServer) Reload(cfg *config.Config) {
+	wd.config.Store(cfg)
+	select {
+	case wd.reschedule <- struct{}{}:
+	default:
+	}
+}
+
+// runCycle runs one monitoring cycle bounded by the cycle timeout and records its outcome
+func (wd *WatchdogServer) runCycle(run *Run) {
+	wd.track(run)
+	wd.update(run, func(run *Run) {
+		run.Status = RunRunning
+		run.StartedAt = time.Now().UTC()
+	})
+	wd.progress(func(loop *loopState) { loop.running = true })
+
+	ctx, cancel := context.WithTimeout(context.Background(), wd.cycleTimeout())
+	defer cancel()
+
+	report, err := wd.pm.Run(monitoring.WithCycleID(ctx, run.ID), run.Options)
+	if err != nil {
+		wd.logger.Errorw("Monitoring run failed", "run", run.ID, "trigger", run.Trigger, "error", err)
+	}
+
+	wd.update(run, func(run *Run) {
+		run.FinishedAt = time.Now().UTC()
+		run.Report = report
+		run.Status = RunSucceeded
+		if err != nil {
+			run.Status = RunFailed
+			run.Error = err.Error()
+		}
+	})
+	// Only a full cycle that listed every namespace counts as a success, as for
+	// watchdog_last_success_timestamp_seconds
+	complete := err == nil && len(run.Options.Namespaces) == 0 && len(report.Unlisted) == 0
+	wd.progress(func(loop *loopState) {
+		loop.running = false
+		if complete {
+			loop.succeeded = loop.progress
+		}
+	})
+}
+
+// cycleTimeout returns the configured cycle timeout, defaulting to the schedule interval
+func (wd *WatchdogServer) cycleTimeout() time.Duration {
+	cfg := wd.Config()
+	if cfg.Watchdog.CycleTimeout > 0 {
+		return cfg.Watchdog.CycleTimeout
+	}
+	return cfg.Watchdog.ScheduleInterval
+}
+
 // Shutdown stops the monitoring process
 func (wd *WatchdogServer) Shutdown(_ context.Context) error {
 	close(wd.stopChannel)
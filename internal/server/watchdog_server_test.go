@@ -20,8 +20,8 @@ type MockPodMonitor struct {
 	mock.Mock
 }
 
-func (m *MockPodMonitor) MonitorAndCleanup() error {
-	args := m.Called()
+func (m *MockPodMonitor) MonitorAndCleanup(ctx context.Context) error {
+	args := m.Called(ctx)
 	return args.Error(0)
 }
 
@@ -37,14 +37,14 @@ func TestNewWatchdogServer(t *testing.T) {
 		logger, _ := zap.NewDevelopment()
 		sugaredLogger := logger.Sugar()
 
-		podMonitor := monitoring.NewPodMonitor(clientset, configObj, sugaredLogger)
+		podMonitor := monitoring.NewPodMonitor(clientset, configObj, nil, nil, sugaredLogger, nil, nil, nil)
 
 		lc := fxtest.NewLifecycle(t)
 		wdServer := NewWatchdogServer(lc, podMonitor, sugaredLogger, configObj)
 
 		require.NotNil(t, wdServer)
 		require.Equal(t, podMonitor, wdServer.pm)
-		require.Equal(t, configObj, wdServer.config)
+		require.Equal(t, configObj, wdServer.Config())
 		require.NotNil(t, wdServer.stopChannel)
 	})
 }
@@ -61,7 +61,7 @@ func TestWatchdogServerStart(t *testing.T) {
 		logger, _ := zap.NewDevelopment()
 		sugaredLogger := logger.Sugar()
 
-		podMonitor := monitoring.NewPodMonitor(clientset, configObj, sugaredLogger)
+		podMonitor := monitoring.NewPodMonitor(clientset, configObj, nil, nil, sugaredLogger, nil, nil, nil)
 
 		lc := fxtest.NewLifecycle(t)
 		wdServer := NewWatchdogServer(lc, podMonitor, sugaredLogger, configObj)
@@ -91,7 +91,7 @@ func TestWatchdogServerShutdown(t *testing.T) {
 		logger, _ := zap.NewDevelopment()
 		sugaredLogger := logger.Sugar()
 
-		podMonitor := monitoring.NewPodMonitor(clientset, configObj, sugaredLogger)
+		podMonitor := monitoring.NewPodMonitor(clientset, configObj, nil, nil, sugaredLogger, nil, nil, nil)
 
 		lc := fxtest.NewLifecycle(t)
 		wdServer := NewWatchdogServer(lc, podMonitor, sugaredLogger, configObj)
@@ -115,3 +115,46 @@ func TestWatchdogServerShutdown(t *testing.T) {
 		}
 	})
 }
+
+func TestWatchdogServerCycleTimeout(t *testing.T) {
+	wdServer := &WatchdogServer{}
+	wdServer.config.Store(&config.Config{
+		Watchdog: config.WatchdogConfig{
+			ScheduleInterval: 10 * time.Minute,
+		},
+	})
+	require.Equal(t, 10*time.Minute, wdServer.cycleTimeout())
+
+	wdServer.Config().Watchdog.CycleTimeout = time.Minute
+	require.Equal(t, time.Minute, wdServer.cycleTimeout())
+}
+
+func TestWatchdogServerReload(t *testing.T) {
+	configObj := &config.Config{
+		Watchdog: config.WatchdogConfig{
+			ScheduleInterval: 10 * time.Minute,
+			MaxPodLifetime:   time.Hour,
+		},
+	}
+	logger := zap.NewNop().Sugar()
+	podMonitor := monitoring.NewPodMonitor(fake.NewSimpleClientset(), configObj, nil, nil, logger, nil, nil, nil)
+	wdServer := NewWatchdogServer(fxtest.NewLifecycle(t), podMonitor, logger, configObj)
+
+	require.NoError(t, wdServer.Start(context.Background()))
+	t.Cleanup(func() { _ = wdServer.Shutdown(context.Background()) })
+
+	// The new interval applies without waiting for the old one to elapse
+	reloaded := *configObj
+	reloaded.Watchdog.ScheduleInterval = 20 * time.Millisecond
+	wdServer.Reload(&reloaded)
+	require.Same(t, &reloaded, wdServer.Config())
+
+	require.Eventually(t, func() bool {
+		for _, run := range wdServer.Runs() {
+			if run.Trigger == TriggerSchedule && run.Status == RunSucceeded {
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+}
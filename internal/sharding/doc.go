@@ -0,0 +1,16 @@
+// Package sharding splits the monitored namespaces across watchdog replicas.
+//
+// Every replica holds a coordination.k8s.io Lease labeled with its shard group
+// and renews it periodically. The set of replicas with unexpired Leases forms
+// the membership, and namespaces are assigned to members with a consistent hash
+// ring, so a replica joining or leaving only moves a small share of namespaces.
+//
+// The package includes:
+//   - Ring: Consistent hash ring with virtual nodes
+//   - Membership: Lease-based member discovery and namespace ownership
+//
+// When a replica shuts down it deletes its Lease so the others rebalance on
+// their next sync. A replica that disappears without cleaning up is dropped
+// once its Lease expires, and the stale Lease is garbage collected later.
+// With sharding disabled every namespace is owned by the single replica.
+package sharding
@@ -0,0 +1,300 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/health"
+)
+
+const (
+	// GroupLabel marks the coordination Leases that belong to one group of replicas
+	GroupLabel = "watchdog.isdmx.io/shard-group"
+
+	leaseNamePrefix = "watchdog-shard-"
+)
+
+// Membership tracks the live replicas of a shard group through coordination Leases
+// and decides which namespaces belong to this replica
+type Membership struct {
+	clientset kubernetes.Interface
+	config    config.ShardingConfig
+	logger    *zap.SugaredLogger
+	metrics   *metrics
+
+	mu       sync.RWMutex
+	ring     *Ring
+	started  time.Time
+	lastSync time.Time
+
+	stopChannel chan struct{}
+	done        chan struct{}
+}
+
+// NewMembership creates a new shard membership
+func NewMembership(
+	lc fx.Lifecycle,
+	clientset kubernetes.Interface,
+	cfg *config.Config,
+	logger *zap.SugaredLogger,
+	registerer prometheus.Registerer,
+) (*Membership, error) {
+	shardingConfig := cfg.Sharding
+	if shardingConfig.Identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+		shardingConfig.Identity = hostname
+	}
+
+	m := &Membership{
+		clientset:   clientset,
+		config:      shardingConfig,
+		logger:      logger.Named("Membership").With("identity", shardingConfig.Identity),
+		metrics:     newMetrics(registerer),
+		ring:        NewRing([]string{shardingConfig.Identity}, shardingConfig.VirtualNodes),
+		stopChannel: make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: m.Start,
+		OnStop:  m.Shutdown,
+	})
+
+	return m, nil
+}
+
+// Owns reports whether the namespace belongs to this replica's shard
+func (m *Membership) Owns(namespace string) bool {
+	if !m.config.Enabled {
+		return true
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.ring.Owner(namespace) == m.config.Identity
+}
+
+// ShardID returns the identity of this replica, or "" when sharding is disabled
+func (m *Membership) ShardID() string {
+	if !m.config.Enabled {
+		return ""
+	}
+	return m.config.Identity
+}
+
+// Members returns the replicas currently sharing the namespace set
+func (m *Membership) Members() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.ring.Members()
+}
+
+// Start registers this replica and keeps its Lease renewed
+func (m *Membership) Start(ctx context.Context) error {
+	if !m.config.Enabled {
+		close(m.done)
+		return nil
+	}
+
+	m.logger.Infow("Joining shard group", "group", m.config.Group, "namespace", m.config.LeaseNamespace)
+	m.mu.Lock()
+	m.started = time.Now()
+	m.mu.Unlock()
+
+	// A failed first sync leaves the ring with only this replica, so
+	// namespaces are processed twice rather than not at all
+	if err := m.Sync(ctx); err != nil {
+		m.logger.Errorw("Initial shard membership sync failed", "error", err)
+	}
+
+	go func() {
+		defer close(m.done)
+
+		ticker := time.NewTicker(m.config.RenewInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := m.Sync(context.Background()); err != nil {
+					m.logger.Errorw("Shard membership sync failed", "error", err)
+				}
+			case <-m.stopChannel:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown stops renewing and releases this replica's Lease so the others rebalance at once
+func (m *Membership) Shutdown(ctx context.Context) error {
+	if !m.config.Enabled {
+		return nil
+	}
+
+	close(m.stopChannel)
+	<-m.done
+
+	err := m.clientset.CoordinationV1().Leases(m.config.LeaseNamespace).Delete(ctx, m.leaseName(), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		m.logger.Errorw("Failed to release shard lease", "error", err)
+		return err
+	}
+
+	m.logger.Info("Left shard group")
+	return nil
+}
+
+// Sync renews this replica's Lease and rebuilds the ring from the live Leases
+func (m *Membership) Sync(ctx context.Context) error {
+	now := time.Now()
+	if err := m.renew(ctx, now); err != nil {
+		return err
+	}
+
+	leases, err := m.clientset.CoordinationV1().Leases(m.config.LeaseNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: GroupLabel + "=" + m.config.Group,
+	})
+	if err != nil {
+		return err
+	}
+
+	members := []string{m.config.Identity}
+	for i := range leases.Items {
+		lease := &leases.Items[i]
+		holder, expiry := leaseHolder(lease)
+		if holder == "" || holder == m.config.Identity {
+			continue
+		}
+		if expiry.After(now) {
+			members = append(members, holder)
+			continue
+		}
+		if now.Sub(expiry) > m.config.LeaseDuration {
+			m.collect(ctx, lease)
+		}
+	}
+
+	m.update(members)
+	m.mu.Lock()
+	m.lastSync = now
+	m.mu.Unlock()
+	return nil
+}
+
+// HealthChecks makes the pod unready once its Lease may have expired, as the
+// other replicas then take over its namespaces
+func (m *Membership) HealthChecks() []health.Check {
+	if !m.config.Enabled {
+		return nil
+	}
+	return []health.Check{{Name: "shard-lease", Kind: health.Readiness, Run: m.checkLease}}
+}
+
+// checkLease fails when the Lease was not renewed within the lease duration
+func (m *Membership) checkLease(context.Context) error {
+	m.mu.RLock()
+	last, started := m.lastSync, m.started
+	m.mu.RUnlock()
+
+	if last.IsZero() {
+		if started.IsZero() || time.Since(started) <= m.config.LeaseDuration {
+			return nil
+		}
+		return fmt.Errorf("shard lease not acquired since joining %s ago", time.Since(started).Round(time.Second))
+	}
+	if age := time.Since(last); age > m.config.LeaseDuration {
+		return fmt.Errorf("shard lease last renewed %s ago, the lease duration is %s, other replicas may own its namespaces",
+			age.Round(time.Second), m.config.LeaseDuration)
+	}
+	return nil
+}
+
+// renew creates or refreshes this replica's Lease
+func (m *Membership) renew(ctx context.Context, now time.Time) error {
+	leases := m.clientset.CoordinationV1().Leases(m.config.LeaseNamespace)
+	renewTime := metav1.NewMicroTime(now)
+	durationSeconds := int32(m.config.LeaseDuration.Seconds())
+
+	lease, err := leases.Get(ctx, m.leaseName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		identity := m.config.Identity
+		_, err = leases.Create(ctx, &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   m.leaseName(),
+				Labels: map[string]string{GroupLabel: m.config.Group},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &identity,
+				LeaseDurationSeconds: &durationSeconds,
+				AcquireTime:          &renewTime,
+				RenewTime:            &renewTime,
+			},
+		}, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	lease.Spec.RenewTime = &renewTime
+	lease.Spec.LeaseDurationSeconds = &durationSeconds
+	_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	return err
+}
+
+// collect removes a Lease left behind by a replica that did not shut down cleanly
+func (m *Membership) collect(ctx context.Context, lease *coordinationv1.Lease) {
+	err := m.clientset.CoordinationV1().Leases(m.config.LeaseNamespace).Delete(ctx, lease.Name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		m.logger.Warnw("Failed to remove expired shard lease", "lease", lease.Name, "error", err)
+		return
+	}
+	m.logger.Infow("Removed expired shard lease", "lease", lease.Name)
+}
+
+// update swaps in a new ring when the set of members changed
+func (m *Membership) update(members []string) {
+	ring := NewRing(members, m.config.VirtualNodes)
+	m.metrics.shardMembers.WithLabelValues(m.config.Group).Set(float64(len(ring.Members())))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if slices.Equal(ring.Members(), m.ring.Members()) {
+		return
+	}
+	m.logger.Infow("Shard membership changed, rebalancing", "previous", m.ring.Members(), "members", ring.Members())
+	m.ring = ring
+}
+
+func (m *Membership) leaseName() string {
+	return leaseNamePrefix + m.config.Identity
+}
+
+// leaseHolder returns the holder of a Lease and the time it expires
+func leaseHolder(lease *coordinationv1.Lease) (string, time.Time) {
+	if lease.Spec.HolderIdentity == nil || lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return "", time.Time{}
+	}
+	duration := time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	return *lease.Spec.HolderIdentity, lease.Spec.RenewTime.Add(duration)
+}
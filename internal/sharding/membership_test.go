@@ -0,0 +1,174 @@
+package sharding
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+func newTestConfig(identity string) *config.Config {
+	return &config.Config{
+		Sharding: config.ShardingConfig{
+			Enabled:        true,
+			Identity:       identity,
+			Group:          "watchdog",
+			LeaseNamespace: "watchdog",
+			LeaseDuration:  30 * time.Second,
+			RenewInterval:  time.Hour,
+			VirtualNodes:   64,
+		},
+	}
+}
+
+func newPeerLease(holder string, renewTime time.Time) *coordinationv1.Lease {
+	duration := int32(30)
+	renew := metav1.NewMicroTime(renewTime)
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      leaseNamePrefix + holder,
+			Namespace: "watchdog",
+			Labels:    map[string]string{GroupLabel: "watchdog"},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &duration,
+			RenewTime:            &renew,
+		},
+	}
+}
+
+func TestNewMembership(t *testing.T) {
+	t.Run("defaults identity to hostname", func(t *testing.T) {
+		cfg := newTestConfig("")
+		m, err := NewMembership(fxtest.NewLifecycle(t), fake.NewSimpleClientset(), cfg, zap.NewNop().Sugar(), nil)
+		require.NoError(t, err)
+		require.NotEmpty(t, m.ShardID())
+	})
+
+	t.Run("disabled sharding owns every namespace", func(t *testing.T) {
+		cfg := newTestConfig("watchdog-0")
+		cfg.Sharding.Enabled = false
+		m, err := NewMembership(fxtest.NewLifecycle(t), fake.NewSimpleClientset(), cfg, zap.NewNop().Sugar(), nil)
+		require.NoError(t, err)
+		require.Empty(t, m.ShardID())
+		require.True(t, m.Owns("anything"))
+
+		require.NoError(t, m.Start(context.Background()))
+		require.NoError(t, m.Shutdown(context.Background()))
+	})
+}
+
+func TestMembershipLifecycle(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newPeerLease("watchdog-1", time.Now()))
+	m, err := NewMembership(fxtest.NewLifecycle(t), clientset, newTestConfig("watchdog-0"), zap.NewNop().Sugar(), nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, m.Start(ctx))
+	require.Equal(t, []string{"watchdog-0", "watchdog-1"}, m.Members())
+
+	// Own lease is created with the group label
+	lease, err := clientset.CoordinationV1().Leases("watchdog").Get(ctx, "watchdog-shard-watchdog-0", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "watchdog", lease.Labels[GroupLabel])
+	require.Equal(t, "watchdog-0", *lease.Spec.HolderIdentity)
+
+	// Namespaces are split between the two members
+	ring := NewRing([]string{"watchdog-0", "watchdog-1"}, 64)
+	for _, namespace := range []string{"a", "b", "c", "d", "e"} {
+		require.Equal(t, ring.Owner(namespace) == "watchdog-0", m.Owns(namespace))
+	}
+
+	// Shutdown releases the lease
+	require.NoError(t, m.Shutdown(ctx))
+	_, err = clientset.CoordinationV1().Leases("watchdog").Get(ctx, "watchdog-shard-watchdog-0", metav1.GetOptions{})
+	require.Error(t, err)
+}
+
+func TestMembershipSync(t *testing.T) {
+	t.Run("rebalances when a member disappears", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newPeerLease("watchdog-1", time.Now()))
+		m, err := NewMembership(fxtest.NewLifecycle(t), clientset, newTestConfig("watchdog-0"), zap.NewNop().Sugar(), nil)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		require.NoError(t, m.Sync(ctx))
+		require.Len(t, m.Members(), 2)
+
+		err = clientset.CoordinationV1().Leases("watchdog").Delete(ctx, "watchdog-shard-watchdog-1", metav1.DeleteOptions{})
+		require.NoError(t, err)
+
+		require.NoError(t, m.Sync(ctx))
+		require.Equal(t, []string{"watchdog-0"}, m.Members())
+		require.True(t, m.Owns("any-namespace"))
+	})
+
+	t.Run("ignores expired leases and collects stale ones", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(
+			newPeerLease("watchdog-1", time.Now().Add(-45*time.Second)),
+			newPeerLease("watchdog-2", time.Now().Add(-5*time.Minute)),
+		)
+		m, err := NewMembership(fxtest.NewLifecycle(t), clientset, newTestConfig("watchdog-0"), zap.NewNop().Sugar(), nil)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		require.NoError(t, m.Sync(ctx))
+		require.Equal(t, []string{"watchdog-0"}, m.Members())
+
+		// Recently expired lease is kept, long expired lease is removed
+		_, err = clientset.CoordinationV1().Leases("watchdog").Get(ctx, "watchdog-shard-watchdog-1", metav1.GetOptions{})
+		require.NoError(t, err)
+		_, err = clientset.CoordinationV1().Leases("watchdog").Get(ctx, "watchdog-shard-watchdog-2", metav1.GetOptions{})
+		require.Error(t, err)
+	})
+
+	t.Run("renews an existing lease", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		m, err := NewMembership(fxtest.NewLifecycle(t), clientset, newTestConfig("watchdog-0"), zap.NewNop().Sugar(), nil)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		require.NoError(t, m.Sync(ctx))
+		first, err := clientset.CoordinationV1().Leases("watchdog").Get(ctx, "watchdog-shard-watchdog-0", metav1.GetOptions{})
+		require.NoError(t, err)
+
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, m.Sync(ctx))
+		second, err := clientset.CoordinationV1().Leases("watchdog").Get(ctx, "watchdog-shard-watchdog-0", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.True(t, second.Spec.RenewTime.After(first.Spec.RenewTime.Time))
+	})
+}
+
+func TestMembershipHealthChecks(t *testing.T) {
+	m, err := NewMembership(fxtest.NewLifecycle(t), fake.NewSimpleClientset(), newTestConfig("watchdog-0"), zap.NewNop().Sugar(), nil)
+	require.NoError(t, err)
+	checks := m.HealthChecks()
+	require.Len(t, checks, 1)
+	require.Equal(t, "shard-lease", checks[0].Name)
+
+	// Not started yet
+	ctx := context.Background()
+	require.NoError(t, checks[0].Run(ctx))
+
+	require.NoError(t, m.Sync(ctx))
+	require.NoError(t, checks[0].Run(ctx))
+
+	m.lastSync = time.Now().Add(-time.Minute)
+	require.ErrorContains(t, checks[0].Run(ctx), "shard lease last renewed 1m0s ago")
+
+	cfg := newTestConfig("watchdog-0")
+	cfg.Sharding.Enabled = false
+	m, err = NewMembership(fxtest.NewLifecycle(t), fake.NewSimpleClientset(), cfg, zap.NewNop().Sugar(), nil)
+	require.NoError(t, err)
+	require.Empty(t, m.HealthChecks())
+}
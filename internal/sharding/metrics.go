@@ -0,0 +1,26 @@
+package sharding
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// metrics are the metrics of the shard membership
+type metrics struct {
+	// shardMembers tracks how many live replicas share the namespace set
+	shardMembers *prometheus.GaugeVec
+}
+
+// newMetrics registers the sharding metrics, a nil registerer leaves them unregistered
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	factory := promauto.With(registerer)
+	return &metrics{
+		shardMembers: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "watchdog_shard_members",
+				Help: "Number of live watchdog replicas in the shard group",
+			},
+			[]string{"group"},
+		),
+	}
+}
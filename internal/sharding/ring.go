@@ -0,0 +1,78 @@
+package sharding
+
+import (
+	"hash/fnv"
+	"slices"
+	"strconv"
+)
+
+// Ring is a consistent hash ring that maps keys to members
+type Ring struct {
+	hashes  []uint64
+	owners  map[uint64]string
+	members []string
+}
+
+// NewRing creates a hash ring with the given number of virtual nodes per member
+func NewRing(members []string, virtualNodes int) *Ring {
+	if virtualNodes < 1 {
+		virtualNodes = 1
+	}
+
+	sorted := slices.Clone(members)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	ring := &Ring{
+		hashes:  make([]uint64, 0, len(sorted)*virtualNodes),
+		owners:  make(map[uint64]string, len(sorted)*virtualNodes),
+		members: sorted,
+	}
+	for _, member := range sorted {
+		for i := range virtualNodes {
+			h := hashKey(member + "#" + strconv.Itoa(i))
+			if _, taken := ring.owners[h]; taken {
+				continue
+			}
+			ring.owners[h] = member
+			ring.hashes = append(ring.hashes, h)
+		}
+	}
+	slices.Sort(ring.hashes)
+
+	return ring
+}
+
+// Owner returns the member responsible for the key, or "" for an empty ring
+func (r *Ring) Owner(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+
+	h := hashKey(key)
+	idx, _ := slices.BinarySearch(r.hashes, h)
+	if idx == len(r.hashes) {
+		idx = 0
+	}
+	return r.owners[r.hashes[idx]]
+}
+
+// Members returns the sorted list of ring members
+func (r *Ring) Members() []string {
+	return slices.Clone(r.members)
+}
+
+// hashKey hashes with FNV-1a and spreads the result with the murmur3 finalizer,
+// since FNV alone clusters short keys that differ only in their last bytes
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
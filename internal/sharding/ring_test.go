@@ -0,0 +1,60 @@
+package sharding
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRing(t *testing.T) {
+	t.Run("empty ring owns nothing", func(t *testing.T) {
+		ring := NewRing(nil, 16)
+		require.Empty(t, ring.Owner("default"))
+		require.Empty(t, ring.Members())
+	})
+
+	t.Run("single member owns everything", func(t *testing.T) {
+		ring := NewRing([]string{"watchdog-0"}, 16)
+		for i := range 50 {
+			require.Equal(t, "watchdog-0", ring.Owner(fmt.Sprintf("ns-%d", i)))
+		}
+	})
+
+	t.Run("members are sorted and deduplicated", func(t *testing.T) {
+		ring := NewRing([]string{"b", "a", "b"}, 4)
+		require.Equal(t, []string{"a", "b"}, ring.Members())
+	})
+
+	t.Run("ownership is deterministic", func(t *testing.T) {
+		first := NewRing([]string{"a", "b", "c"}, 32)
+		second := NewRing([]string{"c", "a", "b"}, 32)
+		for i := range 100 {
+			key := fmt.Sprintf("ns-%d", i)
+			require.Equal(t, first.Owner(key), second.Owner(key))
+		}
+	})
+
+	t.Run("keys are spread over all members", func(t *testing.T) {
+		ring := NewRing([]string{"a", "b", "c"}, 64)
+		counts := map[string]int{}
+		for i := range 300 {
+			counts[ring.Owner(fmt.Sprintf("ns-%d", i))]++
+		}
+		require.Len(t, counts, 3)
+		for member, count := range counts {
+			require.Greater(t, count, 30, "member %s owns too few keys", member)
+		}
+	})
+
+	t.Run("removing a member only moves its keys", func(t *testing.T) {
+		before := NewRing([]string{"a", "b", "c"}, 64)
+		after := NewRing([]string{"a", "b"}, 64)
+		for i := range 300 {
+			key := fmt.Sprintf("ns-%d", i)
+			if owner := before.Owner(key); owner != "c" {
+				require.Equal(t, owner, after.Owner(key))
+			}
+		}
+	})
+}
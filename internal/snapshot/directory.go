@@ -0,0 +1,126 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// fileTimeLayout names snapshot files so they sort by the time they were taken
+const fileTimeLayout = "20060102T150405.000000000Z"
+
+// DirectoryStore keeps snapshots as JSON files in <directory>/<namespace>/<pod>/<time>.json
+type DirectoryStore struct {
+	directory string
+}
+
+// NewDirectoryStore creates a snapshot store in a local directory
+func NewDirectoryStore(directory string) *DirectoryStore {
+	return &DirectoryStore{directory: directory}
+}
+
+// Save writes the snapshot to a new file
+func (s *DirectoryStore) Save(_ context.Context, snapshot *Snapshot) error {
+	dir := filepath.Join(s.directory, snapshot.Namespace, snapshot.Name)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(snapshot.Pod, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a partial snapshot
+	path := filepath.Join(dir, snapshot.Taken.UTC().Format(fileTimeLayout)+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Latest reads the newest snapshot of a pod
+func (s *DirectoryStore) Latest(_ context.Context, namespace, name string) (*Snapshot, error) {
+	dir := filepath.Join(s.directory, namespace, name)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if _, ok := takenAt(entry.Name()); ok {
+			files = append(files, entry.Name())
+		}
+	}
+	if len(files) == 0 {
+		return nil, ErrNotFound
+	}
+	latest := slices.Max(files)
+	taken, _ := takenAt(latest)
+
+	data, err := os.ReadFile(filepath.Join(dir, latest))
+	if err != nil {
+		return nil, err
+	}
+	var pod v1.Pod
+	if err := json.Unmarshal(data, &pod); err != nil {
+		return nil, err
+	}
+	return &Snapshot{Namespace: namespace, Name: name, Taken: taken, Pod: &pod}, nil
+}
+
+// Prune deletes the snapshot files taken before the given time and the directories left empty
+func (s *DirectoryStore) Prune(_ context.Context, before time.Time) (int, error) {
+	pruned := 0
+	var dirs []string
+	err := filepath.WalkDir(s.directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if path != s.directory {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		if taken, ok := takenAt(entry.Name()); ok && taken.Before(before) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			pruned++
+		}
+		return nil
+	})
+
+	// Remove the deepest directories first, non-empty ones are left alone
+	for i := len(dirs) - 1; i >= 0; i-- {
+		_ = os.Remove(dirs[i])
+	}
+	return pruned, err
+}
+
+// takenAt parses the time a snapshot file was taken from its name
+func takenAt(fileName string) (time.Time, bool) {
+	stamp, ok := strings.CutSuffix(fileName, ".json")
+	if !ok {
+		return time.Time{}, false
+	}
+	taken, err := time.Parse(fileTimeLayout, stamp)
+	return taken, err == nil
+}
@@ -0,0 +1,15 @@
+// Package snapshot saves pod manifests before the watchdog terminates them.
+//
+// A bare pod that is deleted by mistake cannot be recreated by a controller,
+// and its spec is gone with it. The Snapshotter runs right before every
+// termination and saves a sanitized copy of the pod, without status, UID,
+// resourceVersion and other server-populated fields, to a Store:
+//   - directory: JSON files in <directory>/<namespace>/<pod>/<time>.json
+//   - configmap: one ConfigMap per snapshot in a configured namespace
+//   - secret: one Secret per snapshot, for specs carrying sensitive values
+//
+// Snapshots older than the retention period are pruned. A failed snapshot is
+// logged and counted, and holds back the termination only when snapshots are
+// required. `watchdog restore <namespace>/<pod>` recreates a pod from its
+// latest snapshot.
+package snapshot
@@ -0,0 +1,39 @@
+package snapshot
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	resultSaved  = "saved"
+	resultFailed = "failed"
+)
+
+// metrics are the metrics of the snapshotter
+type metrics struct {
+	// snapshotsTotal counts pod snapshots by result
+	snapshotsTotal *prometheus.CounterVec
+	// snapshotsPrunedTotal counts snapshots deleted after their retention
+	snapshotsPrunedTotal prometheus.Counter
+}
+
+// newMetrics registers the snapshot metrics, a nil registerer leaves them unregistered
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	factory := promauto.With(registerer)
+	return &metrics{
+		snapshotsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "watchdog_snapshots_total",
+				Help: "Total number of pod snapshots taken before termination by result (saved, failed)",
+			},
+			[]string{"result"},
+		),
+		snapshotsPrunedTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "watchdog_snapshots_pruned_total",
+				Help: "Total number of pod snapshots deleted after their retention period",
+			},
+		),
+	}
+}
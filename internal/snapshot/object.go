@@ -0,0 +1,203 @@
+package snapshot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+const (
+	// SnapshotLabel marks the ConfigMaps and Secrets holding pod snapshots
+	SnapshotLabel = "watchdog.isdmx.io/snapshot"
+	// PodLabel holds a hash of the snapshotted pod's namespace and name, which may be too long for a label
+	PodLabel = "watchdog.isdmx.io/pod"
+
+	annotationNamespace = "watchdog.isdmx.io/pod-namespace"
+	annotationName      = "watchdog.isdmx.io/pod-name"
+	annotationTakenAt   = "watchdog.isdmx.io/taken-at"
+
+	dataKey = "pod.json"
+)
+
+// object is the part of a ConfigMap or Secret used to hold a snapshot
+type object struct {
+	Name        string
+	Labels      map[string]string
+	Annotations map[string]string
+	Data        []byte
+}
+
+// objectClient stores objects as ConfigMaps or Secrets
+type objectClient interface {
+	Create(ctx context.Context, obj object) error
+	List(ctx context.Context, selector string) ([]object, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// ObjectStore keeps one ConfigMap or Secret per snapshot in a single namespace
+type ObjectStore struct {
+	client objectClient
+}
+
+// newObjectStore creates a snapshot store backed by ConfigMaps or Secrets
+func newObjectStore(client objectClient) *ObjectStore {
+	return &ObjectStore{client: client}
+}
+
+// Save creates an object holding the snapshot
+func (s *ObjectStore) Save(ctx context.Context, snapshot *Snapshot) error {
+	data, err := json.Marshal(snapshot.Pod)
+	if err != nil {
+		return err
+	}
+
+	key := podKey(snapshot.Namespace, snapshot.Name)
+	return s.client.Create(ctx, object{
+		Name:   fmt.Sprintf("watchdog-snapshot-%s-%d", key, snapshot.Taken.UnixNano()),
+		Labels: map[string]string{SnapshotLabel: "true", PodLabel: key},
+		Annotations: map[string]string{
+			annotationNamespace: snapshot.Namespace,
+			annotationName:      snapshot.Name,
+			annotationTakenAt:   snapshot.Taken.UTC().Format(time.RFC3339Nano),
+		},
+		Data: data,
+	})
+}
+
+// Latest returns the newest snapshot of a pod
+func (s *ObjectStore) Latest(ctx context.Context, namespace, name string) (*Snapshot, error) {
+	objects, err := s.client.List(ctx, PodLabel+"="+podKey(namespace, name))
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *object
+	var latestTaken time.Time
+	for i := range objects {
+		obj := &objects[i]
+		if obj.Annotations[annotationNamespace] != namespace || obj.Annotations[annotationName] != name {
+			continue
+		}
+		taken, ok := objectTakenAt(obj)
+		if ok && (latest == nil || taken.After(latestTaken)) {
+			latest, latestTaken = obj, taken
+		}
+	}
+	if latest == nil {
+		return nil, ErrNotFound
+	}
+
+	var pod v1.Pod
+	if err := json.Unmarshal(latest.Data, &pod); err != nil {
+		return nil, fmt.Errorf("snapshot %s is corrupt: %w", latest.Name, err)
+	}
+	return &Snapshot{Namespace: namespace, Name: name, Taken: latestTaken, Pod: &pod}, nil
+}
+
+// Prune deletes the snapshot objects taken before the given time
+func (s *ObjectStore) Prune(ctx context.Context, before time.Time) (int, error) {
+	objects, err := s.client.List(ctx, SnapshotLabel+"=true")
+	if err != nil {
+		return 0, err
+	}
+
+	pruned := 0
+	for i := range objects {
+		taken, ok := objectTakenAt(&objects[i])
+		if !ok || !taken.Before(before) {
+			continue
+		}
+		if err := s.client.Delete(ctx, objects[i].Name); err != nil {
+			return pruned, err
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+// podKey is a short hash identifying a pod in object names and labels
+func podKey(namespace, name string) string {
+	sum := sha256.Sum256([]byte(namespace + "/" + name))
+	return hex.EncodeToString(sum[:8])
+}
+
+func objectTakenAt(obj *object) (time.Time, bool) {
+	taken, err := time.Parse(time.RFC3339Nano, obj.Annotations[annotationTakenAt])
+	return taken, err == nil
+}
+
+// configMaps stores snapshot objects as ConfigMaps
+type configMaps struct {
+	client typedcorev1.ConfigMapInterface
+}
+
+func (c configMaps) Create(ctx context.Context, obj object) error {
+	_, err := c.client.Create(ctx, &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: obj.Name, Labels: obj.Labels, Annotations: obj.Annotations},
+		Data:       map[string]string{dataKey: string(obj.Data)},
+	}, metav1.CreateOptions{})
+	return err
+}
+
+func (c configMaps) List(ctx context.Context, selector string) ([]object, error) {
+	list, err := c.client.List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]object, 0, len(list.Items))
+	for _, item := range list.Items {
+		objects = append(objects, object{
+			Name:        item.Name,
+			Labels:      item.Labels,
+			Annotations: item.Annotations,
+			Data:        []byte(item.Data[dataKey]),
+		})
+	}
+	return objects, nil
+}
+
+func (c configMaps) Delete(ctx context.Context, name string) error {
+	return c.client.Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+// secrets stores snapshot objects as Secrets, for pods whose specs carry sensitive values
+type secrets struct {
+	client typedcorev1.SecretInterface
+}
+
+func (c secrets) Create(ctx context.Context, obj object) error {
+	_, err := c.client.Create(ctx, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: obj.Name, Labels: obj.Labels, Annotations: obj.Annotations},
+		Type:       v1.SecretTypeOpaque,
+		Data:       map[string][]byte{dataKey: obj.Data},
+	}, metav1.CreateOptions{})
+	return err
+}
+
+func (c secrets) List(ctx context.Context, selector string) ([]object, error) {
+	list, err := c.client.List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]object, 0, len(list.Items))
+	for _, item := range list.Items {
+		objects = append(objects, object{
+			Name:        item.Name,
+			Labels:      item.Labels,
+			Annotations: item.Annotations,
+			Data:        item.Data[dataKey],
+		})
+	}
+	return objects, nil
+}
+
+func (c secrets) Delete(ctx context.Context, name string) error {
+	return c.client.Delete(ctx, name, metav1.DeleteOptions{})
+}
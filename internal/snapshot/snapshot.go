@@ -0,0 +1,89 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+// Snapshot stores
+const (
+	StoreDirectory = "directory"
+	StoreConfigMap = "configmap"
+	StoreSecret    = "secret"
+)
+
+// ErrNotFound is returned when a pod has no snapshot
+var ErrNotFound = errors.New("no snapshot found")
+
+// Snapshot is a sanitized pod manifest saved before termination
+type Snapshot struct {
+	Namespace string
+	Name      string
+	Taken     time.Time
+	Pod       *v1.Pod
+}
+
+// Store keeps snapshots
+type Store interface {
+	// Save stores a snapshot
+	Save(ctx context.Context, snapshot *Snapshot) error
+	// Latest returns the newest snapshot of a pod, or ErrNotFound
+	Latest(ctx context.Context, namespace, name string) (*Snapshot, error)
+	// Prune deletes the snapshots taken before the given time and returns how many were deleted
+	Prune(ctx context.Context, before time.Time) (int, error)
+}
+
+// NewStore creates the snapshot store selected in the configuration
+func NewStore(clientset kubernetes.Interface, cfg config.SnapshotConfig) (Store, error) {
+	switch cfg.Store {
+	case StoreDirectory:
+		return NewDirectoryStore(cfg.Directory), nil
+	case StoreConfigMap:
+		return newObjectStore(configMaps{clientset.CoreV1().ConfigMaps(cfg.Namespace)}), nil
+	case StoreSecret:
+		return newObjectStore(secrets{clientset.CoreV1().Secrets(cfg.Namespace)}), nil
+	default:
+		return nil, fmt.Errorf("unknown snapshot store %q, expected %q, %q or %q",
+			cfg.Store, StoreDirectory, StoreConfigMap, StoreSecret)
+	}
+}
+
+// Sanitize returns a copy of the pod without status, UID, resourceVersion and
+// the other fields populated by the API server
+func Sanitize(pod *v1.Pod) *v1.Pod {
+	clean := pod.DeepCopy()
+	clean.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}
+	clean.UID = ""
+	clean.ResourceVersion = ""
+	clean.Generation = 0
+	clean.SelfLink = ""
+	clean.CreationTimestamp = metav1.Time{}
+	clean.DeletionTimestamp = nil
+	clean.DeletionGracePeriodSeconds = nil
+	clean.ManagedFields = nil
+	clean.Status = v1.PodStatus{}
+	return clean
+}
+
+// PrepareRestore returns a copy of a snapshot's pod that can be created again as a
+// bare pod: it is detached from its owner and node, and loses the watchdog's expiry
+// annotation and the TTL label that would get it terminated right away
+func PrepareRestore(pod *v1.Pod, ttlLabel string) *v1.Pod {
+	restored := Sanitize(pod)
+	restored.OwnerReferences = nil
+	restored.Spec.NodeName = ""
+	delete(restored.Annotations, monitoring.AnnotationExpiresAt)
+	if ttlLabel != "" {
+		delete(restored.Labels, ttlLabel)
+	}
+	return restored
+}
@@ -0,0 +1,77 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+func newTestPod() *v1.Pod {
+	controller := true
+	now := metav1.Now()
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "sandbox-1",
+			Namespace:         "default",
+			UID:               "uid-1",
+			ResourceVersion:   "42",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			DeletionTimestamp: &now,
+			ManagedFields:     []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+			Labels:            map[string]string{"app": "sandbox", "sandbox.kill_time": "1700000000"},
+			Annotations:       map[string]string{monitoring.AnnotationExpiresAt: "2026-01-02T03:00:00Z", "note": "keep"},
+			OwnerReferences:   []metav1.OwnerReference{{Kind: "Job", Name: "sandbox", Controller: &controller}},
+		},
+		Spec: v1.PodSpec{
+			NodeName:   "node-1",
+			Containers: []v1.Container{{Name: "main", Image: "busybox"}},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning, PodIP: "10.0.0.1"},
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	pod := newTestPod()
+	clean := Sanitize(pod)
+
+	require.Equal(t, "v1", clean.APIVersion)
+	require.Equal(t, "Pod", clean.Kind)
+	require.Empty(t, clean.UID)
+	require.Empty(t, clean.ResourceVersion)
+	require.True(t, clean.CreationTimestamp.IsZero())
+	require.Nil(t, clean.DeletionTimestamp)
+	require.Nil(t, clean.ManagedFields)
+	require.Equal(t, v1.PodStatus{}, clean.Status)
+	require.Equal(t, pod.Spec, clean.Spec)
+	require.Equal(t, pod.Labels, clean.Labels)
+	require.Len(t, clean.OwnerReferences, 1)
+
+	require.Equal(t, "uid-1", string(pod.UID), "the original pod is left alone")
+}
+
+func TestPrepareRestore(t *testing.T) {
+	restored := PrepareRestore(Sanitize(newTestPod()), "sandbox.kill_time")
+
+	require.Nil(t, restored.OwnerReferences)
+	require.Empty(t, restored.Spec.NodeName)
+	require.Equal(t, map[string]string{"app": "sandbox"}, restored.Labels)
+	require.Equal(t, map[string]string{"note": "keep"}, restored.Annotations)
+}
+
+func TestNewStore(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	for _, store := range []string{StoreDirectory, StoreConfigMap, StoreSecret} {
+		_, err := NewStore(clientset, config.SnapshotConfig{Store: store, Directory: t.TempDir(), Namespace: "watchdog"})
+		require.NoError(t, err, store)
+	}
+
+	_, err := NewStore(clientset, config.SnapshotConfig{Store: "s3"})
+	require.Error(t, err)
+}
@@ -0,0 +1,105 @@
+package snapshot
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/isdmx/watchdog/internal/config"
+	"github.com/isdmx/watchdog/internal/monitoring"
+)
+
+// pruneInterval limits how often old snapshots are pruned
+const pruneInterval = time.Minute
+
+var _ monitoring.Preserver = (*Snapshotter)(nil)
+
+// Snapshotter saves the manifest of every pod right before the watchdog terminates it
+type Snapshotter struct {
+	store   Store
+	config  config.SnapshotConfig
+	logger  *zap.SugaredLogger
+	metrics *metrics
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+// NewSnapshotter creates a new snapshotter
+func NewSnapshotter(
+	clientset kubernetes.Interface,
+	cfg *config.Config,
+	logger *zap.SugaredLogger,
+	registerer prometheus.Registerer,
+) (*Snapshotter, error) {
+	store, err := NewStore(clientset, cfg.Snapshots)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshotter{
+		store:   store,
+		config:  cfg.Snapshots,
+		logger:  logger.Named("Snapshotter"),
+		metrics: newMetrics(registerer),
+	}, nil
+}
+
+// Name identifies the snapshotter in errors
+func (s *Snapshotter) Name() string {
+	return "snapshot"
+}
+
+// Preserve saves a sanitized copy of the pod. A failure only holds back the
+// termination when snapshots are required.
+func (s *Snapshotter) Preserve(ctx context.Context, pod *v1.Pod) error {
+	if !s.config.Enabled {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	err := s.store.Save(ctx, &Snapshot{Namespace: pod.Namespace, Name: pod.Name, Taken: now, Pod: Sanitize(pod)})
+	if err != nil {
+		s.logger.Errorw("Failed to snapshot pod", "namespace", pod.Namespace, "pod", pod.Name, "required", s.config.Required, "error", err)
+		s.metrics.snapshotsTotal.WithLabelValues(resultFailed).Inc()
+		if s.config.Required {
+			return err
+		}
+		return nil
+	}
+	s.logger.Debugw("Saved pod snapshot", "namespace", pod.Namespace, "pod", pod.Name)
+	s.metrics.snapshotsTotal.WithLabelValues(resultSaved).Inc()
+
+	s.prune(ctx, now)
+	return nil
+}
+
+// prune deletes the snapshots older than the retention, at most once per pruneInterval
+func (s *Snapshotter) prune(ctx context.Context, now time.Time) {
+	if s.config.Retention <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	if now.Sub(s.lastPruned) < pruneInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPruned = now
+	s.mu.Unlock()
+
+	pruned, err := s.store.Prune(ctx, now.Add(-s.config.Retention))
+	s.metrics.snapshotsPrunedTotal.Add(float64(pruned))
+	if err != nil {
+		s.logger.Warnw("Failed to prune old snapshots", "error", err)
+		return
+	}
+	if pruned > 0 {
+		s.logger.Infow("Pruned old snapshots", "count", pruned, "retention", s.config.Retention)
+	}
+}
@@ -0,0 +1,71 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+func TestSnapshotter(t *testing.T) {
+	newSnapshotter := func(t *testing.T, snapshots config.SnapshotConfig) *Snapshotter {
+		t.Helper()
+		snapshotter, err := NewSnapshotter(fake.NewSimpleClientset(), &config.Config{Snapshots: snapshots}, zap.NewNop().Sugar(), nil)
+		require.NoError(t, err)
+		return snapshotter
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		dir := t.TempDir()
+		snapshotter := newSnapshotter(t, config.SnapshotConfig{Store: StoreDirectory, Directory: dir})
+		require.NoError(t, snapshotter.Preserve(context.Background(), newTestPod()))
+
+		_, err := snapshotter.store.Latest(context.Background(), "default", "sandbox-1")
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("saves a sanitized snapshot and prunes old ones", func(t *testing.T) {
+		dir := t.TempDir()
+		snapshotter := newSnapshotter(t, config.SnapshotConfig{Enabled: true, Store: StoreDirectory, Directory: dir, Retention: time.Hour})
+		old := &Snapshot{Namespace: "default", Name: "old", Taken: time.Now().Add(-2 * time.Hour), Pod: Sanitize(newTestPod())}
+		require.NoError(t, snapshotter.store.Save(context.Background(), old))
+
+		require.NoError(t, snapshotter.Preserve(context.Background(), newTestPod()))
+
+		snapshot, err := snapshotter.store.Latest(context.Background(), "default", "sandbox-1")
+		require.NoError(t, err)
+		require.Empty(t, snapshot.Pod.UID)
+		require.Empty(t, snapshot.Pod.Status.Phase)
+
+		_, err = snapshotter.store.Latest(context.Background(), "default", "old")
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("failures only block when required", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		clientset.PrependReactor("create", "configmaps", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("boom")
+		})
+
+		snapshots := config.SnapshotConfig{Enabled: true, Store: StoreConfigMap, Namespace: "watchdog"}
+		snapshotter, err := NewSnapshotter(clientset, &config.Config{Snapshots: snapshots}, zap.NewNop().Sugar(), nil)
+		require.NoError(t, err)
+		require.NoError(t, snapshotter.Preserve(context.Background(), newTestPod()))
+		require.InDelta(t, 1, testutil.ToFloat64(snapshotter.metrics.snapshotsTotal.WithLabelValues(resultFailed)), 0)
+
+		snapshots.Required = true
+		snapshotter, err = NewSnapshotter(clientset, &config.Config{Snapshots: snapshots}, zap.NewNop().Sugar(), nil)
+		require.NoError(t, err)
+		require.EqualError(t, snapshotter.Preserve(context.Background(), newTestPod()), "boom")
+		require.InDelta(t, 1, testutil.ToFloat64(snapshotter.metrics.snapshotsTotal.WithLabelValues(resultFailed)), 0)
+	})
+}
@@ -0,0 +1,76 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testStore runs the behavior every store shares
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	base := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	_, err := store.Latest(ctx, "default", "sandbox-1")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	for i, name := range []string{"sandbox-1", "sandbox-1", "sandbox-2"} {
+		pod := Sanitize(newTestPod())
+		pod.Name = name
+		pod.Labels["generation"] = string(rune('a' + i))
+		require.NoError(t, store.Save(ctx, &Snapshot{Namespace: "default", Name: name, Taken: base.Add(time.Duration(i) * time.Hour), Pod: pod}))
+	}
+
+	latest, err := store.Latest(ctx, "default", "sandbox-1")
+	require.NoError(t, err)
+	require.Equal(t, "sandbox-1", latest.Pod.Name)
+	require.Equal(t, "b", latest.Pod.Labels["generation"])
+	require.True(t, base.Add(time.Hour).Equal(latest.Taken))
+
+	_, err = store.Latest(ctx, "other", "sandbox-1")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	pruned, err := store.Prune(ctx, base.Add(90*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 2, pruned)
+
+	_, err = store.Latest(ctx, "default", "sandbox-1")
+	require.ErrorIs(t, err, ErrNotFound)
+	latest, err = store.Latest(ctx, "default", "sandbox-2")
+	require.NoError(t, err)
+	require.Equal(t, "c", latest.Pod.Labels["generation"])
+}
+
+func TestDirectoryStore(t *testing.T) {
+	testStore(t, NewDirectoryStore(t.TempDir()))
+
+	t.Run("prune of a missing directory", func(t *testing.T) {
+		pruned, err := NewDirectoryStore(t.TempDir()+"/missing").Prune(context.Background(), time.Now())
+		require.NoError(t, err)
+		require.Zero(t, pruned)
+	})
+}
+
+func TestConfigMapStore(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	testStore(t, newObjectStore(configMaps{clientset.CoreV1().ConfigMaps("watchdog")}))
+
+	list, err := clientset.CoreV1().ConfigMaps("watchdog").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	require.Equal(t, "true", list.Items[0].Labels[SnapshotLabel])
+}
+
+func TestSecretStore(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	testStore(t, newObjectStore(secrets{clientset.CoreV1().Secrets("watchdog")}))
+
+	list, err := clientset.CoreV1().Secrets("watchdog").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	require.Contains(t, list.Items[0].Data, dataKey)
+}
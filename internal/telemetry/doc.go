@@ -0,0 +1,15 @@
+// Package telemetry exports traces and metrics to an OpenTelemetry collector
+// over OTLP, using gRPC or HTTP.
+//
+// With telemetry.tracing enabled, each monitoring cycle is a trace: the cycle
+// span holds a span per namespace list and per pod evaluation, and the delete
+// calls are children of the evaluation of their pod. Spans carry the standard
+// k8s.namespace.name, k8s.pod.name and k8s.pod.uid attributes. When tracing is
+// disabled the monitor gets a no-op tracer provider.
+//
+// With telemetry.metrics enabled, the Prometheus registry of the watchdog is
+// pushed to the collector at every interval, so both exports carry the same
+// metrics. /metrics keeps being served unless metrics.prometheus is disabled.
+//
+// Both exports are flushed when the application stops.
+package telemetry
@@ -0,0 +1,213 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	otelprom "go.opentelemetry.io/contrib/bridges/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+const (
+	// ProtocolGRPC exports over OTLP/gRPC, usually on port 4317
+	ProtocolGRPC = "grpc"
+	// ProtocolHTTP exports over OTLP/HTTP with protobuf bodies, usually on port 4318
+	ProtocolHTTP = "http"
+)
+
+// Telemetry owns the OpenTelemetry providers exporting over OTLP
+type Telemetry struct {
+	tracerProvider trace.TracerProvider
+	shutdowns      []func(context.Context) error
+	logger         *zap.SugaredLogger
+}
+
+// NewTelemetry creates the trace and metric exports enabled by the telemetry
+// configuration, the metric export pushes the metrics of the registry
+func NewTelemetry(lc fx.Lifecycle, cfg *config.Config, logger *zap.SugaredLogger, registry *prometheus.Registry) (*Telemetry, error) {
+	t := &Telemetry{
+		tracerProvider: noop.NewTracerProvider(),
+		logger:         logger.Named("Telemetry"),
+	}
+	tcfg := cfg.Telemetry
+	if !tcfg.Tracing.Enabled && !tcfg.Metrics.Enabled {
+		return t, nil
+	}
+
+	switch tcfg.OTLP.Protocol {
+	case ProtocolGRPC, ProtocolHTTP:
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol %q, expected %q or %q", tcfg.OTLP.Protocol, ProtocolGRPC, ProtocolHTTP)
+	}
+
+	res, err := resource.New(context.Background(),
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(semconv.ServiceName(tcfg.ServiceName)),
+		resource.WithHost(),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe the telemetry resource: %w", err)
+	}
+
+	if tcfg.Tracing.Enabled {
+		exporter, err := newTraceExporter(tcfg.OTLP)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the OTLP trace exporter: %w", err)
+		}
+		provider := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(res),
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(tcfg.Tracing.SampleRatio))),
+		)
+		t.tracerProvider = provider
+		t.shutdowns = append(t.shutdowns, provider.Shutdown)
+	}
+
+	if tcfg.Metrics.Enabled {
+		exporter, err := newMetricExporter(tcfg.OTLP)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the OTLP metric exporter: %w", err)
+		}
+		reader := sdkmetric.NewPeriodicReader(exporter,
+			sdkmetric.WithInterval(tcfg.Metrics.Interval),
+			sdkmetric.WithProducer(otelprom.NewMetricProducer(otelprom.WithGatherer(registry))),
+		)
+		provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader), sdkmetric.WithResource(res))
+		t.shutdowns = append(t.shutdowns, provider.Shutdown)
+	}
+
+	// Failed exports are reported through the global handler, which logs with the standard library
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		t.logger.Warnw("Failed to export telemetry", "error", err)
+	}))
+	t.logger.Infow("Exporting telemetry over OTLP",
+		"endpoint", tcfg.OTLP.Endpoint, "protocol", tcfg.OTLP.Protocol,
+		"tracing", tcfg.Tracing.Enabled, "metrics", tcfg.Metrics.Enabled)
+
+	lc.Append(fx.Hook{
+		OnStop: t.Shutdown,
+	})
+	return t, nil
+}
+
+// TracerProvider returns the provider of the cycle traces, a no-op one unless
+// tracing is enabled
+func (t *Telemetry) TracerProvider() trace.TracerProvider {
+	return t.tracerProvider
+}
+
+// Shutdown flushes the pending spans and metrics and stops the exports
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, shutdown := range t.shutdowns {
+		errs = append(errs, shutdown(ctx))
+	}
+	if err := errors.Join(errs...); err != nil {
+		t.logger.Errorw("Failed to flush telemetry", "error", err)
+		return err
+	}
+	return nil
+}
+
+// isURL tells a URL endpoint from a host:port one
+func isURL(endpoint string) bool {
+	return strings.Contains(endpoint, "://")
+}
+
+// signalURL appends the path of the signal to an OTLP/HTTP base URL, the way
+// OTEL_EXPORTER_OTLP_ENDPOINT is interpreted
+func signalURL(endpoint, signal string) string {
+	return strings.TrimSuffix(endpoint, "/") + "/v1/" + signal
+}
+
+// newTraceExporter creates the OTLP trace exporter of the protocol
+func newTraceExporter(cfg config.OTLPConfig) (sdktrace.SpanExporter, error) {
+	ctx := context.Background()
+	if cfg.Protocol == ProtocolHTTP {
+		opts := []otlptracehttp.Option{otlptracehttp.WithTimeout(cfg.Timeout)}
+		switch {
+		case isURL(cfg.Endpoint):
+			opts = append(opts, otlptracehttp.WithEndpointURL(signalURL(cfg.Endpoint, "traces")))
+		case cfg.Endpoint != "":
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	}
+
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithTimeout(cfg.Timeout)}
+	switch {
+	case isURL(cfg.Endpoint):
+		opts = append(opts, otlptracegrpc.WithEndpointURL(cfg.Endpoint))
+	case cfg.Endpoint != "":
+		opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(cfg.Headers))
+	}
+	return otlptracegrpc.New(ctx, opts...)
+}
+
+// newMetricExporter creates the OTLP metric exporter of the protocol
+func newMetricExporter(cfg config.OTLPConfig) (sdkmetric.Exporter, error) {
+	ctx := context.Background()
+	if cfg.Protocol == ProtocolHTTP {
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithTimeout(cfg.Timeout)}
+		switch {
+		case isURL(cfg.Endpoint):
+			opts = append(opts, otlpmetrichttp.WithEndpointURL(signalURL(cfg.Endpoint, "metrics")))
+		case cfg.Endpoint != "":
+			opts = append(opts, otlpmetrichttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlpmetrichttp.WithHeaders(cfg.Headers))
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	}
+
+	opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithTimeout(cfg.Timeout)}
+	switch {
+	case isURL(cfg.Endpoint):
+		opts = append(opts, otlpmetricgrpc.WithEndpointURL(cfg.Endpoint))
+	case cfg.Endpoint != "":
+		opts = append(opts, otlpmetricgrpc.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlpmetricgrpc.WithHeaders(cfg.Headers))
+	}
+	return otlpmetricgrpc.New(ctx, opts...)
+}
@@ -0,0 +1,233 @@
+package telemetry
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/isdmx/watchdog/internal/config"
+)
+
+// collector stands in for an OpenTelemetry collector, recording what it receives
+// over OTLP/gRPC and OTLP/HTTP
+type collector struct {
+	collectortrace.UnimplementedTraceServiceServer
+	collectormetrics.UnimplementedMetricsServiceServer
+
+	mu      sync.Mutex
+	spans   []string
+	metrics []string
+	headers []string
+	service string
+}
+
+func (c *collector) Export(_ context.Context, req *collectortrace.ExportTraceServiceRequest) (*collectortrace.ExportTraceServiceResponse, error) {
+	c.recordTraces(req)
+	return &collectortrace.ExportTraceServiceResponse{}, nil
+}
+
+// metricsService answers the metrics export, whose method name clashes with the trace one
+type metricsService struct {
+	collectormetrics.UnimplementedMetricsServiceServer
+	*collector
+}
+
+func (s metricsService) Export(_ context.Context, req *collectormetrics.ExportMetricsServiceRequest) (*collectormetrics.ExportMetricsServiceResponse, error) {
+	s.recordMetrics(req)
+	return &collectormetrics.ExportMetricsServiceResponse{}, nil
+}
+
+func (c *collector) recordTraces(req *collectortrace.ExportTraceServiceRequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rs := range req.GetResourceSpans() {
+		c.service = serviceName(rs.GetResource().GetAttributes())
+		for _, ss := range rs.GetScopeSpans() {
+			for _, span := range ss.GetSpans() {
+				c.spans = append(c.spans, span.GetName())
+			}
+		}
+	}
+}
+
+func (c *collector) recordMetrics(req *collectormetrics.ExportMetricsServiceRequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rm := range req.GetResourceMetrics() {
+		c.service = serviceName(rm.GetResource().GetAttributes())
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, metric := range sm.GetMetrics() {
+				c.metrics = append(c.metrics, metric.GetName())
+			}
+		}
+	}
+}
+
+// serveHTTP answers OTLP/HTTP requests with protobuf bodies
+func (c *collector) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	c.headers = append(c.headers, r.Header.Get("Authorization"))
+	c.mu.Unlock()
+
+	var resp proto.Message
+	switch r.URL.Path {
+	case "/v1/traces":
+		req := &collectortrace.ExportTraceServiceRequest{}
+		if err := proto.Unmarshal(body, req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.recordTraces(req)
+		resp = &collectortrace.ExportTraceServiceResponse{}
+	case "/v1/metrics":
+		req := &collectormetrics.ExportMetricsServiceRequest{}
+		if err := proto.Unmarshal(body, req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.recordMetrics(req)
+		resp = &collectormetrics.ExportMetricsServiceResponse{}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	data, _ := proto.Marshal(resp)
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(data)
+}
+
+func (c *collector) received() (spans, metrics []string, service string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.spans), slices.Clone(c.metrics), c.service
+}
+
+func serviceName(attributes []*commonpb.KeyValue) string {
+	for _, attribute := range attributes {
+		if attribute.GetKey() == "service.name" {
+			return attribute.GetValue().GetStringValue()
+		}
+	}
+	return ""
+}
+
+// startGRPCCollector serves the collector over gRPC and returns its address
+func startGRPCCollector(t *testing.T, c *collector) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	collectortrace.RegisterTraceServiceServer(server, c)
+	collectormetrics.RegisterMetricsServiceServer(server, metricsService{collector: c})
+	go func() { _ = server.Serve(ln) }()
+	t.Cleanup(server.Stop)
+	return ln.Addr().String()
+}
+
+// testRegistry returns a registry with a single counter
+func testRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	promauto.With(registry).NewCounter(prometheus.CounterOpts{Name: "watchdog_test_total", Help: "Test counter"}).Inc()
+	return registry
+}
+
+func telemetryConfig(otlp config.OTLPConfig) *config.Config {
+	return &config.Config{Telemetry: config.TelemetryConfig{
+		ServiceName: "watchdog-test",
+		OTLP:        otlp,
+		Tracing:     config.TracingConfig{Enabled: true, SampleRatio: 1},
+		Metrics:     config.TelemetryMetricsConfig{Enabled: true, Interval: time.Hour},
+	}}
+}
+
+func TestTelemetry(t *testing.T) {
+	export := func(t *testing.T, cfg *config.Config) {
+		t.Helper()
+		lc := fxtest.NewLifecycle(t)
+		telemetry, err := NewTelemetry(lc, cfg, zap.NewNop().Sugar(), testRegistry())
+		require.NoError(t, err)
+		lc.RequireStart()
+
+		_, span := telemetry.TracerProvider().Tracer("test").Start(context.Background(), "cycle")
+		span.End()
+
+		// Stopping flushes the batched spans and the pending metrics
+		lc.RequireStop()
+	}
+
+	t.Run("exports over gRPC", func(t *testing.T) {
+		c := &collector{}
+		addr := startGRPCCollector(t, c)
+		export(t, telemetryConfig(config.OTLPConfig{Endpoint: addr, Protocol: ProtocolGRPC, Insecure: true, Timeout: 5 * time.Second}))
+
+		spans, metrics, service := c.received()
+		require.Equal(t, []string{"cycle"}, spans)
+		require.Contains(t, metrics, "watchdog_test_total")
+		require.Equal(t, "watchdog-test", service)
+	})
+
+	t.Run("exports over HTTP", func(t *testing.T) {
+		c := &collector{}
+		server := httptest.NewServer(http.HandlerFunc(c.serveHTTP))
+		t.Cleanup(server.Close)
+		export(t, telemetryConfig(config.OTLPConfig{
+			Endpoint: server.URL,
+			Protocol: ProtocolHTTP,
+			Headers:  map[string]string{"Authorization": "Bearer secret"},
+			Timeout:  5 * time.Second,
+		}))
+
+		spans, metrics, service := c.received()
+		require.Equal(t, []string{"cycle"}, spans)
+		require.Contains(t, metrics, "watchdog_test_total")
+		require.Equal(t, "watchdog-test", service)
+		require.Equal(t, []string{"Bearer secret", "Bearer secret"}, c.headers)
+	})
+
+	t.Run("exports metrics only", func(t *testing.T) {
+		c := &collector{}
+		addr := startGRPCCollector(t, c)
+		cfg := telemetryConfig(config.OTLPConfig{Endpoint: addr, Protocol: ProtocolGRPC, Insecure: true, Timeout: 5 * time.Second})
+		cfg.Telemetry.Tracing.Enabled = false
+		export(t, cfg)
+
+		spans, metrics, _ := c.received()
+		require.Empty(t, spans)
+		require.Contains(t, metrics, "watchdog_test_total")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		telemetry, err := NewTelemetry(fxtest.NewLifecycle(t), &config.Config{}, zap.NewNop().Sugar(), testRegistry())
+		require.NoError(t, err)
+		require.IsType(t, noop.TracerProvider{}, telemetry.TracerProvider())
+		require.NoError(t, telemetry.Shutdown(context.Background()))
+	})
+
+	t.Run("rejects unknown protocols", func(t *testing.T) {
+		_, err := NewTelemetry(fxtest.NewLifecycle(t), telemetryConfig(config.OTLPConfig{Protocol: "thrift"}), zap.NewNop().Sugar(), testRegistry())
+		require.ErrorContains(t, err, `unknown OTLP protocol "thrift"`)
+	})
+}